@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// writeAzureDevOps writes Azure Pipelines logging commands that set every
+// field as an output variable of the step, e.g. $(step.version), and as a
+// prefixed variable of the job, e.g. $(GITVERSION_VERSION), and that set the
+// build number to the version
+func writeAzureDevOps(w io.Writer, info *version.Info, envPrefix string) error {
+	var sb strings.Builder
+	for _, f := range info.Fields() {
+		value := azureEscape(f.Value)
+		fmt.Fprintf(&sb, "##vso[task.setvariable variable=%s;isoutput=true]%s\n", f.Name, value)
+		fmt.Fprintf(&sb, "##vso[task.setvariable variable=%s%s]%s\n", envPrefix, strings.ToUpper(f.Name), value)
+	}
+	if number := azureBuildNumber(info.Version); number != "" {
+		fmt.Fprintf(&sb, "##vso[build.updatebuildnumber]%s\n", number)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// azureEscape escapes the value of a logging command
+func azureEscape(value string) string {
+	return strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// azureBuildNumber replaces the characters Azure Pipelines rejects in build
+// numbers with '-' and removes trailing dots
+func azureBuildNumber(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`"/:<>\|?@*`, r) || r < ' ' {
+			return '-'
+		}
+		return r
+	}, v)
+	return strings.TrimRight(v, ".")
+}
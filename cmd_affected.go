@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/config"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// affectedComponent is a component with changes since a ref
+type affectedComponent struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Reason is "changed" for changes below the path of the component, else
+	// "dependency" for changes to components it depends on, else "shared"
+	// for changes to shared paths
+	Reason string `json:"reason"`
+	// Files are the changed files that affect the component
+	Files []string `json:"files"`
+	// Dependencies are the changed components it depends on
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// runAffected implements the affected command, which maps the files changed
+// since a ref to the components that need to be rebuilt
+func runAffected(args []string) error {
+	fs := flag.NewFlagSet("affected", flag.ContinueOnError)
+	var (
+		sinceFlag  = fs.String("since", "", "Ref to compare with, e.g. origin/main; changes since its merge base with HEAD count")
+		jsonFlag   = fs.Bool("json", false, "Show the affected components with their reason and files as JSON")
+		markerFlag = fs.String("marker", "", "Without components in the config file, use the top-level directories containing this file, e.g. go.mod")
+		githubFlag = fs.Bool("github-actions", false, "Write the affected component names as JSON array and whether there are any to $GITHUB_OUTPUT")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+	if *sinceFlag == "" {
+		return usageError(errors.New("usage: gitversion affected -since <ref> [options]"))
+	}
+
+	opts, err := vf.options()
+	if err != nil {
+		return err
+	}
+	cfg, err := vf.loadConfig(*vf.path)
+	if err != nil {
+		return err
+	}
+	components, err := discoverComponents(*vf.path, cfg, *markerFlag)
+	if err != nil {
+		return err
+	}
+	files, err := version.ChangedPaths(*vf.path, *sinceFlag, opts...)
+	if err != nil {
+		return err
+	}
+	affected := affectedComponents(components, cfg.SharedPaths, files)
+
+	if *githubFlag {
+		outputFile := os.Getenv("GITHUB_OUTPUT")
+		if outputFile == "" {
+			return errors.New("GITHUB_OUTPUT is not set: not running in GitHub Actions?")
+		}
+		names := make([]string, len(affected))
+		for i, a := range affected {
+			names[i] = a.Name
+		}
+		data, err := json.Marshal(names)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		if err := appendToFile(outputFile, fmt.Sprintf("components=%s\naffected=%t\n", data, len(affected) > 0)); err != nil {
+			return err
+		}
+	}
+
+	if *jsonFlag {
+		data, err := json.MarshalIndent(affected, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	for _, a := range affected {
+		fmt.Println(a.Name)
+	}
+	return nil
+}
+
+// affectedComponents returns the components with changed files below their
+// path or below the path of a component they depend on in the order given,
+// or all components if a shared path changed
+func affectedComponents(components []config.Component, sharedPaths, files []string) []affectedComponent {
+	var shared []string
+	for _, f := range files {
+		if version.MatchPath(f, sharedPaths...) {
+			shared = append(shared, f)
+		}
+	}
+
+	own := make(map[string][]string, len(components))
+	changed := make(map[string]bool, len(components))
+	for _, c := range components {
+		for _, f := range files {
+			if f == c.Path || strings.HasPrefix(f, c.Path+"/") {
+				own[c.Name] = append(own[c.Name], f)
+			}
+		}
+		changed[c.Name] = len(own[c.Name]) > 0
+	}
+	// Changes since the merge base affect every dependent alike
+	deps, _ := changedDependencies(components, func(_, dep config.Component) (bool, error) {
+		return changed[dep.Name], nil
+	})
+
+	affected := []affectedComponent{}
+	for _, c := range components {
+		a := affectedComponent{Name: c.Name, Path: c.Path, Dependencies: deps[c.Name]}
+		switch {
+		case changed[c.Name]:
+			a.Reason, a.Files = "changed", own[c.Name]
+		case len(a.Dependencies) > 0:
+			a.Reason = "dependency"
+			for _, dep := range a.Dependencies {
+				a.Files = append(a.Files, own[dep]...)
+			}
+		case len(shared) > 0:
+			a.Reason, a.Files = "shared", shared
+		default:
+			continue
+		}
+		affected = append(affected, a)
+	}
+	return affected
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/changelog"
+	"github.com/fxsml/gitversion/pkg/release"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runChangelog implements the changelog command, which renders the commits
+// since the latest tag as Markdown
+func runChangelog(args []string) error {
+	fs := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	var (
+		versionFlag  = fs.String("version", "", "Heading of the changelog section (default: Unreleased)")
+		sinceFlag    = fs.String("since", "", "Tag to start after (default: latest tag)")
+		templateFlag = fs.String("template", "", "File containing a Go text/template for the changelog section")
+		outputFlag   = fs.String("output", "", "Prepend the section to this file (e.g. CHANGELOG.md) instead of printing it")
+		remoteFlag   = fs.String("remote", release.DefaultRemote, "Remote used to build commit links")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+	repo, err := version.OpenRepository(*vf.path)
+	if err != nil {
+		return err
+	}
+
+	since := *sinceFlag
+	if since == "" {
+		since = info.LatestTag
+	}
+
+	format := ""
+	if *templateFlag != "" {
+		data, err := os.ReadFile(*templateFlag)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+		format = string(data)
+	}
+
+	cl, err := changelog.Generate(repo, plumbing.NewHash(info.GitCommit), changelog.Options{
+		Version:   *versionFlag,
+		Since:     since,
+		RemoteURL: remoteURL(repo, *remoteFlag),
+	})
+	if err != nil {
+		return err
+	}
+
+	out, err := cl.Render(format)
+	if err != nil {
+		return err
+	}
+
+	if *outputFlag != "" {
+		return changelog.Prepend(*outputFlag, out)
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// changelogSection renders the default changelog section of the release of
+// head as tag, with the commits since the previous tag and links built from
+// the remote
+func changelogSection(repo *git.Repository, head plumbing.Hash, tag, since, remote string) (string, error) {
+	cl, err := changelog.Generate(repo, head, changelog.Options{
+		Version:   tag,
+		Since:     since,
+		RemoteURL: remoteURL(repo, remote),
+	})
+	if err != nil {
+		return "", err
+	}
+	return cl.Render("")
+}
+
+// remoteURL returns the first URL of the named remote or an empty string
+func remoteURL(repo *git.Repository, name string) string {
+	remote, err := repo.Remote(name)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
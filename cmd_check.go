@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/policy"
+)
+
+// runCheck implements the check command, which validates the given or the
+// computed version against a policy. It exits with exitCheckFailed if the
+// version violates the policy.
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	var (
+		semverFlag = fs.Bool("semver", true, "Require a valid semantic version")
+		stableFlag = fs.Bool("stable", false, "Require a semantic version without prerelease")
+		regexFlag  = fs.String("regex", "", "Require the version to match this regular expression")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usageError(errors.New("usage: gitversion check [options] [version]"))
+	}
+
+	p := policy.Policy{SemVer: *semverFlag, Stable: *stableFlag}
+	if !*vf.stripTagPrefix {
+		p.Prefix = *vf.tagPrefix
+	}
+	if *regexFlag != "" {
+		re, err := regexp.Compile(*regexFlag)
+		if err != nil {
+			return usageError(fmt.Errorf("invalid regex: %w", err))
+		}
+		p.Pattern = re
+	}
+
+	ver := fs.Arg(0)
+	if ver == "" {
+		info, err := vf.getVersionInfo()
+		if err != nil {
+			return err
+		}
+		ver = info.Version
+	}
+
+	if violations := p.Check(ver); len(violations) > 0 {
+		return &exitError{
+			code:   exitCheckFailed,
+			reason: "invalid_version",
+			err:    fmt.Errorf("invalid version %s: %s", ver, strings.Join(violations, "; ")),
+		}
+	}
+	fmt.Printf("Version %s is valid\n", ver)
+	return nil
+}
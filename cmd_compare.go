@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runCompare implements the compare command, which relates the version info
+// at two refs
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	var (
+		jsonFlag     = fs.Bool("json", false, "Show the comparison as JSON")
+		exitCodeFlag = fs.Bool("exit-code", false, fmt.Sprintf("Exit with status %d if a bump is required", exitBumpRequired))
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageError(errors.New("usage: gitversion compare [options] <refA> <refB>"))
+	}
+	if *vf.ref != "" {
+		return usageError(errors.New("-ref cannot be used with compare, pass both refs as arguments"))
+	}
+
+	opts, err := vf.options()
+	if err != nil {
+		return err
+	}
+	c, err := version.Compare(*vf.path, fs.Arg(0), fs.Arg(1), opts...)
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printComparison(fs.Arg(0), fs.Arg(1), c)
+	}
+
+	if *exitCodeFlag && c.BumpRequired {
+		return &exitError{
+			code:   exitBumpRequired,
+			reason: "bump_required",
+			err:    fmt.Errorf("bump required: %s has %d commits not in %s", fs.Arg(1), c.Ahead, fs.Arg(0)),
+		}
+	}
+	return nil
+}
+
+// printComparison prints the comparison of the refs a and b
+func printComparison(a, b string, c *version.Comparison) {
+	tagOrNone := func(info *version.Info) string {
+		if info.LatestTag == "" {
+			return "(none)"
+		}
+		return info.LatestTag
+	}
+	relation := map[int]string{-1: "<", 0: "=", 1: ">"}[c.Order]
+	bump := "not required"
+	if c.BumpRequired {
+		bump = "required"
+	}
+
+	fmt.Printf("From:     %s (%s at %s)\n", c.From.Version, a, c.From.GitCommitShort)
+	fmt.Printf("To:       %s (%s at %s)\n", c.To.Version, b, c.To.GitCommitShort)
+	fmt.Printf("Tags:     %s %s %s\n", tagOrNone(c.From), relation, tagOrNone(c.To))
+	fmt.Printf("Commits:  %d ahead, %d behind\n", c.Ahead, c.Behind)
+	fmt.Printf("Bump:     %s\n", bump)
+}
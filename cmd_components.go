@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/config"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// componentReport is the version of a monorepo component
+type componentReport struct {
+	Name            string `json:"name"`
+	Path            string `json:"path"`
+	Version         string `json:"version"`
+	LatestTag       string `json:"latestTag,omitempty"`
+	CommitsSinceTag int    `json:"commitsSinceTag"`
+	// Changed reports commits touching the component since its latest tag,
+	// that it has no tag yet or that a component it depends on changed since
+	Changed bool `json:"changed"`
+	// ChangedDependencies are the components it depends on with commits
+	// since its latest tag, or at all if it has none
+	ChangedDependencies []string `json:"changedDependencies,omitempty"`
+
+	// commit is the commit the component was versioned at
+	commit string
+}
+
+// runComponents implements the components command, which versions each
+// component of a monorepo and reports whether it changed since its latest tag
+func runComponents(args []string) error {
+	fs := flag.NewFlagSet("components", flag.ContinueOnError)
+	var (
+		jsonFlag   = fs.Bool("json", false, "Show the components as JSON")
+		matrixFlag = fs.Bool("matrix", false, "Print a GitHub Actions matrix ({\"include\": [...]}) of the changed components")
+		allFlag    = fs.Bool("all", false, "Include unchanged components in -matrix")
+		markerFlag = fs.String("marker", "", "Without components in the config file, use the top-level directories containing this file, e.g. go.mod")
+		githubFlag = fs.Bool("github-actions", false, "Write the matrix and whether any component changed to $GITHUB_OUTPUT")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+	if *vf.componentPath != "" || *vf.component != "" {
+		return usageError(errors.New("-component and -component-path cannot be used with components, which versions every component"))
+	}
+	if err := vf.singlePath(); err != nil {
+		return err
+	}
+
+	cfg, err := vf.loadConfig(*vf.path)
+	if err != nil {
+		return err
+	}
+	components, err := discoverComponents(*vf.path, cfg, *markerFlag)
+	if err != nil {
+		return err
+	}
+	reports := make([]componentReport, len(components))
+	for i, c := range components {
+		opts, err := componentOptions(cfg, c)
+		if err != nil {
+			return err
+		}
+		info, err := vf.getVersionInfoAt(*vf.path, opts...)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", c.Name, err)
+		}
+		reports[i] = componentReport{
+			Name:            c.Name,
+			Path:            c.Path,
+			Version:         info.Version,
+			LatestTag:       info.LatestTag,
+			CommitsSinceTag: info.CommitsSinceTag,
+			Changed:         info.LatestTag == "" || info.CommitsSinceTag > 0,
+			commit:          info.GitCommit,
+		}
+	}
+
+	repo, err := version.OpenRepository(*vf.path)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]componentReport, len(reports))
+	for _, r := range reports {
+		byName[r.Name] = r
+	}
+	deps, err := changedDependencies(components, func(c, dep config.Component) (bool, error) {
+		r := byName[c.Name]
+		count, err := version.CountCommits(repo, plumbing.NewHash(r.commit), r.LatestTag,
+			version.WithComponentPath(dep.Path), version.WithFirstParent(*vf.firstParent))
+		if err != nil {
+			return false, fmt.Errorf("component %s: %w", c.Name, err)
+		}
+		return count > 0, nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range reports {
+		if d := deps[reports[i].Name]; len(d) > 0 {
+			reports[i].ChangedDependencies = d
+			reports[i].Changed = true
+		}
+	}
+
+	matrix := componentMatrix(reports, *allFlag)
+	if *githubFlag {
+		if err := writeGitHubMatrix(matrix); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case *matrixFlag:
+		data, err := json.Marshal(matrix)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	case *jsonFlag:
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	default:
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tPATH\tVERSION\tCHANGED")
+		for _, r := range reports {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", r.Name, r.Path, r.Version, r.Changed)
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// discoverComponents returns the components of the config file or, without
+// any, the top-level directories of the repository at repoPath containing the
+// marker file
+func discoverComponents(repoPath string, cfg *config.Config, marker string) ([]config.Component, error) {
+	if len(cfg.Components) > 0 {
+		return cfg.Components, nil
+	}
+	if marker == "" {
+		return nil, errors.New("no components: list them under components in the config file or pass -marker")
+	}
+
+	repo, err := version.OpenRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	root := w.Filesystem.Root()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", root, err)
+	}
+	var components []config.Component
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, e.Name(), marker)); err == nil {
+			components = append(components, config.Component{Name: e.Name(), Path: e.Name()})
+		}
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no components: no top-level directory contains %s", marker)
+	}
+	return components, nil
+}
+
+// changedDependencies returns for each component the components it depends
+// on, directly or through other components, that changed for it, in the order
+// of components
+func changedDependencies(components []config.Component, changed func(c, dep config.Component) (bool, error)) (map[string][]string, error) {
+	byName := make(map[string]config.Component, len(components))
+	for _, c := range components {
+		byName[c.Name] = c
+	}
+	deps := make(map[string][]string)
+	for _, c := range components {
+		reached := make(map[string]bool)
+		var visit func(name string)
+		visit = func(name string) {
+			for _, dep := range byName[name].DependsOn {
+				if !reached[dep] {
+					reached[dep] = true
+					visit(dep)
+				}
+			}
+		}
+		visit(c.Name)
+		for _, d := range components {
+			if d.Name == c.Name || !reached[d.Name] {
+				continue
+			}
+			ok, err := changed(c, d)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				deps[c.Name] = append(deps[c.Name], d.Name)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// githubMatrix is a GitHub Actions matrix listing combinations with include
+type githubMatrix struct {
+	Include []componentReport `json:"include"`
+}
+
+// componentMatrix returns the matrix of the changed components, or of all
+// components if all is set
+func componentMatrix(reports []componentReport, all bool) githubMatrix {
+	m := githubMatrix{Include: []componentReport{}}
+	for _, r := range reports {
+		if all || r.Changed {
+			m.Include = append(m.Include, r)
+		}
+	}
+	return m
+}
+
+// writeGitHubMatrix writes the matrix and whether it is not empty as step
+// outputs matrix and changed to $GITHUB_OUTPUT
+func writeGitHubMatrix(m githubMatrix) error {
+	outputFile := os.Getenv("GITHUB_OUTPUT")
+	if outputFile == "" {
+		return errors.New("GITHUB_OUTPUT is not set: not running in GitHub Actions?")
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return appendToFile(outputFile, fmt.Sprintf("matrix=%s\nchanged=%t\n", data, len(m.Include) > 0))
+}
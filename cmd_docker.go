@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/fxsml/gitversion/pkg/docker"
+)
+
+// runDockerTags implements the docker-tags command, which prints OCI image
+// tags for the version, one per line
+func runDockerTags(args []string) error {
+	fs := flag.NewFlagSet("docker-tags", flag.ContinueOnError)
+	imageFlag := fs.String("image", "", "Image name to prefix the tags with, e.g. ghcr.io/org/app")
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range docker.Tags(info) {
+		if *imageFlag != "" {
+			tag = *imageFlag + ":" + tag
+		}
+		fmt.Println(tag)
+	}
+	return nil
+}
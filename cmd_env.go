@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runEnv implements the env command, which prints the version info as shell
+// variable assignments with quoted values
+func runEnv(args []string) error {
+	fs := flag.NewFlagSet("env", flag.ContinueOnError)
+	var (
+		prefixFlag    = fs.String("prefix", "GITVERSION_", "Variable name prefix")
+		lowercaseFlag = fs.Bool("lowercase", false, "Use lower-case variable names")
+		exportFlag    = fs.Bool("export", false, "Prefix each assignment with export")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, f := range info.Fields() {
+		name := strings.ToUpper(*prefixFlag + f.Name)
+		if *lowercaseFlag {
+			name = strings.ToLower(name)
+		}
+		if *exportFlag {
+			sb.WriteString("export ")
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", name, version.ShellQuote(f.Value))
+	}
+	fmt.Print(sb.String())
+	return nil
+}
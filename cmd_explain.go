@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runExplain implements the explain command, which prints the steps the
+// version was computed by: the repository found, the default branch, the
+// tags considered and the one chosen, how the distance was counted, the
+// dirty check and the rule the version was derived by
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	var steps []string
+	info, err := vf.getVersionInfo(version.WithLogger(slog.New(&explainHandler{steps: &steps})))
+	for i, step := range steps {
+		fmt.Printf("%2d. %s\n", i+1, step)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nVersion: %s\n", info.Version)
+	return nil
+}
+
+// explainHandler is a slog.Handler collecting the records down to debug
+// level as steps of the form "message: key=value ...", leaving out empty
+// values
+type explainHandler struct {
+	steps *[]string
+	attrs []slog.Attr
+}
+
+func (h *explainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelDebug
+}
+
+func (h *explainHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	sep := ": "
+	add := func(a slog.Attr) bool {
+		v := a.Value.Resolve().String()
+		if v == "" || v == "[]" {
+			return true
+		}
+		fmt.Fprintf(&b, "%s%s=%s", sep, a.Key, v)
+		sep = " "
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(add)
+	*h.steps = append(*h.steps, b.String())
+	return nil
+}
+
+func (h *explainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &explainHandler{steps: h.steps, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+func (h *explainHandler) WithGroup(string) slog.Handler { return h }
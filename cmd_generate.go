@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runGenerate implements the generate command, which writes a Go source file
+// declaring the version constants into a package directory
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	var (
+		pkgFlag    = fs.String("pkg", ".", "Directory of the package to write the file into")
+		nameFlag   = fs.String("package", "", "Package name (default: that of the Go files in -pkg, else the directory name)")
+		outputFlag = fs.String("output", "version_gen.go", "Name of the generated file")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	name := *nameFlag
+	if name == "" {
+		var err error
+		name, err = packageName(*pkgFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+	src, err := info.GoSource(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*pkgFlag, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", *pkgFlag, err)
+	}
+	path := filepath.Join(*pkgFlag, *outputFlag)
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Printf("Wrote %s (%s)\n", path, info.Version)
+	return nil
+}
+
+// packageName returns the package name of the Go files in dir, ignoring
+// tests, or the directory name if it has none
+func packageName(dir string) (string, error) {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range matches {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return f.Name.Name, nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	name := strings.ToLower(strings.NewReplacer("-", "", ".", "").Replace(filepath.Base(abs)))
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("cannot derive a package name from %s, use -package", dir)
+	}
+	return name, nil
+}
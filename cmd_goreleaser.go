@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/goreleaser"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runGoreleaser implements the goreleaser command, which prints the variables
+// or flags that make goreleaser release the computed version
+func runGoreleaser(args []string) error {
+	fs := flag.NewFlagSet("goreleaser", flag.ContinueOnError)
+	var (
+		prefixFlag = fs.String("prefix", "GITVERSION_", "Prefix of the version variables")
+		exportFlag = fs.Bool("export", false, "Prefix each assignment with export")
+		flagsFlag  = fs.Bool("flags", false, "Print goreleaser flags instead: --snapshot unless the version is a release")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+
+	if *flagsFlag {
+		if !goreleaser.Release(info) {
+			fmt.Println("--snapshot")
+		}
+		return nil
+	}
+
+	opts, err := vf.options()
+	if err != nil {
+		return err
+	}
+	repo, err := version.OpenRepository(*vf.path)
+	if err != nil {
+		return err
+	}
+	previous := ""
+	if info.HasCommits {
+		previous, err = goreleaser.PreviousTag(repo, plumbing.NewHash(info.GitCommit), opts...)
+		if err != nil {
+			return err
+		}
+	}
+
+	var sb strings.Builder
+	for _, f := range goreleaser.Env(info, previous, *prefixFlag) {
+		if *exportFlag {
+			sb.WriteString("export ")
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", f.Name, version.ShellQuote(f.Value))
+	}
+	fmt.Print(sb.String())
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runHistory implements the history command, which lists the release tags in
+// SemVer order with their dates, the commits since the previous release and
+// their authors
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	jsonFlag := fs.Bool("json", false, "Show the history as JSON")
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	opts, err := vf.options()
+	if err != nil {
+		return err
+	}
+	history, err := version.History(*vf.path, opts...)
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		data, err := json.MarshalIndent(history, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TAG\tDATE\tCOMMITS\tAUTHORS")
+	for _, r := range history {
+		authors := make([]string, len(r.Authors))
+		for i, a := range r.Authors {
+			authors[i] = fmt.Sprintf("%s (%d)", a.Name, a.Commits)
+		}
+		// Only the day of the release, like the changelog
+		date, _, _ := strings.Cut(r.Date, "T")
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", r.Tag, date, r.Commits, strings.Join(authors, ", "))
+	}
+	return tw.Flush()
+}
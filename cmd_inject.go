@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/fxsml/gitversion/pkg/inject"
+)
+
+// runInject implements the inject command, which writes the version into
+// package.json, Chart.yaml, pyproject.toml and VERSION files
+func runInject(args []string) error {
+	fs := flag.NewFlagSet("inject", flag.ContinueOnError)
+	versionFlag := fs.String("version", "", "Version to write (default: computed version)")
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageError(errors.New("usage: gitversion inject [options] <file>..."))
+	}
+
+	ver := *versionFlag
+	if ver == "" {
+		info, err := vf.getVersionInfo()
+		if err != nil {
+			return err
+		}
+		ver = info.Version
+	}
+
+	for _, path := range fs.Args() {
+		if err := inject.File(path, ver); err != nil {
+			return err
+		}
+		fmt.Printf("Updated %s to %s\n", path, ver)
+	}
+	return nil
+}
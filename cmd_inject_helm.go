@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/fxsml/gitversion/pkg/inject"
+)
+
+// runInjectHelm implements the inject-helm command, which sets version and
+// appVersion in the Chart.yaml of a Helm chart
+func runInjectHelm(args []string) error {
+	fs := flag.NewFlagSet("inject-helm", flag.ContinueOnError)
+	var (
+		chartFlag      = fs.String("chart", ".", "Chart directory or Chart.yaml")
+		versionFlag    = fs.String("version", "", "Chart version (default: computed version, made SemVer)")
+		appVersionFlag = fs.String("app-version", "", "App version (default: computed version)")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	chartVersion, appVersion := *versionFlag, *appVersionFlag
+	if chartVersion == "" || appVersion == "" {
+		info, err := vf.getVersionInfo()
+		if err != nil {
+			return err
+		}
+		if chartVersion == "" {
+			chartVersion = info.Version
+		}
+		if appVersion == "" {
+			appVersion = info.Version
+		}
+	}
+	chartVersion = inject.HelmVersion(chartVersion)
+
+	path, err := inject.HelmChart(*chartFlag, chartVersion, appVersion)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s to version %s, appVersion %s\n", path, chartVersion, appVersion)
+	return nil
+}
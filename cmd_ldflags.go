@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runLdflags implements the ldflags command, which prints a -ldflags string
+// setting the version variables of the given package
+func runLdflags(args []string) error {
+	fs := flag.NewFlagSet("ldflags", flag.ContinueOnError)
+	var (
+		pkgFlag = fs.String("pkg", "main", "Package path holding the Version, Commit, Branch and BuildTime variables")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(info.LDFlags(*pkgFlag))
+	return nil
+}
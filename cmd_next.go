@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/conventional"
+	"github.com/fxsml/gitversion/pkg/semver"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// bumpFlags holds the flags selecting how the next version is computed
+type bumpFlags struct {
+	bump       *string
+	auto       *bool
+	prerelease *string
+}
+
+// addBumpFlags registers the bump flags on the given flag set
+func addBumpFlags(fs *flag.FlagSet) *bumpFlags {
+	return &bumpFlags{
+		bump:       fs.String("bump", "patch", "Version component to increment: patch, minor or major"),
+		auto:       fs.Bool("auto", false, "Derive the bump from Conventional Commits since the latest tag"),
+		prerelease: fs.String("prerelease", "", "Compute the next prerelease with this label, e.g. rc for v1.4.0-rc.3"),
+	}
+}
+
+// runNext implements the next command, which prints the next SemVer version
+// computed from the latest tag
+func runNext(args []string) error {
+	fs := flag.NewFlagSet("next", flag.ContinueOnError)
+	bf := addBumpFlags(fs)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+
+	next, err := nextVersion(*vf.path, info, bf)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(next)
+	return nil
+}
+
+// nextVersion computes the next version according to the bump flags
+func nextVersion(repoPath string, info *version.Info, bf *bumpFlags) (string, error) {
+	var (
+		bump semver.Bump
+		err  error
+	)
+	if *bf.auto {
+		var releaseAs string
+		bump, releaseAs, err = analyzeBump(repoPath, info)
+		if err != nil {
+			return "", err
+		}
+		if releaseAs != "" {
+			if *bf.prerelease == "" {
+				return info.ReleaseAs(releaseAs)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: Release-As: %s is not applied to prereleases\n", releaseAs)
+		}
+	} else {
+		bump, err = semver.ParseBump(*bf.bump)
+		if err != nil {
+			return "", usageError(err)
+		}
+	}
+
+	if *bf.prerelease != "" {
+		repo, err := version.OpenRepository(repoPath)
+		if err != nil {
+			return "", err
+		}
+		return info.NextPrerelease(repo, bump, *bf.prerelease)
+	}
+	return info.NextVersion(bump)
+}
+
+// analyzeBump determines the bump from the Conventional Commits between the
+// latest tag and HEAD, and the version pinned by a Release-As trailer
+func analyzeBump(repoPath string, info *version.Info) (semver.Bump, string, error) {
+	repo, err := version.OpenRepository(repoPath)
+	if err != nil {
+		return semver.None, "", err
+	}
+
+	result, err := conventional.Analyze(repo, plumbing.NewHash(info.GitCommit), info.LatestTag)
+	if err != nil {
+		return semver.None, "", err
+	}
+	return result.Bump, result.ReleaseAs, nil
+}
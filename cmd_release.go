@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/fxsml/gitversion/pkg/changelog"
+	"github.com/fxsml/gitversion/pkg/release"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runRelease implements the release command, which computes the next version,
+// prepends its changelog section and commits it, creates the annotated tag
+// and optionally pushes both. Every step is printed to stderr; with -dry-run
+// nothing is changed.
+func runRelease(args []string) error {
+	fs := flag.NewFlagSet("release", flag.ContinueOnError)
+	bf := addBumpFlags(fs)
+	var (
+		changelogFlag = fs.String("changelog", "CHANGELOG.md", "File in the repository root to prepend the changelog section to (empty to skip)")
+		commitFlag    = fs.String("commit-message", "chore(release): {{.Tag}}", "Commit message template for the changelog (fields: .Tag, .Previous, .Info)")
+		messageFlag   = fs.String("message", "Release {{.Tag}}", "Tag message template (fields: .Tag, .Previous, .Info)")
+		pushFlag      = fs.Bool("push", false, "Push the branch and the tag to the remote")
+		remoteFlag    = fs.String("remote", release.DefaultRemote, "Remote to push to and build changelog links from")
+		sshKeyFlag    = fs.String("ssh-key", "", "Private key file for pushing to SSH remotes (default: ssh-agent)")
+		signFlag      = fs.Bool("sign", false, "Sign the tag with the OpenPGP or SSH private key of -signing-key")
+		signingKey    = fs.String("signing-key", "", "Private key file for -sign (default: $GITVERSION_SIGNING_KEY or user.signingKey)")
+		dryRunFlag    = fs.Bool("dry-run", false, "Show the steps without changing anything")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+	if *vf.ref != "" {
+		return usageError(errors.New("-ref cannot be used with release, which commits to the checked out branch"))
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+	switch {
+	case !info.HasCommits:
+		return fmt.Errorf("cannot release: %w", version.ErrNoCommits)
+	case info.IsDirty:
+		return errors.New("cannot release: the working tree has uncommitted changes")
+	case info.GitBranch == "HEAD":
+		return errors.New("cannot release: HEAD is detached")
+	}
+
+	next, err := nextVersion(*vf.path, info, bf)
+	if err != nil {
+		return err
+	}
+	// The tag keeps the component namespace and tag prefix stripped from the
+	// printed version, so that later runs find it
+	tag := info.TagName(next)
+	if tag == info.LatestTag {
+		return fmt.Errorf("no version bump: %s is already tagged", tag)
+	}
+	if err := vf.checkMonotonic(tag); err != nil {
+		return err
+	}
+
+	repo, err := version.OpenRepository(*vf.path)
+	if err != nil {
+		return err
+	}
+	// Fail on unusable keys and credentials before anything is changed
+	signer, err := tagSigner(repo, *signFlag, *signingKey)
+	if err != nil {
+		return err
+	}
+	var auth transport.AuthMethod
+	if *pushFlag {
+		if auth, err = release.Auth(repo, *remoteFlag, *sshKeyFlag); err != nil {
+			return err
+		}
+	}
+	data := tagMessageData{Tag: tag, Previous: info.LatestTag, Info: info}
+	step := func(format string, a ...any) {
+		if *dryRunFlag {
+			format = "[dry-run] " + format
+		}
+		fmt.Fprintf(os.Stderr, "==> "+format+"\n", a...)
+	}
+
+	previous := info.LatestTag
+	if previous == "" {
+		previous = "(none)"
+	}
+	step("Release %s on %s (previous: %s)", next, info.GitBranch, previous)
+
+	target := plumbing.NewHash(info.GitCommit)
+	if *changelogFlag != "" {
+		section, err := changelogSection(repo, target, tag, info.LatestTag, *remoteFlag)
+		if err != nil {
+			return err
+		}
+		commitMessage, err := renderTagMessage(*commitFlag, data)
+		if err != nil {
+			return err
+		}
+
+		w, err := repo.Worktree()
+		if err != nil {
+			return fmt.Errorf("failed to get worktree: %w", err)
+		}
+		path := filepath.Join(w.Filesystem.Root(), *changelogFlag)
+
+		step("Prepend changelog section to %s:\n%s", *changelogFlag, section)
+		step("Commit %s: %s", *changelogFlag, commitMessage)
+		if !*dryRunFlag {
+			if err := changelog.Prepend(path, section); err != nil {
+				return err
+			}
+			target, err = release.CommitFiles(repo, commitMessage, filepath.ToSlash(*changelogFlag))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	message, err := renderTagMessage(*messageFlag, data)
+	if err != nil {
+		return err
+	}
+	step("Create tag %s", tag)
+	if !*dryRunFlag {
+		if err := createTag(repo, tag, target, message, signer); err != nil {
+			return err
+		}
+	}
+
+	if *pushFlag {
+		if *changelogFlag != "" {
+			step("Push branch %s to %s", info.GitBranch, *remoteFlag)
+			if !*dryRunFlag {
+				if err := release.PushBranch(repo, *remoteFlag, info.GitBranch, auth); err != nil {
+					return err
+				}
+			}
+		}
+		step("Push tag %s to %s", tag, *remoteFlag)
+		if !*dryRunFlag {
+			if err := release.PushTag(repo, *remoteFlag, tag, auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println(next)
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runSatisfies implements the satisfies command, which checks the given or
+// the computed version against a SemVer constraint. It exits with
+// exitCheckFailed if the version does not satisfy the constraint.
+func runSatisfies(args []string) error {
+	fs := flag.NewFlagSet("satisfies", flag.ContinueOnError)
+	var (
+		prereleaseFlag = fs.Bool("include-prerelease", false, "Let prereleases, e.g. v1.5.0-rc.1, satisfy the constraint by precedence alone")
+		quietFlag      = fs.Bool("q", false, "Print nothing, only set the exit status")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return usageError(errors.New("usage: gitversion satisfies [options] <constraint> [version]"))
+	}
+
+	constraint, err := semver.ParseConstraint(fs.Arg(0))
+	if err != nil {
+		return usageError(err)
+	}
+	constraint.IncludePrerelease = *prereleaseFlag
+
+	// Versions of git describe and branch slugs are checked by their latest
+	// tag, as their suffix would make them prereleases of it
+	ver := fs.Arg(1)
+	var v semver.Version
+	if ver == "" {
+		info, err := vf.getVersionInfo()
+		if err != nil {
+			return err
+		}
+		ver = info.Version
+		if v, err = info.SemVer(); err != nil {
+			return err
+		}
+	} else if v, err = semver.Parse(strings.TrimPrefix(version.TrimDescribe(ver), *vf.tagPrefix)); err != nil {
+		return usageError(err)
+	}
+	if checked := strings.TrimPrefix(strings.TrimPrefix(ver, *vf.tagPrefix), "v"); checked != v.String() {
+		ver = fmt.Sprintf("%s (%s)", ver, v)
+	}
+
+	if !constraint.Check(v) {
+		return &exitError{
+			code:   exitCheckFailed,
+			reason: "unsatisfied",
+			err:    fmt.Errorf("version %s does not satisfy %s", ver, constraint),
+		}
+	}
+	if !*quietFlag {
+		fmt.Printf("Version %s satisfies %s\n", ver, constraint)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runServe implements the serve command, which serves version info of the
+// repositories below -path as JSON over HTTP
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	timeout := fs.Duration("timeout", 30*time.Second, "Maximum time to compute the version info of a request")
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	// Invalid flags fail at startup, not on every request
+	if _, err := vf.options(); err != nil {
+		return err
+	}
+	root, err := filepath.Abs(*vf.path)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/version", &versionHandler{
+		root:          root,
+		defaultBranch: *vf.defaultBranch,
+		options:       vf.optionsAt,
+		timeout:       *timeout,
+	})
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("Serving version info of %s on %s", root, *addr)
+	return server.ListenAndServe()
+}
+
+// versionHandler serves GET /version?repo=<dir>&ref=<ref>, where repo is a
+// directory relative to root (default: root itself) and ref an optional
+// branch, tag or commit (default: HEAD). Computing the version info is
+// aborted after timeout or when the client disconnects.
+type versionHandler struct {
+	root          string
+	defaultBranch string
+	// options returns the options for the repository at a path, so that
+	// each repository's own config file applies
+	options func(path string) ([]version.Option, error)
+	timeout time.Duration
+}
+
+func (h *versionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	repo := r.URL.Query().Get("repo")
+	if repo == "" {
+		repo = "."
+	}
+	if !filepath.IsLocal(repo) {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid repo %q", repo))
+		return
+	}
+	path := filepath.Join(h.root, repo)
+	if _, err := os.Stat(path); err != nil {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("repo %q not found", repo))
+		return
+	}
+
+	opts, err := h.options(path)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if ref := r.URL.Query().Get("ref"); ref != "" {
+		opts = append(opts, version.WithRef(ref))
+	}
+	ctx := r.Context()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	info, err := version.GetVersionInfoContext(ctx, path, h.defaultBranch, opts...)
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeJSONError(w, http.StatusGatewayTimeout, err)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// writeJSON writes v as JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+// writeJSONError writes {"error": "..."} with the given status code
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
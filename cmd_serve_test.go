@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// initServedRepo creates a repository with one commit in dir
+func initServedRepo(t *testing.T, dir string) {
+	t.Helper()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add("."); err != nil {
+		t.Fatalf("Failed to add files: %v", err)
+	}
+	_, err = w.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func TestVersionHandlerRepoConfig(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		initServedRepo(t, filepath.Join(root, name))
+	}
+	// Only repository a has a config file
+	if err := os.WriteFile(filepath.Join(root, "a", ".gitversion.yaml"), []byte("branches:\n  '*': 'custom-{slug}'\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	vf := addVersionFlags(fs)
+	if err := fs.Parse([]string{"-path", root, "-no-dirty-check"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	handler := &versionHandler{root: root, defaultBranch: "main", options: vf.optionsAt}
+
+	tests := map[string]string{
+		"a": "custom-master",
+		"b": "master-g",
+	}
+	for repo, prefix := range tests {
+		t.Run(repo, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version?repo="+repo, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			var info version.Info
+			if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !strings.HasPrefix(info.Version, prefix) {
+				t.Errorf("Version = %q, want prefix %q", info.Version, prefix)
+			}
+		})
+	}
+}
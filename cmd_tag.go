@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/fxsml/gitversion/pkg/release"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// tagMessageData is the data the tag message template is rendered with
+type tagMessageData struct {
+	Tag      string
+	Previous string
+	Info     *version.Info
+}
+
+// runTag implements the tag command, which creates an annotated tag for the
+// next version and optionally pushes it
+func runTag(args []string) error {
+	fs := flag.NewFlagSet("tag", flag.ContinueOnError)
+	bf := addBumpFlags(fs)
+	var (
+		messageFlag   = fs.String("message", "Release {{.Tag}}", "Tag message template (fields: .Tag, .Previous, .Info)")
+		changelogFlag = fs.Bool("annotate-changelog", false, "Append the changelog section of the release to the tag message")
+		pushFlag      = fs.Bool("push", false, "Push the created tag to the remote")
+		remoteFlag    = fs.String("remote", release.DefaultRemote, "Remote to push the tag to and build changelog links from")
+		sshKeyFlag    = fs.String("ssh-key", "", "Private key file for pushing to SSH remotes (default: ssh-agent)")
+		signFlag      = fs.Bool("sign", false, "Sign the tag with the OpenPGP or SSH private key of -signing-key")
+		signingKey    = fs.String("signing-key", "", "Private key file for -sign (default: $GITVERSION_SIGNING_KEY or user.signingKey)")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	info, err := vf.getVersionInfo()
+	if err != nil {
+		return err
+	}
+	if !info.HasCommits {
+		return fmt.Errorf("cannot tag: %w", version.ErrNoCommits)
+	}
+
+	next, err := nextVersion(*vf.path, info, bf)
+	if err != nil {
+		return err
+	}
+	// The tag keeps the component namespace and tag prefix stripped from the
+	// printed version, so that later runs find it
+	tag := info.TagName(next)
+	if tag == info.LatestTag {
+		return fmt.Errorf("no version bump: %s is already tagged", tag)
+	}
+	if err := vf.checkMonotonic(tag); err != nil {
+		return err
+	}
+
+	message, err := renderTagMessage(*messageFlag, tagMessageData{
+		Tag:      tag,
+		Previous: info.LatestTag,
+		Info:     info,
+	})
+	if err != nil {
+		return err
+	}
+
+	repo, err := version.OpenRepository(*vf.path)
+	if err != nil {
+		return err
+	}
+	signer, err := tagSigner(repo, *signFlag, *signingKey)
+	if err != nil {
+		return err
+	}
+	// Fail on unusable credentials before the tag is created
+	var auth transport.AuthMethod
+	if *pushFlag {
+		if auth, err = release.Auth(repo, *remoteFlag, *sshKeyFlag); err != nil {
+			return err
+		}
+	}
+	target := plumbing.NewHash(info.GitCommit)
+	if *changelogFlag {
+		section, err := changelogSection(repo, target, tag, info.LatestTag, *remoteFlag)
+		if err != nil {
+			return err
+		}
+		message = strings.TrimRight(message, "\n") + "\n\n" + section
+	}
+	if err := createTag(repo, tag, target, message, signer); err != nil {
+		return err
+	}
+
+	if *pushFlag {
+		if err := release.PushTag(repo, *remoteFlag, tag, auth); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(next)
+	return nil
+}
+
+// tagSigner returns the signer of -sign with the key file, else
+// GITVERSION_SIGNING_KEY or user.signingKey of git config, decrypted with
+// GITVERSION_SIGNING_PASSPHRASE; nil without -sign
+func tagSigner(repo *git.Repository, sign bool, keyFile string) (release.Signer, error) {
+	if !sign {
+		return nil, nil
+	}
+	if keyFile == "" {
+		keyFile = os.Getenv("GITVERSION_SIGNING_KEY")
+	}
+	if keyFile == "" {
+		var err error
+		if keyFile, err = release.SigningKey(repo); err != nil {
+			return nil, err
+		}
+	}
+	if keyFile == "" {
+		return nil, errors.New("-sign needs a key: set -signing-key, GITVERSION_SIGNING_KEY or user.signingKey")
+	}
+	return release.LoadSigner(keyFile, os.Getenv("GITVERSION_SIGNING_PASSPHRASE"))
+}
+
+// createTag creates the annotated tag, signed if the signer is not nil
+func createTag(repo *git.Repository, name string, hash plumbing.Hash, message string, signer release.Signer) error {
+	var err error
+	if signer != nil {
+		_, err = release.CreateSignedTag(repo, name, hash, message, signer)
+	} else {
+		_, err = release.CreateTag(repo, name, hash, message)
+	}
+	return err
+}
+
+// renderTagMessage renders the tag message template
+func renderTagMessage(format string, data tagMessageData) (string, error) {
+	tmpl, err := template.New("message").Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse message template: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render message template: %w", err)
+	}
+	return sb.String(), nil
+}
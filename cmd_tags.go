@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runTags implements the tags command, which lists the version tags or, with
+// -check-remote, compares them with the tags of the remote
+func runTags(args []string) error {
+	fs := flag.NewFlagSet("tags", flag.ContinueOnError)
+	var (
+		checkRemoteFlag = fs.Bool("check-remote", false, fmt.Sprintf("Compare the tags with the remote like git ls-remote; exit with status %d if tags are missing locally or moved", exitStaleTags))
+		remoteFlag      = fs.String("remote", version.DefaultFetchRemote, "Remote of -check-remote")
+		jsonFlag        = fs.Bool("json", false, "Show the tags as JSON")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	opts, err := vf.options()
+	if err != nil {
+		return err
+	}
+	if !*checkRemoteFlag {
+		tags, err := version.Tags(*vf.path, opts...)
+		if err != nil {
+			return err
+		}
+		if *jsonFlag {
+			if tags == nil {
+				tags = []string{}
+			}
+			data, err := json.MarshalIndent(tags, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		for _, tag := range tags {
+			fmt.Println(tag)
+		}
+		return nil
+	}
+
+	diff, err := version.CompareRemoteTags(*vf.path, *remoteFlag, opts...)
+	if err != nil {
+		return err
+	}
+	if *jsonFlag {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TAG\tSTATUS")
+		for _, group := range []struct {
+			status string
+			tags   []string
+		}{{"remote-only", diff.RemoteOnly}, {"local-only", diff.LocalOnly}, {"moved", diff.Moved}} {
+			for _, tag := range group.tags {
+				fmt.Fprintf(tw, "%s\t%s\n", tag, group.status)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if diff.Stale() {
+		return &exitError{code: exitStaleTags, reason: "stale_tags", err: fmt.Errorf("local tags are stale compared to %s: %d missing, %d moved", diff.Remote, len(diff.RemoteOnly), len(diff.Moved))}
+	}
+	return nil
+}
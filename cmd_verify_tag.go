@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// runVerifyTag implements the verify-tag command, which checks the version
+// tags at HEAD against the version gitversion would have tagged. It exits
+// with exitCheckFailed if a tag is invalid.
+func runVerifyTag(args []string) error {
+	fs := flag.NewFlagSet("verify-tag", flag.ContinueOnError)
+	var (
+		autoFlag = fs.Bool("auto", false, "Require the bump the Conventional Commits since the previous tag call for")
+		jsonFlag = fs.Bool("json", false, "Show the checks as JSON")
+	)
+	vf := addVersionFlags(fs)
+	if err := vf.parse(args); err != nil {
+		return err
+	}
+
+	opts, err := vf.options()
+	if err != nil {
+		return err
+	}
+	checks, err := version.VerifyHeadTags(*vf.path, *autoFlag, opts...)
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		if checks == nil {
+			checks = []version.TagCheck{}
+		}
+		data, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	} else if len(checks) == 0 {
+		fmt.Println("HEAD has no version tag")
+	}
+
+	var invalid []string
+	for _, check := range checks {
+		if !check.Valid() {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", check.Tag, strings.Join(check.Violations, "; ")))
+		} else if !*jsonFlag {
+			fmt.Printf("Tag %s is valid\n", check.Tag)
+		}
+	}
+	if len(invalid) > 0 {
+		return &exitError{
+			code:   exitCheckFailed,
+			reason: "invalid_tag",
+			err:    fmt.Errorf("invalid tag %s", strings.Join(invalid, ", ")),
+		}
+	}
+	return nil
+}
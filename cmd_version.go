@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// Version, Commit, Branch and BuildTime of gitversion itself, set with
+// -ldflags "$(gitversion ldflags)" at build time
+var (
+	Version   string
+	Commit    string
+	Branch    string
+	BuildTime string
+)
+
+// printVersion implements the version command and the -version flag, which
+// print the version of gitversion, read from the build info if it was built
+// without ldflags, e.g. with go install, and "(devel)" if neither is known
+func printVersion() {
+	v, commit, timeLabel, t := Version, Commit, "built", BuildTime
+	if v == "" {
+		v = "(devel)"
+		if info, ok := version.BuildInfo(); ok {
+			// The build info records the commit time, not the build time
+			v, commit, timeLabel, t = info.Version, info.GitCommit, "committed", info.CommitTime
+		}
+	}
+
+	fmt.Printf("gitversion %s\n", v)
+	if commit != "" {
+		fmt.Printf("  commit:    %s\n", commit)
+	}
+	if t != "" {
+		fmt.Printf("  %-10s %s\n", timeLabel+":", t)
+	}
+	fmt.Printf("  go:        %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// printDebugRefs prints the refs of the repository at -path and how each was
+// classified
+func printDebugRefs(vf *versionFlags) error {
+	opts, err := vf.options()
+	if err != nil {
+		return err
+	}
+	refs, err := version.DebugRefs(*vf.path, *vf.defaultBranch, opts...)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REF\tSTORAGE\tTARGET\tSTATUS")
+	for _, ref := range refs {
+		storage, target := ref.Storage, "-"
+		if storage == "" {
+			storage = "-"
+		}
+		if !ref.Target.IsZero() {
+			target = ref.Target.String()[:7]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ref.Name, storage, target, ref.Status)
+	}
+	return w.Flush()
+}
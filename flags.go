@@ -0,0 +1,579 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fxsml/gitversion/pkg/calver"
+	"github.com/fxsml/gitversion/pkg/config"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// versionFlags holds the flags shared by all commands that compute version info
+type versionFlags struct {
+	// fs is the flag set the flags are registered on, names the names of
+	// the version flags in it
+	fs    *flag.FlagSet
+	names []string
+
+	path           *string
+	paths          *pathList
+	config         *string
+	defaultBranch  *string
+	queryDefault   *bool
+	queryTimeout   *time.Duration
+	tagPrefix      *string
+	tagExcludes    *stringList
+	stripTagPrefix *bool
+	componentPath  *string
+	component      *string
+	ref            *string
+	mode           *string
+	scheme         *string
+	calverFormat   *string
+	mainlineBase   *string
+	noDirtyCheck   *bool
+	failOnDirty    *bool
+	dirtyExcludes  *stringList
+	dirtyUntracked *bool
+	dirtyFormat    *string
+	firstParent    *bool
+	maxDepth       *int
+	fetchTags      *bool
+	deepen         *bool
+	checkTags      *bool
+	monotonic      *bool
+	verifySigs     *string
+	cache          *bool
+	cacheDir       *string
+	profile        *bool
+	backend        *string
+	superproject   *bool
+	recurseSubs    *bool
+	abbrev         *string
+	metadata       *bool
+	metadataFormat *string
+	noCI           *bool
+	ceilingDirs    *stringList
+	oneFilesystem  *bool
+	verbose        *bool
+	debug          *bool
+}
+
+// pathList is the -path flag. Commands for a single repository use the last
+// value, the main command versions all given repositories.
+type pathList struct {
+	path *string
+	all  []string
+}
+
+func (l *pathList) String() string {
+	if l.path == nil {
+		return ""
+	}
+	return *l.path
+}
+
+func (l *pathList) Set(value string) error {
+	*l.path = value
+	l.all = append(l.all, value)
+	return nil
+}
+
+// stringList is a flag that can be repeated and also accepts comma-separated values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// dirtySuffixValue is the deprecated -dirty-suffix flag, an alias setting
+// -dirty-format to the format of timestamp or hash
+type dirtySuffixValue struct {
+	format *string
+}
+
+func (v dirtySuffixValue) String() string {
+	return ""
+}
+
+func (v dirtySuffixValue) Set(value string) error {
+	switch value {
+	case "timestamp":
+		*v.format = version.DirtyFormatTimestamp
+	case "hash":
+		*v.format = version.DirtyFormatHash
+	default:
+		return fmt.Errorf("must be timestamp or hash")
+	}
+	return nil
+}
+
+// errorFormatValue is the -error-format flag: text or json
+type errorFormatValue string
+
+func (v *errorFormatValue) String() string {
+	return string(*v)
+}
+
+func (v *errorFormatValue) Set(value string) error {
+	if value != "text" && value != "json" {
+		return fmt.Errorf("must be text or json")
+	}
+	*v = errorFormatValue(value)
+	return nil
+}
+
+// addVersionFlags registers the shared version flags on the given flag set
+func addVersionFlags(fs *flag.FlagSet) *versionFlags {
+	other := make(map[string]bool)
+	fs.VisitAll(func(fl *flag.Flag) { other[fl.Name] = true })
+	path := "."
+	f := &versionFlags{
+		fs:             fs,
+		path:           &path,
+		paths:          &pathList{path: &path},
+		config:         fs.String("config", "", "Config file (default: .gitversion.yaml or gitversion.yaml in the repository root)"),
+		defaultBranch:  fs.String("default-branch", "", "Default branch name (auto-detected if not set)"),
+		queryDefault:   fs.Bool("query-default-branch", false, "Without origin/HEAD, query the HEAD of origin for the default branch instead of guessing main or master"),
+		queryTimeout:   fs.Duration("query-timeout", version.DefaultRemoteHEADTimeout, "Time -query-default-branch waits for origin"),
+		tagPrefix:      fs.String("tag-prefix", "", "Only consider tags starting with this prefix (e.g. v or release/)"),
+		tagExcludes:    &stringList{},
+		stripTagPrefix: fs.Bool("strip-tag-prefix", false, "Remove the tag prefix from the emitted version"),
+		componentPath:  fs.String("component-path", "", "Version only the given subdirectory of a monorepo"),
+		component:      fs.String("component", "", "Version only the named component of the config file, with its path and tag template"),
+		ref:            fs.String("ref", "", "Compute the version for this branch, tag or commit instead of HEAD (skips the dirty check)"),
+		mode:           fs.String("mode", "githubflow", "Versioning strategy: "+strings.Join(version.StrategyNames(), ", ")),
+		scheme:         fs.String("scheme", "semver", "Version scheme: semver, calver, mainline or snapshot"),
+		calverFormat:   fs.String("calver-format", calver.DefaultFormat, "CalVer format for -scheme calver (e.g. YYYY.0M.MICRO)"),
+		mainlineBase:   fs.String("mainline-base", "0.1", "MAJOR.MINOR of -scheme mainline versions before the first tag"),
+		noDirtyCheck:   fs.Bool("no-dirty-check", false, "Skip the check for uncommitted changes"),
+		failOnDirty:    fs.Bool("fail-on-dirty", false, fmt.Sprintf("Exit with status %d if there are uncommitted changes", exitDirty)),
+		dirtyExcludes:  &stringList{},
+		dirtyUntracked: fs.Bool("dirty-untracked", false, "Also treat untracked files that are not ignored as uncommitted changes"),
+		dirtyFormat:    fs.String("dirty-format", "timestamp", "Suffix of dirty versions: timestamp, hash or a template like -dirty or +dirty.{sha}"),
+		firstParent:    fs.Bool("first-parent", false, "Follow only the first parent of merge commits when searching tags"),
+		maxDepth:       fs.Int("max-describe-depth", 0, "Stop searching for the latest tag after this many commits and fall back to <branch-slug>-g<hash> (0: no limit)"),
+		fetchTags:      fs.Bool("fetch-tags", false, "Fetch all tags from origin before computing the version"),
+		checkTags:      fs.Bool("check-remote-tags", false, fmt.Sprintf("Compare the tags with origin first and exit with status %d if tags are missing locally or moved", exitStaleTags)),
+		monotonic:      fs.Bool("monotonic", false, fmt.Sprintf("Exit with status %d if the version is not higher than every release tag", exitNotMonotonic)),
+		deepen:         fs.Bool("deepen", false, "Fetch more history of a shallow clone until a tag is reachable"),
+		verifySigs:     fs.String("verify-signatures", "", "Verify HEAD and latest tag signatures against an OpenPGP keyring or SSH allowed_signers file"),
+		cache:          fs.Bool("cache", false, "Cache the repository state between invocations on an unchanged repository"),
+		cacheDir:       fs.String("cache-dir", "", "Directory of the cache (implies -cache, default: gitversion in the user cache directory)"),
+		profile:        fs.Bool("profile", false, "Report the time spent opening the repository, enumerating tags, walking history and checking the working tree on stderr"),
+		backend:        fs.String("backend", "go-git", "Repository backend: "+strings.Join(version.BackendNames(), ", ")),
+		superproject:   fs.Bool("superproject", false, "Include the superproject version info if the repository is a submodule"),
+		recurseSubs:    fs.Bool("recurse-submodules", false, "Include the version info of all submodules at their recorded commits, recursively"),
+		abbrev:         fs.String("abbrev", "", "Length of abbreviated commit hashes, auto or no (default: core.abbrev or 7)"),
+		metadata:       fs.Bool("metadata", false, "Emit SemVer build metadata (+g<sha>.<branch-slug>) instead of the -g<sha> suffix"),
+		metadataFormat: fs.String("metadata-format", "", "Go template for the build metadata (implies -metadata)"),
+		noCI:           fs.Bool("no-ci", false, "Do not read the branch, tag and default branch of a detached HEAD from CI environment variables"),
+		ceilingDirs:    &stringList{},
+		verbose:        fs.Bool("v", false, "Trace how the version is computed on stderr"),
+		debug:          fs.Bool("vv", false, "Like -v, also tracing each directory searched, the tags considered and git commands"),
+		oneFilesystem:  fs.Bool("one-filesystem", false, "Stop the search for the repository at filesystem boundaries such as mount points"),
+	}
+	fs.Var(f.paths, "path", "Path to Git repository")
+	fs.Var(f.tagExcludes, "tag-exclude", "Glob or /regexp/ of tags to ignore, e.g. *-rc* (repeatable, comma-separated)")
+	fs.Var(dirtySuffixValue{f.dirtyFormat}, "dirty-suffix", "Deprecated: use -dirty-format timestamp or hash")
+	fs.Var(f.dirtyExcludes, "dirty-exclude", "Path or glob to ignore in the dirty check (repeatable, comma-separated)")
+	fs.Var(f.ceilingDirs, "ceiling-dir", "Directory the search for the repository does not walk up into, like GIT_CEILING_DIRECTORIES (repeatable)")
+	fs.Var(&errorFormat, "error-format", "Format of errors on stderr: text, or json for {\"error\", \"reason\", \"exitCode\"}")
+	fs.VisitAll(func(fl *flag.Flag) {
+		if !other[fl.Name] {
+			f.names = append(f.names, fl.Name)
+		}
+	})
+	return f
+}
+
+// parse parses the command line arguments and applies the git config with
+// applyGitConfig
+func (f *versionFlags) parse(args []string) error {
+	if err := f.fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	return f.applyGitConfig()
+}
+
+// applyGitConfig sets the version flags not given on the command line from
+// the gitversion.<flag> keys of the git config of the repository at -path,
+// e.g. gitversion.tag-prefix or gitversion.fetch-tags. Repeatable flags take
+// all values of their key. Several repositories, and a path that is not a
+// repository, leave the flags alone.
+func (f *versionFlags) applyGitConfig() error {
+	if len(f.paths.all) > 1 {
+		return nil
+	}
+	cfg, err := version.ReadGitConfigAt(*f.path, version.WithCeilingDirectories(*f.ceilingDirs...), version.WithOneFilesystem(*f.oneFilesystem))
+	if err != nil {
+		// Reported once the version is computed
+		return nil
+	}
+	given := make(map[string]bool)
+	f.fs.Visit(func(fl *flag.Flag) { given[fl.Name] = true })
+	for _, name := range f.names {
+		if given[name] || name == "path" {
+			continue
+		}
+		fl := f.fs.Lookup(name)
+		for _, value := range cfg.GetAll("gitversion." + name) {
+			if b, ok := fl.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+				v, ok := version.ParseGitBool(value, true)
+				if !ok {
+					return fmt.Errorf("invalid gitversion.%s in git config: %q is not a boolean", name, value)
+				}
+				value = strconv.FormatBool(v)
+			}
+			if err := f.fs.Set(name, value); err != nil {
+				return fmt.Errorf("invalid gitversion.%s in git config: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// singlePath returns an error if -path was given more than once
+func (f *versionFlags) singlePath() error {
+	if len(f.paths.all) > 1 {
+		return usageError(fmt.Errorf("-path can only be given once for this command"))
+	}
+	return nil
+}
+
+// options converts the flags into version options for the repository at -path
+func (f *versionFlags) options() ([]version.Option, error) {
+	if err := f.singlePath(); err != nil {
+		return nil, err
+	}
+	return f.optionsAt(*f.path)
+}
+
+// optionsAt converts the flags into version options for the repository at path,
+// whose config file is used unless -config is set; an empty path is a remote
+// repository without one. Invalid flag values are usage errors.
+func (f *versionFlags) optionsAt(path string) ([]version.Option, error) {
+	if *f.failOnDirty && *f.noDirtyCheck {
+		return nil, usageError(fmt.Errorf("-fail-on-dirty cannot be combined with -no-dirty-check"))
+	}
+
+	strategy, err := f.strategy()
+	if err != nil {
+		return nil, usageError(err)
+	}
+
+	backend, err := version.BackendByName(*f.backend)
+	if err != nil {
+		return nil, usageError(err)
+	}
+
+	dirtyFormat, err := version.ParseDirtyFormat(*f.dirtyFormat)
+	if err != nil {
+		return nil, usageError(err)
+	}
+
+	opts := []version.Option{
+		version.WithBackend(backend),
+		version.WithTagPrefix(*f.tagPrefix),
+		version.WithTagExcludes(*f.tagExcludes...),
+		version.WithStripTagPrefix(*f.stripTagPrefix),
+		version.WithStrategy(strategy),
+		version.WithDirtyCheck(!*f.noDirtyCheck),
+		version.WithDirtyExcludes(*f.dirtyExcludes...),
+		version.WithDirtyUntracked(*f.dirtyUntracked),
+		version.WithDirtyFormat(dirtyFormat),
+		version.WithFirstParent(*f.firstParent),
+		version.WithMaxDescribeDepth(*f.maxDepth),
+		version.WithSuperproject(*f.superproject),
+		version.WithRecurseSubmodules(*f.recurseSubs),
+		version.WithDeepen(*f.deepen),
+		version.WithCeilingDirectories(*f.ceilingDirs...),
+		version.WithOneFilesystem(*f.oneFilesystem),
+	}
+	if logger := f.logger(); logger != nil {
+		if path != "" {
+			logger = logger.With("path", path)
+		}
+		opts = append(opts, version.WithLogger(logger))
+	}
+	cfg, err := f.loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	componentOpts, err := f.componentOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, componentOpts...)
+	for _, t := range cfg.Branches {
+		opts = append(opts, version.WithBranchTemplates(version.BranchTemplate{Pattern: t.Pattern, Template: t.Template}))
+	}
+	for _, hook := range cfg.Hooks {
+		opts = append(opts, version.WithModifiers(version.ExecModifier{Command: hook.Command, Dir: cfg.Dir}))
+	}
+	if len(cfg.SharedPaths) > 0 {
+		opts = append(opts, version.WithSharedPaths(cfg.SharedPaths...))
+	}
+	if cfg.PullRequestTemplate != "" {
+		opts = append(opts, version.WithPullRequestTemplate(cfg.PullRequestTemplate))
+	}
+	if len(cfg.ReleaseBranches) > 0 {
+		rules := make([]version.ReleaseBranch, 0, len(cfg.ReleaseBranches))
+		for _, rule := range cfg.ReleaseBranches {
+			rules = append(rules, version.ReleaseBranch{Pattern: rule.Pattern, Label: rule.Label})
+		}
+		opts = append(opts, version.WithReleaseBranches(rules...))
+	}
+	if len(cfg.PrereleaseLabels) > 0 {
+		rules := make([]version.PrereleaseLabel, 0, len(cfg.PrereleaseLabels))
+		for _, rule := range cfg.PrereleaseLabels {
+			rules = append(rules, version.PrereleaseLabel{Pattern: rule.Pattern, Label: rule.Label, Numbering: rule.Numbering})
+		}
+		opts = append(opts, version.WithPrereleaseLabels(rules...))
+	}
+	if *f.fetchTags {
+		opts = append(opts, version.WithFetchTags(version.DefaultFetchRemote))
+	}
+	if *f.queryDefault {
+		opts = append(opts, version.WithRemoteHEADQuery(*f.queryTimeout))
+	}
+	if *f.checkTags {
+		opts = append(opts, version.WithRemoteTagCheck(version.DefaultFetchRemote))
+	}
+	if *f.monotonic {
+		opts = append(opts, version.WithMonotonic(true))
+	}
+	if *f.ref != "" {
+		opts = append(opts, version.WithRef(*f.ref))
+	}
+	if *f.verifySigs != "" {
+		opts = append(opts, version.WithVerifySignatures(*f.verifySigs))
+	}
+	if *f.cache || *f.cacheDir != "" {
+		dir := *f.cacheDir
+		if dir == "" {
+			userDir, err := os.UserCacheDir()
+			if err != nil {
+				return nil, fmt.Errorf("failed to find cache directory: %w", err)
+			}
+			dir = filepath.Join(userDir, "gitversion")
+		}
+		opts = append(opts, version.WithCache(dir))
+	}
+	if *f.abbrev != "" {
+		abbrev, err := version.ParseAbbrev(*f.abbrev)
+		if err != nil {
+			return nil, usageError(err)
+		}
+		opts = append(opts, version.WithAbbrev(abbrev))
+	}
+	if *f.metadata || *f.metadataFormat != "" {
+		opts = append(opts, version.WithMetadata(*f.metadataFormat))
+	}
+	return opts, nil
+}
+
+// componentOptions returns the options versioning the component selected by
+// -component or -component-path, if any
+func (f *versionFlags) componentOptions(cfg *config.Config) ([]version.Option, error) {
+	switch {
+	case *f.component != "" && *f.componentPath != "":
+		return nil, usageError(fmt.Errorf("-component cannot be combined with -component-path"))
+	case *f.component != "":
+		for _, c := range cfg.Components {
+			if c.Name == *f.component {
+				return componentOptions(cfg, c)
+			}
+		}
+		return nil, usageError(fmt.Errorf("unknown component %q: not listed under components in the config file", *f.component))
+	case *f.componentPath != "":
+		p := path.Clean(strings.Trim(filepath.ToSlash(*f.componentPath), "/"))
+		for _, c := range cfg.Components {
+			if c.Path == p {
+				return componentOptions(cfg, c)
+			}
+		}
+		return componentOptions(cfg, config.Component{Name: path.Base(p), Path: *f.componentPath})
+	}
+	return nil, nil
+}
+
+// componentOptions returns the options versioning the component, with the tag
+// namespace of its tag template or else the one of the config file
+func componentOptions(cfg *config.Config, c config.Component) ([]version.Option, error) {
+	opts := []version.Option{version.WithComponentPath(c.Path)}
+	template := c.TagTemplate
+	if template == "" {
+		template = cfg.TagTemplate
+	}
+	if template != "" {
+		namespace, err := version.ComponentTagNamespace(template, c.Name, c.Path)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", c.Name, err)
+		}
+		opts = append(opts, version.WithComponentTagNamespace(namespace))
+	}
+	return opts, nil
+}
+
+// loadConfig loads the config file given by -config or found in the
+// repository at repoPath, or returns an empty config if there is none or no
+// repoPath
+func (f *versionFlags) loadConfig(repoPath string) (*config.Config, error) {
+	path := *f.config
+	if path == "" && repoPath == "" {
+		return &config.Config{}, nil
+	}
+	if path == "" {
+		var err error
+		path, err = config.Find(repoPath)
+		if err != nil || path == "" {
+			return &config.Config{}, err
+		}
+	}
+	return config.Load(path)
+}
+
+// logger returns the logger writing to stderr selected by -v and -vv, or nil
+func (f *versionFlags) logger() *slog.Logger {
+	level := slog.LevelInfo
+	switch {
+	case *f.debug:
+		level = version.LevelTrace
+	case !*f.verbose:
+		return nil
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+// strategy returns the strategy selected by -scheme, -mode and the options of the scheme
+func (f *versionFlags) strategy() (version.Strategy, error) {
+	switch *f.scheme {
+	case "semver":
+		return version.StrategyByName(*f.mode)
+	case "calver":
+		format, err := calver.ParseFormat(*f.calverFormat)
+		if err != nil {
+			return nil, err
+		}
+		return version.CalVer(format), nil
+	case "mainline":
+		major, minor, err := version.ParseMainlineBase(*f.mainlineBase)
+		if err != nil {
+			return nil, err
+		}
+		return version.Mainline(major, minor), nil
+	case "snapshot":
+		return version.Snapshot(), nil
+	default:
+		return nil, fmt.Errorf("unknown scheme %q: must be semver, calver, mainline or snapshot", *f.scheme)
+	}
+}
+
+// getVersionInfo computes the version info according to the flags. With
+// -fail-on-dirty a dirty working tree is an exitError with exitDirty. A
+// shallow clone without a reachable tag is reported on stderr. The branch,
+// tag and default branch of a detached HEAD are read from the CI environment.
+// The extra options are applied after the flags.
+func (f *versionFlags) getVersionInfo(extra ...version.Option) (*version.Info, error) {
+	if err := f.singlePath(); err != nil {
+		return nil, err
+	}
+	var opts []version.Option
+	if ci := f.detectCI(); ci != nil {
+		opts = append(opts, version.WithCI(ci))
+	}
+	return f.getVersionInfoAt(*f.path, append(opts, extra...)...)
+}
+
+// checkMonotonic checks with -monotonic that the version about to be tagged
+// is higher than every release tag
+func (f *versionFlags) checkMonotonic(next string) error {
+	if !*f.monotonic {
+		return nil
+	}
+	opts, err := f.options()
+	if err != nil {
+		return err
+	}
+	return version.CheckMonotonic(*f.path, next, opts...)
+}
+
+// detectCI returns the CI build from the environment unless -no-ci is set
+func (f *versionFlags) detectCI() *version.CI {
+	if *f.noCI {
+		return nil
+	}
+	return version.DetectCI(os.Getenv)
+}
+
+// getVersionInfoAt is like getVersionInfo for the repository at path, with
+// the given options in addition to the flags
+func (f *versionFlags) getVersionInfoAt(path string, extra ...version.Option) (*version.Info, error) {
+	opts, err := f.optionsAt(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.computeVersionInfo(path, append(opts, extra...), func(opts []version.Option) (*version.Info, error) {
+		return version.GetVersionInfo(path, *f.defaultBranch, opts...)
+	})
+}
+
+// getVersionInfoFromURL is like getVersionInfo for the remote repository at
+// url, cloned into memory. The CI environment and a config file of the
+// current directory do not apply to it; -config does.
+func (f *versionFlags) getVersionInfoFromURL(url string) (*version.Info, error) {
+	opts, err := f.optionsAt("")
+	if err != nil {
+		return nil, err
+	}
+	return f.computeVersionInfo(url, opts, func(opts []version.Option) (*version.Info, error) {
+		return version.FromURL(url, *f.defaultBranch, opts...)
+	})
+}
+
+// computeVersionInfo computes the version info of the repository named name
+// with get and the options, reporting the profile and warnings on stderr
+func (f *versionFlags) computeVersionInfo(name string, opts []version.Option, get func([]version.Option) (*version.Info, error)) (*version.Info, error) {
+	var profile *version.Profile
+	if *f.profile {
+		profile = &version.Profile{}
+		opts = append(opts, version.WithProfile(profile))
+	}
+	start := time.Now()
+	info, err := get(opts)
+	if profile != nil {
+		// Print the profile at once, as several repositories are versioned in parallel
+		fmt.Fprintf(os.Stderr, "Profile of %s:\n%s%-10s%v\n", name, profile, "total", time.Since(start))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.IsShallow && info.LatestTag == "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s is a shallow clone without a reachable tag, the version may be wrong; fetch the full history (e.g. fetch-depth: 0) or use -deepen\n", name)
+	}
+	if info.DescribeTruncated {
+		fmt.Fprintf(os.Stderr, "Warning: no tag found within %d commits of %s, falling back to %s\n", *f.maxDepth, name, info.Version)
+	}
+	if *f.failOnDirty && info.IsDirty {
+		return nil, &exitError{code: exitDirty, reason: "dirty", err: fmt.Errorf("working tree has uncommitted changes (version %s)", info.Version)}
+	}
+	return info, nil
+}
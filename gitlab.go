@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// defaultGitLabDotenv is the dotenv report written by -gitlab
+const defaultGitLabDotenv = "gitversion.env"
+
+// writeGitLabDotenv writes the version info as prefixed variables to the
+// dotenv file, to be declared as artifacts:reports:dotenv in .gitlab-ci.yml
+func writeGitLabDotenv(info *version.Info, envPrefix, path string) error {
+	if os.Getenv("GITLAB_CI") != "true" {
+		return fmt.Errorf("GITLAB_CI is not set: not running in GitLab CI?")
+	}
+	// GitLab does not unquote dotenv values, so they are written as they are
+	var sb strings.Builder
+	for _, f := range info.Fields() {
+		fmt.Fprintf(&sb, "%s%s=%s\n", envPrefix, strings.ToUpper(f.Name), f.Value)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
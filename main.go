@@ -1,9 +1,14 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/fxsml/gitversion/pkg/version"
 )
@@ -13,56 +18,434 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println("USAGE:")
 	fmt.Println("  gitversion [options]")
+	fmt.Println("  gitversion <command> [options]")
 	fmt.Println("  gitversion help")
+	fmt.Println("  gitversion version")
+	fmt.Println()
+	fmt.Println("COMMANDS:")
+	fmt.Println("  ldflags                Print a go build -ldflags string with version variables")
+	fmt.Println("  generate               Write version_gen.go with version constants into a package (-pkg, -package)")
+	fmt.Println("  env                    Print the version info as shell-quoted variables (-prefix, -lowercase, -export)")
+	fmt.Println("  next                   Print the next SemVer version (-bump patch|minor|major, -auto, -prerelease)")
+	fmt.Println("  tag                    Create an annotated tag for the next version (-message, -annotate-changelog, -sign, -push)")
+	fmt.Println("  changelog              Render commits since the latest tag as Markdown")
+	fmt.Println("  release                Update the changelog, commit, tag and push the next version (-dry-run)")
+	fmt.Println("  inject <file>...       Write the version into package.json, Chart.yaml, pyproject.toml, VERSION")
+	fmt.Println("  inject-helm            Set version (made SemVer) and appVersion in a Helm Chart.yaml (-chart <dir>)")
+	fmt.Println("  compare <refA> <refB>  Compare the versions at two refs: tag order, commit distance, bump required (-exit-code: exit 9)")
+	fmt.Println("  affected -since <ref>  List the monorepo components with changes since the merge base with ref")
+	fmt.Println("  components             Version each monorepo component and report changes since its tag (-json, -matrix)")
+	fmt.Println("  tags                   List the version tags; -check-remote compares them with origin (exit 5 if stale)")
+	fmt.Println("  history                List the release tags in SemVer order with dates, commits and authors (-json)")
+	fmt.Println("  explain                Print step by step how the version was computed: branch rule, tag, distance, dirty")
+	fmt.Println("  check [version]        Validate the version (-semver, -stable, -regex); exit 8 if invalid")
+	fmt.Println("  verify-tag             Check that the version tags at HEAD follow the previous tag; exit 8 if invalid")
+	fmt.Println("  satisfies <constraint> Check the version against a SemVer range like \">=1.4 <2\"; exit 8 if not")
+	fmt.Println("  docker-tags            Print OCI image tags for the version (-image)")
+	fmt.Println("  goreleaser             Print GORELEASER_CURRENT_TAG/PREVIOUS_TAG and version variables (-export, -flags)")
+	fmt.Println("  serve                  Serve version info as JSON over HTTP (-addr, GET /version?repo=&ref=)")
 	fmt.Println()
 	fmt.Println("OPTIONS:")
 	fmt.Println("  -detailed              Show detailed version information")
 	fmt.Println("  -short                 Show only the version string (default)")
-	fmt.Println("  -path <path>           Path to Git repository (default: .)")
+	fmt.Println("  -json                  Show version information as JSON")
+	fmt.Println("  -format <template>     Render output using a Go text/template")
+	fmt.Println("  -format go-pseudo      Show the Go module pseudo-version (e.g. v1.2.4-0.20240102150405-abcdef123456)")
+	fmt.Println("  -format stamp          Show Bazel workspace status lines (STABLE_VERSION, STABLE_GIT_COMMIT, ...)")
+	fmt.Println("  -format sbom           Show a CycloneDX metadata.component fragment to merge into an SBOM")
+	fmt.Println("  -format provenance     Show the SLSA provenance configSource and materials (VCS URI, commit, ref)")
+	fmt.Println("  -env                   Show version information as dotenv/shell variables")
+	fmt.Println("  -env-prefix <prefix>   Variable name prefix for -env (default: GITVERSION_)")
+	fmt.Println("  -github-actions        Write outputs to $GITHUB_OUTPUT and variables to $GITHUB_ENV")
+	fmt.Println("  -gitlab                Write a GitLab dotenv report (-gitlab-dotenv <file>, default: gitversion.env)")
+	fmt.Println("  -azure-devops          Set Azure Pipelines variables and build number with logging commands")
+	fmt.Println("  -path <path>           Path to Git repository (default: .); repeat for a report of several")
+	fmt.Println("  -ceiling-dir <dir>     Do not search for the repository in dir and above (repeatable)")
+	fmt.Println("  -one-filesystem        Do not search for the repository across filesystem boundaries")
+	fmt.Println("  -url <url>             Version a remote repository, cloned into memory (auth: GITVERSION_TOKEN or ssh-agent)")
+	fmt.Println("  -paths-file <file>     Report the repositories listed in the file, one per line (- for stdin)")
+	fmt.Println("  -config <file>         Config file (default: .gitversion.yaml in the repository root)")
 	fmt.Println("  -default-branch <name> Default branch name (auto-detected if not set)")
+	fmt.Println("  -query-default-branch  Without origin/HEAD, ask origin for its HEAD (-query-timeout, default: 5s)")
+	fmt.Println("  -tag-prefix <prefix>   Only consider tags starting with prefix (e.g. v, release/)")
+	fmt.Println("  -tag-exclude <pattern> Glob or /regexp/ of tags to ignore, e.g. *-rc* (repeatable)")
+	fmt.Println("  -strip-tag-prefix      Remove the tag prefix from the emitted version")
+	fmt.Println("  -component-path <dir>  Version only the given subdirectory of a monorepo")
+	fmt.Println("  -component <name>      Version only the named component of the config file")
+	fmt.Println("  -ref <rev>             Compute the version for a branch, tag or commit instead of HEAD")
+	fmt.Println("  -mode <strategy>       Versioning strategy: githubflow (default), gitflow, trunk, height")
+	fmt.Println("  -scheme <scheme>       Version scheme: semver (default), calver, mainline, snapshot")
+	fmt.Println("  -calver-format <fmt>   CalVer format for -scheme calver (default: YYYY.MM.MICRO)")
+	fmt.Println("  -mainline-base <x.y>   MAJOR.MINOR of -scheme mainline before the first tag (default: 0.1)")
+	fmt.Println("  -first-parent          Follow only first parents when searching tags")
+	fmt.Println("  -max-describe-depth <n> Stop searching for tags after n commits (fall back to <branch-slug>-g<hash>)")
+	fmt.Println("  -fetch-tags            Fetch all tags from origin first (auth: GITVERSION_TOKEN or ssh-agent)")
+	fmt.Println("  -check-remote-tags     Fail with status 5 if origin has tags missing locally or moved ones")
+	fmt.Println("  -monotonic             Fail with status 6 if the version (of tag and release: the new one) is not higher than every release tag")
+	fmt.Println("  -deepen                Fetch more history of a shallow clone until a tag is reachable")
+	fmt.Println("  -verify-signatures <f> Verify HEAD and latest tag signatures (OpenPGP keyring or SSH allowed_signers)")
+	fmt.Println("  -cache                 Cache the repository state between invocations (dir: -cache-dir <dir>)")
+	fmt.Println("  -profile               Report the time spent in each phase on stderr")
+	fmt.Println("  -v, -vv                Trace repository discovery, default branch, tag choice and dirty check on stderr")
+	fmt.Println("  -cpuprofile <file>     Write a pprof CPU profile (also -memprofile <file>)")
+	fmt.Println("  -debug-refs            List the refs seen (loose, packed, remote) and why each tag is used or ignored")
+	fmt.Println("  -version               Print the version of gitversion itself (also: gitversion version)")
+	fmt.Println("  -abbrev <n|auto|no>    Length of abbreviated commit hashes (default: core.abbrev or 7)")
+	fmt.Println("  -metadata              Emit SemVer build metadata (+g<sha>.<branch-slug>) instead of -g<sha>")
+	fmt.Println("  -metadata-format <tpl> Go template for the build metadata (implies -metadata)")
+	fmt.Println("  -superproject          Include the superproject version info in submodules")
+	fmt.Println("  -recurse-submodules    Include the version info of all submodules, recursively")
+	fmt.Println("  -backend <name>        Repository backend: go-git (default), git-cli")
+	fmt.Println("  -no-ci                 Do not read the branch, tag and default branch from CI environment variables")
+	fmt.Println("  -no-dirty-check        Skip the check for uncommitted changes")
+	fmt.Println("  -fail-on-dirty         Exit with status 4 if there are uncommitted changes")
+	fmt.Println("  -dirty-exclude <path>  Path or glob to ignore in the dirty check (repeatable)")
+	fmt.Println("  -dirty-untracked       Also treat untracked, non-ignored files as uncommitted changes")
+	fmt.Println("  -dirty-format <fmt>    Suffix of dirty versions: timestamp (default), hash or a template (e.g. +dirty)")
+	fmt.Println("  -dirty-suffix <kind>   Deprecated alias of -dirty-format timestamp or hash")
+	fmt.Println("  -error-format <fmt>    Format of errors on stderr: text (default) or json (error, reason, exitCode)")
+	fmt.Println()
+	fmt.Println("EXIT STATUS:")
+	fmt.Println("  0 success, 1 error, 2 no repository, 3 no commits, 4 dirty with -fail-on-dirty, 5 stale tags,")
+	fmt.Println("  6 version not above every release tag with -monotonic, 7 invalid flags or arguments,")
+	fmt.Println("  8 check, verify-tag or satisfies not passed, 9 bump required with compare -exit-code")
 	fmt.Println()
 	fmt.Println("VERSION LOGIC:")
 	fmt.Println("  - Default branch with tags:    Uses 'git describe' format (tag or tag-N-ghash)")
 	fmt.Println("  - Default branch without tags: Uses '<branch-slug>-ghash'")
 	fmt.Println("  - Other branches:              Always uses '<branch-slug>-ghash'")
-	fmt.Println("  - Dirty tree:                  Appends '-YYYYMMDDHHMMSS' timestamp")
+	fmt.Println("  - Release branches:            Configurable in the config file, e.g. 'v1.4.0-rc.N' on release/1.4")
+	fmt.Println("  - Branch templates:            Configurable in the config file, e.g. '{slug}-g{sha}' for feature/*")
+	fmt.Println("  - Pull request CI builds:      Uses 'pr-<number>-ghash' (pull-request-template in the config file)")
+	fmt.Println("  - Dirty tree:                  Appends '-YYYYMMDDHHMMSS' timestamp (see -dirty-format)")
+	fmt.Println("  - See README for the gitflow, trunk and height modes")
 	fmt.Println()
 	fmt.Println("EXAMPLES:")
 	fmt.Println("  gitversion                         # Print version")
 	fmt.Println("  gitversion -detailed               # Print detailed info")
+	fmt.Println("  gitversion -json                   # Print info as JSON")
+	fmt.Println("  gitversion -format '{{.GitBranchSlug}}-{{.GitCommitShort}}'")
+	fmt.Println("  eval \"$(gitversion -env)\"          # Set GITVERSION_* variables")
+	fmt.Println("  eval \"$(gitversion env -export)\"   # Export GITVERSION_* variables")
 	fmt.Println("  gitversion -path /repo             # Version for specific repo")
+	fmt.Println("  gitversion -path a -path b -json   # Report several repos")
+	fmt.Println("  gitversion -url https://github.com/org/repo -ref v1.2.3")
 	fmt.Println("  gitversion -default-branch master  # Specify default branch")
+	fmt.Println("  gitversion -tag-prefix release/    # Only use release/* tags")
+	fmt.Println("  gitversion -component-path svc/api # Version a monorepo component")
+	fmt.Println("  gitversion -mode gitflow           # Use GitFlow branch rules")
+	fmt.Println("  gitversion -scheme calver          # Use calendar versions")
+	fmt.Println("  gitversion next -bump minor        # Print next minor version")
+	fmt.Println("  gitversion next -auto              # Bump based on Conventional Commits")
+	fmt.Println("  gitversion tag -auto -push         # Tag and push the next release")
+	fmt.Println("  gitversion tag -prerelease rc      # Tag the next release candidate")
+	fmt.Println("  gitversion changelog -output CHANGELOG.md")
+	fmt.Println("  gitversion release -auto -push -dry-run")
+	fmt.Println("  gitversion inject package.json charts/app/Chart.yaml")
+	fmt.Println("  gitversion inject-helm -chart ./charts/app")
+	fmt.Println("  gitversion explain -ref v1.2.3")
+	fmt.Println("  gitversion check -stable v1.2.3")
+	fmt.Println("  gitversion verify-tag -auto")
+	fmt.Println("  gitversion satisfies \">=1.4 <2\" && ./deploy.sh")
+	fmt.Println("  gitversion compare -exit-code v1.2.3 HEAD")
+	fmt.Println("  gitversion tags -check-remote")
+	fmt.Println("  gitversion docker-tags -image ghcr.io/org/app")
+	fmt.Println("  goreleaser release $(gitversion goreleaser -flags)")
+	fmt.Println("  gitversion serve -addr :8080 -path /repos")
+	fmt.Println("  go build -ldflags \"$(gitversion ldflags -pkg main)\"")
+	fmt.Println("  gitversion generate -pkg internal/buildinfo")
 }
 
 func main() {
-	// Check for help command first
-	if len(os.Args) > 1 && os.Args[1] == "help" {
-		printHelp()
-		os.Exit(0)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "help":
+			printHelp()
+			os.Exit(0)
+		case "version":
+			printVersion()
+			os.Exit(0)
+		case "ldflags":
+			exitOnError(runLdflags(os.Args[2:]))
+			os.Exit(0)
+		case "generate":
+			exitOnError(runGenerate(os.Args[2:]))
+			os.Exit(0)
+		case "env":
+			exitOnError(runEnv(os.Args[2:]))
+			os.Exit(0)
+		case "next":
+			exitOnError(runNext(os.Args[2:]))
+			os.Exit(0)
+		case "tag":
+			exitOnError(runTag(os.Args[2:]))
+			os.Exit(0)
+		case "changelog":
+			exitOnError(runChangelog(os.Args[2:]))
+			os.Exit(0)
+		case "release":
+			exitOnError(runRelease(os.Args[2:]))
+			os.Exit(0)
+		case "inject":
+			exitOnError(runInject(os.Args[2:]))
+			os.Exit(0)
+		case "inject-helm":
+			exitOnError(runInjectHelm(os.Args[2:]))
+			os.Exit(0)
+		case "compare":
+			exitOnError(runCompare(os.Args[2:]))
+			os.Exit(0)
+		case "history":
+			exitOnError(runHistory(os.Args[2:]))
+			os.Exit(0)
+		case "tags":
+			exitOnError(runTags(os.Args[2:]))
+			os.Exit(0)
+		case "affected":
+			exitOnError(runAffected(os.Args[2:]))
+			os.Exit(0)
+		case "components":
+			exitOnError(runComponents(os.Args[2:]))
+			os.Exit(0)
+		case "explain":
+			exitOnError(runExplain(os.Args[2:]))
+			os.Exit(0)
+		case "check":
+			exitOnError(runCheck(os.Args[2:]))
+			os.Exit(0)
+		case "verify-tag":
+			exitOnError(runVerifyTag(os.Args[2:]))
+			os.Exit(0)
+		case "satisfies":
+			exitOnError(runSatisfies(os.Args[2:]))
+			os.Exit(0)
+		case "docker-tags":
+			exitOnError(runDockerTags(os.Args[2:]))
+			os.Exit(0)
+		case "goreleaser":
+			exitOnError(runGoreleaser(os.Args[2:]))
+			os.Exit(0)
+		case "serve":
+			exitOnError(runServe(os.Args[2:]))
+			os.Exit(0)
+		}
 	}
 
 	var (
-		detailedFlag      = flag.Bool("detailed", false, "Show detailed version information")
-		shortFlag         = flag.Bool("short", false, "Show only the version string")
-		pathFlag          = flag.String("path", ".", "Path to Git repository")
-		defaultBranchFlag = flag.String("default-branch", "", "Default branch name (auto-detected if not set)")
+		detailedFlag  = flag.Bool("detailed", false, "Show detailed version information")
+		shortFlag     = flag.Bool("short", false, "Show only the version string")
+		jsonFlag      = flag.Bool("json", false, "Show version information as JSON")
+		formatFlag    = flag.String("format", "", "Render output using a Go text/template, go-pseudo for a Go module pseudo-version, stamp for Bazel workspace status, sbom for a CycloneDX fragment or provenance for SLSA materials")
+		envFlag       = flag.Bool("env", false, "Show version information as dotenv/shell variables")
+		envPrefixFlag = flag.String("env-prefix", "GITVERSION_", "Variable name prefix for -env")
+		githubFlag    = flag.Bool("github-actions", false, "Write outputs to $GITHUB_OUTPUT and variables to $GITHUB_ENV")
+		gitlabFlag    = flag.Bool("gitlab", false, "Use GitLab CI variables and write a dotenv report")
+		gitlabEnvFlag = flag.String("gitlab-dotenv", defaultGitLabDotenv, "Dotenv report file written by -gitlab")
+		azureFlag     = flag.Bool("azure-devops", false, "Set Azure Pipelines variables and the build number with logging commands")
+		urlFlag       = flag.String("url", "", "Version the remote repository at the URL, cloned into memory (auth: GITVERSION_TOKEN or ssh-agent)")
+		pathsFileFlag = flag.String("paths-file", "", "File listing repository paths, one per line (- for stdin)")
+		cpuProfFlag   = flag.String("cpuprofile", "", "Write a pprof CPU profile to the file")
+		memProfFlag   = flag.String("memprofile", "", "Write a pprof memory profile to the file")
+		debugRefsFlag = flag.Bool("debug-refs", false, "List the refs seen and how each was classified, then exit")
+		versionFlag   = flag.Bool("version", false, "Print the version of gitversion itself")
 	)
 
+	vf := addVersionFlags(flag.CommandLine)
+
 	flag.Usage = printHelp
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		exitOnError(flagError(err))
+	}
 
-	flag.Parse()
+	if *versionFlag {
+		printVersion()
+		os.Exit(0)
+	}
 
-	info, err := version.GetVersionInfo(*pathFlag, *defaultBranchFlag)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if *urlFlag == "" && *pathsFileFlag == "" {
+		exitOnError(vf.applyGitConfig())
+	}
+
+	stopPprof, err := startPprof(*cpuProfFlag, *memProfFlag)
+	exitOnError(err)
+
+	if *urlFlag != "" && (len(vf.paths.all) > 0 || *pathsFileFlag != "" || *debugRefsFlag) {
+		exitOnError(usageError(errors.New("-url cannot be combined with -path, -paths-file or -debug-refs")))
+	}
+
+	if *debugRefsFlag {
+		exitOnError(errors.Join(printDebugRefs(vf), stopPprof()))
+		os.Exit(0)
+	}
+
+	// Several repositories are reported together
+	if len(vf.paths.all) > 1 || *pathsFileFlag != "" {
+		paths := vf.paths.all
+		if *pathsFileFlag != "" {
+			filePaths, err := readPaths(*pathsFileFlag)
+			exitOnError(err)
+			paths = append(paths, filePaths...)
+		}
+		if *detailedFlag || *envFlag || *githubFlag || *gitlabFlag || *azureFlag || *formatFlag == "stamp" || *formatFlag == "sbom" || *formatFlag == "provenance" {
+			exitOnError(usageError(errors.New("-detailed, -env, -github-actions, -gitlab, -azure-devops and -format stamp|sbom|provenance support a single repository only")))
+		}
+		err := runMulti(vf, paths, *jsonFlag, *formatFlag)
+		exitOnError(errors.Join(stopPprof(), err))
+		os.Exit(0)
+	}
+
+	var info *version.Info
+	if *urlFlag != "" {
+		info, err = vf.getVersionInfoFromURL(*urlFlag)
+	} else {
+		info, err = vf.getVersionInfo()
+	}
+	exitOnError(errors.Join(stopPprof(), err))
+
+	if *githubFlag {
+		exitOnError(writeGitHubActions(info, *envPrefixFlag))
+	}
+	if *gitlabFlag {
+		exitOnError(writeGitLabDotenv(info, *envPrefixFlag, *gitlabEnvFlag))
+	}
+	if *azureFlag {
+		exitOnError(writeAzureDevOps(os.Stdout, info, *envPrefixFlag))
 	}
 
 	if *shortFlag {
 		fmt.Println(info.Version)
+	} else if *formatFlag == "go-pseudo" {
+		out, err := info.GoPseudoVersion()
+		exitOnError(err)
+		fmt.Println(out)
+	} else if *formatFlag == "stamp" {
+		out, err := info.Stamp()
+		exitOnError(err)
+		fmt.Print(out)
+	} else if *formatFlag == "sbom" {
+		name := strings.TrimSuffix(path.Base(*urlFlag), ".git")
+		if *urlFlag == "" {
+			abs, err := filepath.Abs(*vf.path)
+			exitOnError(err)
+			name = filepath.Base(abs)
+		}
+		out, err := info.CycloneDX(name)
+		exitOnError(err)
+		fmt.Println(string(out))
+	} else if *formatFlag == "provenance" {
+		out, err := info.Provenance()
+		exitOnError(err)
+		fmt.Println(string(out))
+	} else if *formatFlag != "" {
+		out, err := info.Format(*formatFlag)
+		exitOnError(err)
+		fmt.Println(out)
+	} else if *envFlag {
+		fmt.Print(info.Env(*envPrefixFlag))
+	} else if *jsonFlag {
+		data, err := json.MarshalIndent(info, "", "  ")
+		exitOnError(err)
+		fmt.Println(string(data))
 	} else if *detailedFlag {
 		fmt.Println(info.DetailedString())
 	} else {
 		fmt.Println(info.Version)
 	}
 }
+
+// Exit statuses of all commands. Commands checking a condition report a
+// negative result with a status of its own, so that it cannot be mistaken for
+// a failure to check it.
+const (
+	exitFailure      = 1
+	exitNoRepository = 2
+	exitNoCommits    = 3
+	exitDirty        = 4
+	exitStaleTags    = 5
+	exitNotMonotonic = 6
+	exitUsage        = 7
+	// exitCheckFailed is the status of check, verify-tag and satisfies if the
+	// version or a tag does not pass
+	exitCheckFailed = 8
+	// exitBumpRequired is the status of compare -exit-code if a bump is
+	// required
+	exitBumpRequired = 9
+)
+
+// errorFormat is the format errors are printed in, set by -error-format
+var errorFormat errorFormatValue = "text"
+
+// exitError is an error with a specific exit status and the reason reported
+// by -error-format json
+type exitError struct {
+	code   int
+	reason string
+	err    error
+	// printed is set if the error was already printed, as flag errors are
+	// with the usage
+	printed bool
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// usageError returns an exitError with exitUsage for invalid flags or
+// arguments
+func usageError(err error) error {
+	return &exitError{code: exitUsage, reason: "usage", err: err}
+}
+
+// flagError returns the usage error of a flag set failing to parse, which has
+// printed the error and the usage already
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return &exitError{code: exitUsage, reason: "usage", err: err, printed: true}
+}
+
+// exitStatus returns the exit status and reason of the error: those of an
+// exitError, else of a known failure, else exitFailure
+func exitStatus(err error) (int, string) {
+	var exitErr *exitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.reason != "":
+		return exitErr.code, exitErr.reason
+	case errors.As(err, &exitErr):
+		return exitErr.code, "error"
+	case errors.Is(err, version.ErrNoRepository):
+		return exitNoRepository, "no_repository"
+	case errors.Is(err, version.ErrNoCommits):
+		return exitNoCommits, "no_commits"
+	case errors.Is(err, version.ErrStaleTags):
+		return exitStaleTags, "stale_tags"
+	case errors.Is(err, version.ErrNotMonotonic):
+		return exitNotMonotonic, "not_monotonic"
+	}
+	return exitFailure, "error"
+}
+
+// exitOnError prints the error to stderr and exits if err is not nil, with
+// the status of exitStatus
+func exitOnError(err error) {
+	if errors.Is(err, flag.ErrHelp) {
+		// -h printed the usage
+		os.Exit(0)
+	}
+	if err != nil {
+		code, reason := exitStatus(err)
+		var exitErr *exitError
+		if errorFormat == "json" {
+			data, _ := json.Marshal(struct {
+				Error    string `json:"error"`
+				Reason   string `json:"reason"`
+				ExitCode int    `json:"exitCode"`
+			}{err.Error(), reason, code})
+			fmt.Fprintln(os.Stderr, string(data))
+		} else if !errors.As(err, &exitErr) || !exitErr.printed {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(code)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fxsml/gitversion/pkg/config"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+func TestInvalidFlagsExitUsage(t *testing.T) {
+	tests := [][]string{
+		{"-mode", "bogus"},
+		{"-scheme", "x"},
+		{"-scheme", "calver", "-calver-format", "bogus"},
+		{"-scheme", "mainline", "-mainline-base", "x"},
+		{"-abbrev", "foo"},
+		{"-backend", "svn"},
+		{"-dirty-format", "-{nope}"},
+		{"-fail-on-dirty", "-no-dirty-check"},
+		{"-component", "a", "-component-path", "b"},
+		{"-path", "a", "-path", "b"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			vf := addVersionFlags(fs)
+			if err := fs.Parse(append([]string{"-path", t.TempDir()}, args...)); err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			_, err := vf.options()
+			if err == nil {
+				t.Fatalf("options(%v) succeeded", args)
+			}
+			if code, reason := exitStatus(err); code != exitUsage || reason != "usage" {
+				t.Errorf("exit status of %v = %d %q, want %d \"usage\"", err, code, reason, exitUsage)
+			}
+		})
+	}
+}
+
+func TestInvalidBumpExitUsage(t *testing.T) {
+	fs := flag.NewFlagSet("next", flag.ContinueOnError)
+	bf := addBumpFlags(fs)
+	if err := fs.Parse([]string{"-bump", "huge"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	_, err := nextVersion(t.TempDir(), &version.Info{}, bf)
+	if err == nil {
+		t.Fatal("nextVersion succeeded with -bump huge")
+	}
+	if code, reason := exitStatus(err); code != exitUsage || reason != "usage" {
+		t.Errorf("exit status of %v = %d %q, want %d \"usage\"", err, code, reason, exitUsage)
+	}
+}
+
+func TestDirtySuffixAlias(t *testing.T) {
+	tests := map[string]string{
+		"timestamp": version.DirtyFormatTimestamp,
+		"hash":      version.DirtyFormatHash,
+	}
+	for suffix, expected := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		vf := addVersionFlags(fs)
+		if err := fs.Parse([]string{"-dirty-suffix", suffix}); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if *vf.dirtyFormat != expected {
+			t.Errorf("-dirty-suffix %s sets -dirty-format %q, want %q", suffix, *vf.dirtyFormat, expected)
+		}
+		if _, err := version.ParseDirtyFormat(*vf.dirtyFormat); err != nil {
+			t.Errorf("ParseDirtyFormat failed for -dirty-suffix %s: %v", suffix, err)
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addVersionFlags(fs)
+	if err := fs.Parse([]string{"-dirty-suffix", "-dirty"}); err == nil {
+		t.Error("-dirty-suffix accepted a template")
+	}
+}
+
+func TestChangedDependencies(t *testing.T) {
+	components := []config.Component{
+		{Name: "core", Path: "libs/core"},
+		{Name: "db", Path: "libs/db", DependsOn: []string{"core"}},
+		{Name: "api", Path: "services/api", DependsOn: []string{"db"}},
+	}
+	// core changed since the tag of db but not since the tag of api
+	changedFor := map[string]bool{"db/core": true}
+	deps, err := changedDependencies(components, func(c, dep config.Component) (bool, error) {
+		return changedFor[c.Name+"/"+dep.Name], nil
+	})
+	if err != nil {
+		t.Fatalf("changedDependencies failed: %v", err)
+	}
+	if got := strings.Join(deps["db"], ","); got != "core" {
+		t.Errorf("changed dependencies of db = %q, want core", got)
+	}
+	if got := deps["api"]; len(got) != 0 {
+		t.Errorf("changed dependencies of api = %v, want none", got)
+	}
+	if got := deps["core"]; len(got) != 0 {
+		t.Errorf("changed dependencies of core = %v, want none", got)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// repoReport is the version info or the error of one repository in a
+// report of several repositories
+type repoReport struct {
+	Path  string        `json:"path"`
+	Info  *version.Info `json:"info,omitempty"`
+	Error string        `json:"error,omitempty"`
+
+	err error
+}
+
+// readPaths reads repository paths from the file, one per line, skipping
+// blank lines and # comments. "-" reads from stdin.
+func readPaths(name string) ([]string, error) {
+	r := io.Reader(os.Stdin)
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read paths: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read paths: %w", err)
+	}
+	return paths, nil
+}
+
+// reportRepos computes the version info of the repositories in parallel and
+// returns the reports in the order of the paths
+func reportRepos(vf *versionFlags, paths []string) []repoReport {
+	reports := make([]repoReport, len(paths))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			reports[i] = repoReport{Path: path}
+			reports[i].Info, reports[i].err = vf.getVersionInfoAt(path)
+			if reports[i].err != nil {
+				reports[i].Info = nil
+				reports[i].Error = reports[i].err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	return reports
+}
+
+// runMulti prints the version info of several repositories as a table, as
+// JSON array or rendered with the format, one line per repository. Failed
+// repositories are part of the report; the returned error counts them apart
+// from those with uncommitted changes for -fail-on-dirty, and has exitDirty
+// if no repository failed otherwise.
+func runMulti(vf *versionFlags, paths []string, jsonOutput bool, format string) error {
+	reports := reportRepos(vf, paths)
+
+	switch {
+	case jsonOutput:
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case format != "":
+		for _, r := range reports {
+			if r.err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", r.Path, r.err)
+				continue
+			}
+			var out string
+			var err error
+			if format == "go-pseudo" {
+				out, err = r.Info.GoPseudoVersion()
+			} else {
+				out, err = r.Info.Format(format)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", r.Path, err)
+			}
+			fmt.Println(out)
+		}
+	default:
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PATH\tVERSION\tBRANCH\tCOMMIT\tDIRTY")
+		for _, r := range reports {
+			if r.err != nil {
+				fmt.Fprintf(tw, "%s\terror: %s\t\t\t\n", r.Path, r.Error)
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\n", r.Path, r.Info.Version, r.Info.GitBranch, r.Info.GitCommitShort, r.Info.IsDirty)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	failed, dirty := 0, 0
+	for _, r := range reports {
+		var exitErr *exitError
+		if errors.As(r.err, &exitErr) && exitErr.code == exitDirty {
+			dirty++
+		} else if r.err != nil {
+			failed++
+		}
+	}
+	switch {
+	case failed > 0 && dirty > 0:
+		return fmt.Errorf("%d of %d repositories failed, %d have uncommitted changes", failed, len(reports), dirty)
+	case failed > 0:
+		return fmt.Errorf("%d of %d repositories failed", failed, len(reports))
+	case dirty > 0:
+		return &exitError{code: exitDirty, reason: "dirty", err: fmt.Errorf("%d of %d repositories have uncommitted changes", dirty, len(reports))}
+	}
+	return nil
+}
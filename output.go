@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// writeGitHubActions writes the version info as step outputs to $GITHUB_OUTPUT
+// and as prefixed environment variables to $GITHUB_ENV
+func writeGitHubActions(info *version.Info, envPrefix string) error {
+	outputFile := os.Getenv("GITHUB_OUTPUT")
+	envFile := os.Getenv("GITHUB_ENV")
+	if outputFile == "" && envFile == "" {
+		return fmt.Errorf("GITHUB_OUTPUT and GITHUB_ENV are not set: not running in GitHub Actions?")
+	}
+
+	// Values are written in the multiline syntax, NAME<<DELIMITER, the value
+	// and DELIMITER on lines of their own, with a random delimiter, so that a
+	// value containing a newline cannot set further variables
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("failed to generate a delimiter: %w", err)
+	}
+	delimiter := "ghadelimiter_" + hex.EncodeToString(b)
+
+	if outputFile != "" {
+		var sb strings.Builder
+		for _, f := range info.Fields() {
+			fmt.Fprintf(&sb, "%s<<%s\n%s\n%s\n", f.Name, delimiter, f.Value, delimiter)
+		}
+		if err := appendToFile(outputFile, sb.String()); err != nil {
+			return err
+		}
+	}
+
+	if envFile != "" {
+		var sb strings.Builder
+		for _, f := range info.Fields() {
+			fmt.Fprintf(&sb, "%s%s<<%s\n%s\n%s\n", envPrefix, strings.ToUpper(f.Name), delimiter, f.Value, delimiter)
+		}
+		if err := appendToFile(envFile, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendToFile appends content to the file, creating it if necessary
+func appendToFile(path string, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
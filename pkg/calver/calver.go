@@ -0,0 +1,137 @@
+package calver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFormat is the format used if none is given
+const DefaultFormat = "YYYY.MM.MICRO"
+
+// tokens are the supported format segments, longest first so that e.g.
+// YYYY is not matched as YY
+var tokens = []string{"YYYY", "MICRO", "YY", "0Y", "MM", "0M", "WW", "0W", "DD", "0D"}
+
+// Format is a parsed CalVer format such as YYYY.MM.MICRO
+type Format struct {
+	layout string
+	parts  []string
+}
+
+// ParseFormat parses a CalVer format. Segments are YYYY, YY, 0Y, MM, 0M, WW,
+// 0W, DD, 0D and MICRO separated by ".", "-" or "_". MICRO is a counter that
+// is incremented for every release within the same period.
+func ParseFormat(layout string) (*Format, error) {
+	f := &Format{layout: layout}
+	hasDate := false
+	for rest := layout; rest != ""; {
+		if strings.ContainsRune(".-_", rune(rest[0])) {
+			f.parts = append(f.parts, rest[:1])
+			rest = rest[1:]
+			continue
+		}
+		token := ""
+		for _, t := range tokens {
+			if strings.HasPrefix(rest, t) {
+				token = t
+				break
+			}
+		}
+		if token == "" {
+			return nil, fmt.Errorf("invalid calver format %q: unknown segment at %q", layout, rest)
+		}
+		if token != "MICRO" {
+			hasDate = true
+		}
+		f.parts = append(f.parts, token)
+		rest = rest[len(token):]
+	}
+	if !hasDate {
+		return nil, fmt.Errorf("invalid calver format %q: no date segment", layout)
+	}
+	return f, nil
+}
+
+// String returns the format layout
+func (f *Format) String() string {
+	return f.layout
+}
+
+// Version renders the version for the given date and micro counter
+func (f *Format) Version(t time.Time, micro uint64) string {
+	var b strings.Builder
+	for _, p := range f.parts {
+		if p == "MICRO" {
+			b.WriteString(strconv.FormatUint(micro, 10))
+			continue
+		}
+		b.WriteString(datePart(p, t))
+	}
+	return b.String()
+}
+
+// Micro returns the micro counter of version if it was released in the same
+// period as t according to the format. Formats without MICRO report 0.
+func (f *Format) Micro(version string, t time.Time) (uint64, bool) {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for _, p := range f.parts {
+		if p == "MICRO" {
+			pattern.WriteString(`(\d+)`)
+			continue
+		}
+		pattern.WriteString(regexp.QuoteMeta(datePart(p, t)))
+	}
+	pattern.WriteString("$")
+
+	match := regexp.MustCompile(pattern.String()).FindStringSubmatch(version)
+	if match == nil {
+		return 0, false
+	}
+	if len(match) < 2 {
+		return 0, true
+	}
+	micro, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return micro, true
+}
+
+// Next returns the version following latest for a release at t: the micro
+// counter is incremented if latest is in the same period and reset otherwise
+func (f *Format) Next(latest string, t time.Time) string {
+	if micro, ok := f.Micro(latest, t); ok {
+		return f.Version(t, micro+1)
+	}
+	return f.Version(t, 0)
+}
+
+// datePart renders a single date segment
+func datePart(token string, t time.Time) string {
+	_, week := t.ISOWeek()
+	switch token {
+	case "YYYY":
+		return strconv.Itoa(t.Year())
+	case "YY":
+		return strconv.Itoa(t.Year() % 100)
+	case "0Y":
+		return fmt.Sprintf("%02d", t.Year()%100)
+	case "MM":
+		return strconv.Itoa(int(t.Month()))
+	case "0M":
+		return fmt.Sprintf("%02d", int(t.Month()))
+	case "WW":
+		return strconv.Itoa(week)
+	case "0W":
+		return fmt.Sprintf("%02d", week)
+	case "DD":
+		return strconv.Itoa(t.Day())
+	case "0D":
+		return fmt.Sprintf("%02d", t.Day())
+	}
+	return token
+}
@@ -0,0 +1,87 @@
+package calver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		layout  string
+		wantErr bool
+	}{
+		{layout: "YYYY.MM.MICRO"},
+		{layout: "0Y.0M.0D"},
+		{layout: "YYYY-0W_MICRO"},
+		{layout: "YYYY.MM.PATCH", wantErr: true},
+		{layout: "YYYY/MM", wantErr: true},
+		{layout: "MICRO", wantErr: true},
+		{layout: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			_, err := ParseFormat(tt.layout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.layout, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	date := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		layout   string
+		micro    uint64
+		expected string
+	}{
+		{"YYYY.MM.MICRO", 2, "2026.3.2"},
+		{"YYYY.0M.0D", 0, "2026.03.05"},
+		{"YY.MM.DD", 0, "26.3.5"},
+		{"0Y.0W.MICRO", 1, "26.10.1"},
+		{"YYYY-MM_MICRO", 7, "2026-3_7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			f, err := ParseFormat(tt.layout)
+			if err != nil {
+				t.Fatalf("ParseFormat failed: %v", err)
+			}
+			if got := f.Version(date, tt.micro); got != tt.expected {
+				t.Errorf("Version() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatNext(t *testing.T) {
+	date := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		layout   string
+		latest   string
+		expected string
+	}{
+		{"no previous release", "YYYY.MM.MICRO", "", "2026.3.0"},
+		{"same period", "YYYY.MM.MICRO", "2026.3.4", "2026.3.5"},
+		{"previous period", "YYYY.MM.MICRO", "2026.2.4", "2026.3.0"},
+		{"non calver tag", "YYYY.MM.MICRO", "v1.2.3", "2026.3.0"},
+		{"without micro", "YYYY.0M.0D", "2026.03.04", "2026.03.05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFormat(tt.layout)
+			if err != nil {
+				t.Fatalf("ParseFormat failed: %v", err)
+			}
+			if got := f.Next(tt.latest, date); got != tt.expected {
+				t.Errorf("Next(%q) = %q, want %q", tt.latest, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/conventional"
+)
+
+// DefaultTemplate renders a changelog section as Markdown
+const DefaultTemplate = `## {{.Version}} ({{.Date}})
+{{range .Sections}}
+### {{.Title}}
+
+{{range .Entries}}- {{if .Scope}}**{{.Scope}}:** {{end}}{{.Description}} ({{if .URL}}[{{.ShortHash}}]({{.URL}}){{else}}{{.ShortHash}}{{end}})
+{{end}}{{end}}`
+
+// sectionOrder defines the section titles and their order; commits whose
+// type is not listed end up in "Other Changes"
+var sectionOrder = []struct {
+	title string
+	types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance Improvements", []string{"perf"}},
+	{"Reverts", []string{"revert"}},
+}
+
+// Changelog is the data a changelog template is rendered with
+type Changelog struct {
+	Version  string
+	Previous string
+	Date     string
+	URL      string
+	Sections []Section
+}
+
+// Section is a group of changelog entries, e.g. "Features"
+type Section struct {
+	Title   string
+	Entries []Entry
+}
+
+// Entry is a single commit in the changelog
+type Entry struct {
+	Hash        string
+	ShortHash   string
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+	URL         string
+}
+
+// Options configures changelog generation
+type Options struct {
+	// Version is the heading of the changelog section, e.g. the next version
+	Version string
+	// Since is the tag the changelog starts after; empty means all history
+	Since string
+	// RemoteURL is used to build commit links (ssh and https forms supported)
+	RemoteURL string
+}
+
+// Generate collects the commits reachable from head but not from opts.Since
+// and groups them by Conventional Commit type
+func Generate(repo *git.Repository, head plumbing.Hash, opts Options) (*Changelog, error) {
+	result, err := conventional.Analyze(repo, head, opts.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	cl := &Changelog{
+		Version:  opts.Version,
+		Previous: opts.Since,
+		Date:     time.Now().UTC().Format("2006-01-02"),
+		URL:      WebURL(opts.RemoteURL),
+	}
+	if cl.Version == "" {
+		cl.Version = "Unreleased"
+	}
+
+	breaking := Section{Title: "Breaking Changes"}
+	sections := make([]Section, len(sectionOrder))
+	other := Section{Title: "Other Changes"}
+	for i, s := range sectionOrder {
+		sections[i].Title = s.title
+	}
+
+	for _, c := range result.Commits {
+		entry := Entry{
+			Hash:        c.Hash.String(),
+			ShortHash:   c.Hash.String()[:7],
+			Type:        c.Type,
+			Scope:       c.Scope,
+			Description: c.Description,
+			Breaking:    c.Breaking,
+		}
+		if cl.URL != "" {
+			entry.URL = cl.URL + "/commit/" + entry.Hash
+		}
+
+		if c.Breaking {
+			breaking.Entries = append(breaking.Entries, entry)
+		}
+		if idx := sectionIndex(c.Type); idx >= 0 {
+			sections[idx].Entries = append(sections[idx].Entries, entry)
+		} else if !c.Breaking {
+			other.Entries = append(other.Entries, entry)
+		}
+	}
+
+	for _, s := range append(append([]Section{breaking}, sections...), other) {
+		if len(s.Entries) > 0 {
+			cl.Sections = append(cl.Sections, s)
+		}
+	}
+	return cl, nil
+}
+
+// sectionIndex returns the index of the section for a commit type or -1
+func sectionIndex(commitType string) int {
+	for i, s := range sectionOrder {
+		for _, t := range s.types {
+			if t == commitType {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Render renders the changelog with the given Go text/template;
+// an empty template uses DefaultTemplate
+func (c *Changelog) Render(format string) (string, error) {
+	if format == "" {
+		format = DefaultTemplate
+	}
+	tmpl, err := template.New("changelog").Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse changelog template: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, c); err != nil {
+		return "", fmt.Errorf("failed to render changelog template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// Prepend inserts the rendered section at the top of the changelog file,
+// below a leading "# " title if present. The file is created if missing.
+func Prepend(path string, section string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	section = strings.TrimRight(section, "\n") + "\n"
+	content := string(existing)
+
+	var out string
+	switch {
+	case content == "":
+		out = "# Changelog\n\n" + section
+	case strings.HasPrefix(content, "# "):
+		title, rest, _ := strings.Cut(content, "\n")
+		out = title + "\n\n" + section + "\n" + strings.TrimLeft(rest, "\n")
+	default:
+		out = section + "\n" + content
+	}
+
+	if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// WebURL converts a remote URL (https, ssh or scp-like) into the repository
+// web URL, e.g. git@github.com:org/repo.git becomes https://github.com/org/repo.
+// Returns an empty string for local paths and unrecognized URLs.
+func WebURL(remoteURL string) string {
+	u := strings.TrimSpace(remoteURL)
+	u = strings.TrimSuffix(u, "/")
+	u = strings.TrimSuffix(u, ".git")
+
+	switch {
+	case strings.HasPrefix(u, "https://"), strings.HasPrefix(u, "http://"):
+		// Drop credentials, e.g. https://token@github.com/org/repo
+		scheme, rest, _ := strings.Cut(u, "://")
+		if at := strings.Index(rest, "@"); at >= 0 && at < strings.Index(rest+"/", "/") {
+			rest = rest[at+1:]
+		}
+		return scheme + "://" + rest
+	case strings.HasPrefix(u, "ssh://"):
+		rest := strings.TrimPrefix(u, "ssh://")
+		if at := strings.Index(rest, "@"); at >= 0 {
+			rest = rest[at+1:]
+		}
+		host, path, ok := strings.Cut(rest, "/")
+		if !ok {
+			return ""
+		}
+		// Drop the port, which belongs to the ssh service
+		host, _, _ = strings.Cut(host, ":")
+		return "https://" + host + "/" + path
+	case strings.Contains(u, "@") && strings.Contains(u, ":"):
+		// scp-like syntax: git@github.com:org/repo
+		rest := u[strings.Index(u, "@")+1:]
+		host, path, _ := strings.Cut(rest, ":")
+		return "https://" + host + "/" + strings.TrimPrefix(path, "/")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,192 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestWebURL(t *testing.T) {
+	tests := []struct {
+		remote   string
+		expected string
+	}{
+		{"https://github.com/org/repo.git", "https://github.com/org/repo"},
+		{"https://github.com/org/repo", "https://github.com/org/repo"},
+		{"https://token@github.com/org/repo.git", "https://github.com/org/repo"},
+		{"git@github.com:org/repo.git", "https://github.com/org/repo"},
+		{"ssh://git@gitlab.com:2222/group/sub/repo.git", "https://gitlab.com/group/sub/repo"},
+		{"/srv/git/repo.git", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			if result := WebURL(tt.remote); result != tt.expected {
+				t.Errorf("WebURL(%q) = %q, want %q", tt.remote, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gitversion-test-changelog-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	tagged := commit(t, repo, tempDir, "feat: initial feature")
+	if _, err := repo.CreateTag("v1.0.0", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	fix := commit(t, repo, tempDir, "fix(parser): handle empty input")
+	commit(t, repo, tempDir, "chore: update deps")
+	head := commit(t, repo, tempDir, "feat!: new output format")
+
+	cl, err := Generate(repo, head, Options{
+		Version:   "v2.0.0",
+		Since:     "v1.0.0",
+		RemoteURL: "git@github.com:org/repo.git",
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	var titles []string
+	for _, s := range cl.Sections {
+		titles = append(titles, s.Title)
+	}
+	expectedTitles := "Breaking Changes,Features,Bug Fixes,Other Changes"
+	if strings.Join(titles, ",") != expectedTitles {
+		t.Errorf("Sections = %v, want %s", titles, expectedTitles)
+	}
+
+	out, err := cl.Render("")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.HasPrefix(out, "## v2.0.0 (") {
+		t.Errorf("Render() should start with version heading, got:\n%s", out)
+	}
+	fixLine := "- **parser:** handle empty input ([" + fix.String()[:7] + "](https://github.com/org/repo/commit/" + fix.String() + "))"
+	if !strings.Contains(out, fixLine) {
+		t.Errorf("Render() should contain %q, got:\n%s", fixLine, out)
+	}
+	if strings.Contains(out, "initial feature") {
+		t.Errorf("Render() should not contain commits before the tag, got:\n%s", out)
+	}
+
+	custom, err := cl.Render("{{range .Sections}}{{.Title}}:{{len .Entries}};{{end}}")
+	if err != nil {
+		t.Fatalf("Render with custom template failed: %v", err)
+	}
+	if custom != "Breaking Changes:1;Features:1;Bug Fixes:1;Other Changes:1;" {
+		t.Errorf("Render(custom) = %q", custom)
+	}
+}
+
+func TestGenerateMergedBranch(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	// A branch forked before v1.0.0 and merged after it:
+	//
+	//	init - old feature - release (v1.0.0) - merge
+	//	            \                          /
+	//	             fix: branch fix ----------
+	commit(t, repo, tempDir, "feat: init")
+	old := commit(t, repo, tempDir, "feat: old feature")
+	release := commit(t, repo, tempDir, "chore: release")
+	if _, err := repo.CreateTag("v1.0.0", release, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	sig := &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+	fix, err := w.Commit("fix: branch fix", &git.CommitOptions{Author: sig, Parents: []plumbing.Hash{old}, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	head, err := w.Commit("Merge branch 'fix'", &git.CommitOptions{Author: sig, Parents: []plumbing.Hash{release, fix}, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatalf("Failed to commit merge: %v", err)
+	}
+
+	cl, err := Generate(repo, head, Options{Since: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	out, err := cl.Render("")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(out, "- branch fix (") {
+		t.Errorf("changelog lacks the merged fix:\n%s", out)
+	}
+	for _, released := range []string{"init", "old feature", "release"} {
+		if strings.Contains(out, "- "+released+" (") {
+			t.Errorf("changelog lists %q released in v1.0.0:\n%s", released, out)
+		}
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "CHANGELOG.md")
+
+	if err := Prepend(path, "## v1.0.0\n\n- first\n"); err != nil {
+		t.Fatalf("Prepend to missing file failed: %v", err)
+	}
+	if err := Prepend(path, "## v1.1.0\n\n- second\n"); err != nil {
+		t.Fatalf("Prepend to existing file failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read changelog: %v", err)
+	}
+	expected := "# Changelog\n\n## v1.1.0\n\n- second\n\n## v1.0.0\n\n- first\n"
+	if string(data) != expected {
+		t.Errorf("Changelog = %q, want %q", string(data), expected)
+	}
+}
+
+// commit writes the message to a file and commits it
+func commit(t *testing.T, repo *git.Repository, dir, message string) plumbing.Hash {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte(message), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add("test.txt"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	hash, err := w.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the names of the config file in the repository root, in the
+// order they are looked up
+var FileNames = []string{".gitversion.yaml", "gitversion.yaml"}
+
+// Config is the content of a gitversion config file
+type Config struct {
+	// Branches maps branch patterns to version templates, e.g.
+	// {"feature/*": "{slug}-g{sha}"}
+	Branches BranchTemplates `yaml:"branches"`
+	// ReleaseBranches are the rules deriving versions from release branch names
+	ReleaseBranches []ReleaseBranch `yaml:"release-branches"`
+	// PrereleaseLabels are the rules versioning branches as prereleases of a
+	// label, e.g. {pattern: develop, label: beta}
+	PrereleaseLabels []PrereleaseLabel `yaml:"prerelease-labels"`
+	// PullRequestTemplate is the version template of pull request builds,
+	// e.g. pr-{pr}-g{sha}
+	PullRequestTemplate string `yaml:"pull-request-template"`
+	// Hooks are the commands that rewrite the computed version, in order
+	Hooks []Hook `yaml:"hooks"`
+	// Components are the components of a monorepo
+	Components []Component `yaml:"components"`
+	// TagTemplate is the tag format of the components, e.g.
+	// {component}/v{version}; by default tags are named <path>/<version>
+	TagTemplate string `yaml:"tag-template"`
+	// SharedPaths are the paths whose changes count in the dirty check of
+	// every component, e.g. go.mod
+	SharedPaths []string `yaml:"shared-paths"`
+	// Dir is the directory of the loaded config file, in which hooks run
+	Dir string `yaml:"-"`
+}
+
+// BranchTemplate is a version template for the branches matching a pattern
+type BranchTemplate struct {
+	Pattern  string
+	Template string
+}
+
+// BranchTemplates is a YAML mapping of branch patterns to version templates
+// that keeps the order of the mapping, in which the patterns are matched
+type BranchTemplates []BranchTemplate
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (b *BranchTemplates) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: branches must map branch patterns to templates", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var t BranchTemplate
+		if err := node.Content[i].Decode(&t.Pattern); err != nil {
+			return err
+		}
+		if err := node.Content[i+1].Decode(&t.Template); err != nil {
+			return err
+		}
+		*b = append(*b, t)
+	}
+	return nil
+}
+
+// ReleaseBranch is a release branch rule, e.g. {pattern: release/*, label: rc}
+type ReleaseBranch struct {
+	Pattern string `yaml:"pattern"`
+	Label   string `yaml:"label"`
+}
+
+// PrereleaseLabel is a prerelease label rule, e.g.
+// {pattern: feature/*, label: alpha.{slug}, numbering: height}
+type PrereleaseLabel struct {
+	Pattern   string `yaml:"pattern"`
+	Label     string `yaml:"label"`
+	Numbering string `yaml:"numbering"`
+}
+
+// Component is a component of a monorepo, versioned like -component-path,
+// e.g. {name: api, path: services/api}
+type Component struct {
+	// Name identifies the component, by default the last element of Path
+	Name string `yaml:"name"`
+	// Path is the directory of the component relative to the repository root
+	Path string `yaml:"path"`
+	// DependsOn are the names of the components whose changes also change
+	// this component, e.g. a shared library
+	DependsOn []string `yaml:"depends-on"`
+	// TagTemplate overrides the TagTemplate of the config for this component
+	TagTemplate string `yaml:"tag-template"`
+}
+
+// Hook is a command that receives the version info as JSON on stdin and
+// prints the rewritten version, e.g. {command: [./scripts/version.sh]}
+type Hook struct {
+	Command []string `yaml:"command"`
+}
+
+// Load reads the config file at path. Unknown keys are rejected.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Dir = filepath.Dir(path)
+	return cfg, nil
+}
+
+// Parse parses the YAML config. An empty document yields an empty config.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	for _, t := range cfg.Branches {
+		if _, err := filepath.Match(t.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid config: branch pattern %q: %w", t.Pattern, err)
+		}
+		if t.Template == "" {
+			return nil, fmt.Errorf("invalid config: empty template for branch pattern %q", t.Pattern)
+		}
+	}
+	for _, rule := range cfg.ReleaseBranches {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("invalid config: release branch rule without pattern")
+		}
+		if _, err := filepath.Match(rule.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid config: release branch pattern %q: %w", rule.Pattern, err)
+		}
+	}
+	for _, rule := range cfg.PrereleaseLabels {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("invalid config: prerelease label rule without pattern")
+		}
+		if _, err := filepath.Match(rule.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid config: prerelease label pattern %q: %w", rule.Pattern, err)
+		}
+		if rule.Label == "" {
+			return nil, fmt.Errorf("invalid config: empty label for prerelease label pattern %q", rule.Pattern)
+		}
+		if rule.Numbering != "" && rule.Numbering != "tags" && rule.Numbering != "height" {
+			return nil, fmt.Errorf("invalid config: unknown numbering %q for prerelease label pattern %q: must be tags or height", rule.Numbering, rule.Pattern)
+		}
+	}
+	for i, hook := range cfg.Hooks {
+		if len(hook.Command) == 0 || hook.Command[0] == "" {
+			return nil, fmt.Errorf("invalid config: hook %d without command", i+1)
+		}
+	}
+	names := make(map[string]bool)
+	for i := range cfg.Components {
+		c := &cfg.Components[i]
+		if c.Path = path.Clean(strings.Trim(c.Path, "/")); c.Path == "." {
+			return nil, fmt.Errorf("invalid config: component %d without path", i+1)
+		}
+		if c.Name == "" {
+			c.Name = path.Base(c.Path)
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("invalid config: duplicate component %q", c.Name)
+		}
+		names[c.Name] = true
+	}
+	for _, c := range cfg.Components {
+		for _, dep := range c.DependsOn {
+			if dep == c.Name {
+				return nil, fmt.Errorf("invalid config: component %q depends on itself", c.Name)
+			}
+			if !names[dep] {
+				return nil, fmt.Errorf("invalid config: component %q depends on unknown component %q", c.Name, dep)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// Find returns the path of the config file in the root of the repository
+// containing dir, or "" if there is none
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Reached filesystem root
+			return "", nil
+		}
+		dir = parent
+	}
+
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+			return path, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(`
+branches:
+  hotfix/*: '{base}-hotfix.{height}'
+  feature/*: '{slug}-g{sha}'
+release-branches:
+  - pattern: release/*
+  - pattern: release-*
+    label: beta
+prerelease-labels:
+  - pattern: develop
+    label: beta
+    numbering: height
+  - pattern: feature/*
+    label: 'alpha.{slug}'
+pull-request-template: 'mr{pr}-{slug}'
+hooks:
+  - command: [./scripts/version.sh, --strict]
+components:
+  - path: services/api/
+  - name: core
+    path: libs/core
+    tag-template: 'core-v{version}'
+  - path: services/web
+    depends-on: [core]
+shared-paths: [go.mod, libs/common/]
+tag-template: '{component}/v{version}'
+
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	expected := []ReleaseBranch{{Pattern: "release/*"}, {Pattern: "release-*", Label: "beta"}}
+	if !reflect.DeepEqual(cfg.ReleaseBranches, expected) {
+		t.Errorf("ReleaseBranches = %+v, want %+v", cfg.ReleaseBranches, expected)
+	}
+
+	labels := []PrereleaseLabel{{Pattern: "develop", Label: "beta", Numbering: "height"}, {Pattern: "feature/*", Label: "alpha.{slug}"}}
+	if !reflect.DeepEqual(cfg.PrereleaseLabels, labels) {
+		t.Errorf("PrereleaseLabels = %+v, want %+v", cfg.PrereleaseLabels, labels)
+	}
+
+	templates := BranchTemplates{
+		{Pattern: "hotfix/*", Template: "{base}-hotfix.{height}"},
+		{Pattern: "feature/*", Template: "{slug}-g{sha}"},
+	}
+	if !reflect.DeepEqual(cfg.Branches, templates) {
+		t.Errorf("Branches = %+v, want %+v", cfg.Branches, templates)
+	}
+
+	if cfg.PullRequestTemplate != "mr{pr}-{slug}" {
+		t.Errorf("PullRequestTemplate = %q, want mr{pr}-{slug}", cfg.PullRequestTemplate)
+	}
+
+	hooks := []Hook{{Command: []string{"./scripts/version.sh", "--strict"}}}
+	if !reflect.DeepEqual(cfg.Hooks, hooks) {
+		t.Errorf("Hooks = %+v, want %+v", cfg.Hooks, hooks)
+	}
+
+	components := []Component{
+		{Name: "api", Path: "services/api"},
+		{Name: "core", Path: "libs/core", TagTemplate: "core-v{version}"},
+		{Name: "web", Path: "services/web", DependsOn: []string{"core"}},
+	}
+	if !reflect.DeepEqual(cfg.Components, components) {
+		t.Errorf("Components = %+v, want %+v", cfg.Components, components)
+	}
+
+	if !reflect.DeepEqual(cfg.SharedPaths, []string{"go.mod", "libs/common/"}) {
+		t.Errorf("SharedPaths = %v, want [go.mod libs/common/]", cfg.SharedPaths)
+	}
+
+	if cfg.TagTemplate != "{component}/v{version}" {
+		t.Errorf("TagTemplate = %q, want {component}/v{version}", cfg.TagTemplate)
+	}
+
+	if cfg, err := Parse(nil); err != nil || cfg.ReleaseBranches != nil {
+		t.Errorf("Parse(empty) = %+v, %v, want empty config", cfg, err)
+	}
+
+	for _, data := range []string{
+		"unknown: true",
+		"release-branches: [{label: rc}]",
+		"release-branches: [{pattern: '['}]",
+		"prerelease-labels: [{label: beta}]",
+		"prerelease-labels: [{pattern: '[', label: beta}]",
+		"prerelease-labels: [{pattern: develop}]",
+		"prerelease-labels: [{pattern: develop, label: beta, numbering: count}]",
+		"branches: [feature/*]",
+		"branches: {'[': x}",
+		"branches: {feature/*: ''}",
+		"hooks: [{command: []}]",
+		"hooks: [{command: ''}]",
+		"components: [{name: api}]",
+		"components: [{path: a/api}, {path: b/api}]",
+		"components: [{path: api, depends-on: [api]}]",
+		"components: [{path: api, depends-on: [core]}]",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) expected error", data)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := Find(sub)
+	if err != nil || path != "" {
+		t.Errorf("Find without config = %q, %v, want none", path, err)
+	}
+
+	expected := filepath.Join(root, "gitversion.yaml")
+	if err := os.WriteFile(expected, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := Find(sub); err != nil || path != expected {
+		t.Errorf("Find = %q, %v, want %q", path, err, expected)
+	}
+
+	// .gitversion.yaml takes precedence
+	expected = filepath.Join(root, ".gitversion.yaml")
+	if err := os.WriteFile(expected, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := Find(sub); err != nil || path != expected {
+		t.Errorf("Find = %q, %v, want %q", path, err, expected)
+	}
+}
@@ -0,0 +1,178 @@
+package conventional
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+
+	"github.com/fxsml/gitversion/pkg/revwalk"
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// headerPattern matches a Conventional Commits header: type(scope)!: description
+var headerPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+// Commit is a commit classified according to the Conventional Commits specification
+type Commit struct {
+	Hash         plumbing.Hash
+	Type         string
+	Scope        string
+	Description  string
+	Body         string
+	Breaking     bool
+	Conventional bool
+	// VersionBump is the bump of a Version-Bump trailer, overriding the bump
+	// implied by the type; None without the trailer
+	VersionBump semver.Bump
+	// ReleaseAs is the version of a Release-As trailer, e.g. 2.0.0
+	ReleaseAs string
+}
+
+// Result contains the analyzed commits and the resulting version bump
+type Result struct {
+	Commits []Commit
+	Bump    semver.Bump
+	// ReleaseAs is the version the newest Release-As trailer pins the next
+	// release to, or empty
+	ReleaseAs string
+}
+
+// ParseMessage classifies a commit message. Messages without a valid
+// Conventional Commits header are returned with Conventional set to false
+// and the first line as description.
+func ParseMessage(message string) Commit {
+	message = strings.TrimSpace(message)
+	header, body, _ := strings.Cut(message, "\n")
+	body = strings.TrimSpace(body)
+
+	var c Commit
+	match := headerPattern.FindStringSubmatch(strings.TrimSpace(header))
+	if match == nil {
+		c = Commit{
+			Description: strings.TrimSpace(header),
+			Body:        body,
+			Breaking:    hasBreakingFooter(body),
+		}
+	} else {
+		c = Commit{
+			Type:         strings.ToLower(match[1]),
+			Scope:        match[2],
+			Description:  match[4],
+			Body:         body,
+			Breaking:     match[3] == "!" || hasBreakingFooter(body),
+			Conventional: true,
+		}
+	}
+	if bump, ok := trailer(body, "Version-Bump"); ok {
+		// Invalid bumps are ignored like non-conventional headers
+		c.VersionBump, _ = semver.ParseBump(bump)
+	}
+	c.ReleaseAs, _ = trailer(body, "Release-As")
+	return c
+}
+
+// trailer returns the value of the last trailer with the given key in the
+// body, e.g. "major" for "Version-Bump: major". Keys are case-insensitive.
+func trailer(body, key string) (string, bool) {
+	value, found := "", false
+	for _, line := range strings.Split(body, "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), key) {
+			value, found = strings.TrimSpace(v), true
+		}
+	}
+	return value, found
+}
+
+// hasBreakingFooter reports whether the body contains a BREAKING CHANGE footer
+func hasBreakingFooter(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return true
+		}
+	}
+	return false
+}
+
+// Bump returns the version bump implied by the commit:
+// breaking changes are major, feat is minor, fix and perf are patch.
+// A Version-Bump trailer takes precedence.
+func (c Commit) Bump() semver.Bump {
+	switch {
+	case c.VersionBump != semver.None:
+		return c.VersionBump
+	case c.Breaking:
+		return semver.Major
+	case c.Type == "feat":
+		return semver.Minor
+	case c.Type == "fix" || c.Type == "perf":
+		return semver.Patch
+	default:
+		return semver.None
+	}
+}
+
+// Analyze walks the commits reachable from the given hash but not from the
+// commit of sinceTag, like git log sinceTag..hash, classifies them and
+// determines the highest bump and the newest Release-As trailer. Commits of
+// branches merged after the tag that it already contains are not analyzed.
+// If sinceTag is empty, the whole history is analyzed.
+func Analyze(repo *git.Repository, from plumbing.Hash, sinceTag string) (*Result, error) {
+	var since plumbing.Hash
+	if sinceTag != "" {
+		tagCommit, err := resolveTag(repo, sinceTag)
+		if err != nil {
+			return nil, err
+		}
+		since = tagCommit
+	}
+
+	hashes, err := revwalk.Range(context.Background(), commitgraph.NewObjectCommitNodeIndex(repo.Storer), from, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk commits: %w", err)
+	}
+
+	result := &Result{}
+	// Commits are walked newest first
+	for _, hash := range hashes {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+		}
+		c := ParseMessage(commit.Message)
+		c.Hash = commit.Hash
+		result.Commits = append(result.Commits, c)
+		if b := c.Bump(); b > result.Bump {
+			result.Bump = b
+		}
+		if result.ReleaseAs == "" {
+			result.ReleaseAs = c.ReleaseAs
+		}
+	}
+
+	return result, nil
+}
+
+// resolveTag returns the commit hash a tag points to, peeling annotated tags
+func resolveTag(repo *git.Repository, name string) (plumbing.Hash, error) {
+	ref, err := repo.Tag(name)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve tag %s: %w", name, err)
+	}
+
+	tagObj, err := repo.TagObject(ref.Hash())
+	if err == nil {
+		commit, err := tagObj.Commit()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to peel tag %s: %w", name, err)
+		}
+		return commit.Hash, nil
+	}
+
+	return ref.Hash(), nil
+}
@@ -0,0 +1,244 @@
+package conventional
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected Commit
+	}{
+		{
+			name:    "feature with scope",
+			message: "feat(cli): add json output",
+			expected: Commit{
+				Type:         "feat",
+				Scope:        "cli",
+				Description:  "add json output",
+				Conventional: true,
+			},
+		},
+		{
+			name:    "breaking marker",
+			message: "refactor!: drop legacy flags",
+			expected: Commit{
+				Type:         "refactor",
+				Description:  "drop legacy flags",
+				Breaking:     true,
+				Conventional: true,
+			},
+		},
+		{
+			name:    "breaking footer",
+			message: "fix: change output\n\nDetails here.\n\nBREAKING CHANGE: output format changed",
+			expected: Commit{
+				Type:         "fix",
+				Description:  "change output",
+				Body:         "Details here.\n\nBREAKING CHANGE: output format changed",
+				Breaking:     true,
+				Conventional: true,
+			},
+		},
+		{
+			name:    "trailers",
+			message: "feat!: new api\n\nRelease-As: v2.0.0\nversion-bump: minor",
+			expected: Commit{
+				Type:         "feat",
+				Description:  "new api",
+				Body:         "Release-As: v2.0.0\nversion-bump: minor",
+				Breaking:     true,
+				Conventional: true,
+				VersionBump:  semver.Minor,
+				ReleaseAs:    "v2.0.0",
+			},
+		},
+		{
+			name:    "non-conventional",
+			message: "Update README\n",
+			expected: Commit{
+				Description: "Update README",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseMessage(tt.message)
+			if result != tt.expected {
+				t.Errorf("ParseMessage(%q) = %+v, want %+v", tt.message, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommitBump(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected semver.Bump
+	}{
+		{"feat: new thing", semver.Minor},
+		{"fix: broken thing", semver.Patch},
+		{"perf: faster thing", semver.Patch},
+		{"docs: explain thing", semver.None},
+		{"chore!: drop support", semver.Major},
+		{"Random commit", semver.None},
+		{"feat!: drop support\n\nVersion-Bump: minor", semver.Minor},
+		{"docs: explain thing\n\nVersion-Bump: patch", semver.Patch},
+		{"fix: broken thing\n\nVersion-Bump: huge", semver.Patch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if result := ParseMessage(tt.message).Bump(); result != tt.expected {
+				t.Errorf("Bump() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	// Create a temporary directory for test repository
+	tempDir, err := os.MkdirTemp("", "gitversion-test-conventional-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	commit := func(message string) plumbing.Hash {
+		testFile := filepath.Join(tempDir, "test.txt")
+		if err := os.WriteFile(testFile, []byte(message), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		if _, err := w.Add("test.txt"); err != nil {
+			t.Fatalf("Failed to add file: %v", err)
+		}
+		hash, err := w.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  "Test User",
+				Email: "test@example.com",
+				When:  time.Now(),
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+		return hash
+	}
+
+	commit("feat!: initial release")
+	tagged := commit("fix: first fix")
+	if _, err := repo.CreateTag("v1.0.0", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commit("docs: update docs")
+	head := commit("feat(api): add endpoint")
+
+	result, err := Analyze(repo, head, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Commits) != 2 {
+		t.Errorf("Analyze returned %d commits, want 2", len(result.Commits))
+	}
+	if result.Bump != semver.Minor {
+		t.Errorf("Bump = %s, want %s", result.Bump, semver.Minor)
+	}
+
+	all, err := Analyze(repo, head, "")
+	if err != nil {
+		t.Fatalf("Analyze without tag failed: %v", err)
+	}
+	if len(all.Commits) != 4 {
+		t.Errorf("Analyze returned %d commits, want 4", len(all.Commits))
+	}
+	if all.Bump != semver.Major {
+		t.Errorf("Bump = %s, want %s", all.Bump, semver.Major)
+	}
+
+	// The newest Release-As trailer pins the next release
+	commit("chore: prepare 3.0\n\nRelease-As: 3.0.0")
+	commit("chore: prepare 2.5\n\nRelease-As: 2.5.0")
+	head = commit("fix: last fix")
+	result, err = Analyze(repo, head, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if result.ReleaseAs != "2.5.0" {
+		t.Errorf("ReleaseAs = %q, want %q", result.ReleaseAs, "2.5.0")
+	}
+
+	if _, err := Analyze(repo, head, "v9.9.9"); err == nil {
+		t.Error("Analyze with unknown tag expected error")
+	}
+}
+
+func TestAnalyzeMergedBranch(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	when := time.Now()
+	commit := func(message string, parents ...plumbing.Hash) plumbing.Hash {
+		when = when.Add(time.Second)
+		sig := &object.Signature{Name: "Test User", Email: "test@example.com", When: when}
+		hash, err := w.Commit(message, &git.CommitOptions{Author: sig, Committer: sig, Parents: parents, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+		return hash
+	}
+
+	// A branch forked before v1.0.0 and merged after it:
+	//
+	//	init - old feature - release (v1.0.0) - merge
+	//	            \                          /
+	//	             fix: branch fix ----------
+	init := commit("feat: init")
+	old := commit("feat: old feature", init)
+	fix := commit("fix: branch fix", old)
+	release := commit("chore: release", old)
+	if _, err := repo.CreateTag("v1.0.0", release, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	merge := commit("Merge branch 'fix'", release, fix)
+
+	result, err := Analyze(repo, merge, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	var hashes []plumbing.Hash
+	for _, c := range result.Commits {
+		hashes = append(hashes, c.Hash)
+	}
+	if len(hashes) != 2 || hashes[0] != merge || hashes[1] != fix {
+		t.Errorf("Analyze returned commits %v, want the merge and the branch fix only", hashes)
+	}
+	if result.Bump != semver.Patch {
+		t.Errorf("Bump = %s, want %s", result.Bump, semver.Patch)
+	}
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// maxTagLength is the maximum length of an OCI image tag
+const maxTagLength = 128
+
+// invalidTagChars matches characters not allowed in OCI image tags
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Tags returns the image tags for the version info, in order and without
+// duplicates:
+//   - the full version without leading "v", e.g. 1.2.3 or 1.2.3-5-gabc123d
+//   - MAJOR.MINOR and MAJOR for release versions, e.g. 1.2 and 1
+//   - the branch slug, unless HEAD is detached
+//   - latest on the default branch
+func Tags(info *version.Info) []string {
+	var tags []string
+	add := func(tag string) {
+		tag = SanitizeTag(tag)
+		if tag == "" {
+			return
+		}
+		for _, t := range tags {
+			if t == tag {
+				return
+			}
+		}
+		tags = append(tags, tag)
+	}
+
+	v := strings.TrimPrefix(info.Version, "v")
+	add(v)
+	if sv, err := semver.Parse(v); err == nil && sv.Prerelease == "" {
+		major := strconv.FormatUint(sv.Major, 10)
+		add(major + "." + strconv.FormatUint(sv.Minor, 10))
+		add(major)
+	}
+	if info.GitBranch != "HEAD" {
+		add(info.GitBranchSlug)
+	}
+	if info.IsDefaultBranch() {
+		add("latest")
+	}
+	return tags
+}
+
+// SanitizeTag converts s into a valid OCI image tag: invalid characters are
+// replaced by "-", leading "." and "-" are removed and the result is truncated
+// to 128 characters
+func SanitizeTag(s string) string {
+	s = invalidTagChars.ReplaceAllString(s, "-")
+	s = strings.TrimLeft(s, ".-")
+	if len(s) > maxTagLength {
+		s = s[:maxTagLength]
+	}
+	return s
+}
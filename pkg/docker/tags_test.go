@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     version.Info
+		expected []string
+	}{
+		{
+			name:     "release on default branch",
+			info:     version.Info{Version: "v1.2.3", GitBranch: "main", GitBranchSlug: "main", DefaultBranch: "main"},
+			expected: []string{"1.2.3", "1.2", "1", "main", "latest"},
+		},
+		{
+			name:     "describe version on default branch",
+			info:     version.Info{Version: "v1.2.3-5-gabc123d", GitBranch: "main", GitBranchSlug: "main", DefaultBranch: "main"},
+			expected: []string{"1.2.3-5-gabc123d", "main", "latest"},
+		},
+		{
+			name:     "feature branch",
+			info:     version.Info{Version: "feature-x-gabc123d", GitBranch: "feature/x", GitBranchSlug: "feature-x", DefaultBranch: "main"},
+			expected: []string{"feature-x-gabc123d", "feature-x"},
+		},
+		{
+			name:     "detached tag checkout with build metadata",
+			info:     version.Info{Version: "v1.2.3+gabc123d", GitBranch: "HEAD", GitBranchSlug: "HEAD", DefaultBranch: "main"},
+			expected: []string{"1.2.3-gabc123d", "1.2", "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := Tags(&tt.info)
+			if !reflect.DeepEqual(tags, tt.expected) {
+				t.Errorf("Tags() = %q, want %q", tags, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSanitizeTag(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"release/1.2.3+build.5", "release-1.2.3-build.5"},
+		{".hidden", "hidden"},
+		{"-dash", "dash"},
+		{strings.Repeat("a", 200), strings.Repeat("a", 128)},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeTag(tt.input); got != tt.expected {
+			t.Errorf("SanitizeTag(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package goreleaser derives the environment goreleaser reads from the
+// version info, so that gitversion decides the version of a release
+package goreleaser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+// Release reports whether goreleaser should publish a release of the
+// version: HEAD is at the latest tag, the working tree is clean and the
+// version is SemVer. Other versions are built as snapshots.
+func Release(info *version.Info) bool {
+	if info.LatestTag == "" || info.GitDescribe != info.LatestTag || info.IsDirty {
+		return false
+	}
+	_, err := semver.Parse(info.Version)
+	return err == nil
+}
+
+// PreviousTag returns the nearest tag before the latest tag reachable from
+// hash, searched from the first parent of the commit the latest tag points
+// to, or "" if there is none. The options select the tags as for Describe.
+func PreviousTag(repo *git.Repository, hash plumbing.Hash, opts ...version.DescribeOption) (string, error) {
+	d, err := version.Describe(repo, hash, opts...)
+	if err != nil || d.Tag == "" {
+		return "", err
+	}
+	commit, err := repo.CommitObject(d.TagHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit of tag %s: %w", d.Tag, err)
+	}
+	if commit.NumParents() == 0 {
+		return "", nil
+	}
+	prev, err := version.Describe(repo, commit.ParentHashes[0], opts...)
+	if err != nil {
+		return "", err
+	}
+	return prev.Tag, nil
+}
+
+// Env returns the variables for goreleaser: GORELEASER_CURRENT_TAG, the
+// latest tag if it is SemVer as goreleaser requires, and
+// GORELEASER_PREVIOUS_TAG, which set the tags goreleaser releases and
+// generates the changelog for, and the version and whether it is a snapshot
+// with the given prefix. The version is the one of the strategy, not the
+// tag, e.g. GITVERSION_VERSION for
+// snapshot.version_template: "{{ .Env.GITVERSION_VERSION }}"
+func Env(info *version.Info, previousTag, prefix string) []version.Field {
+	return []version.Field{
+		{Name: "GORELEASER_CURRENT_TAG", Value: currentTag(info)},
+		{Name: "GORELEASER_PREVIOUS_TAG", Value: previousTag},
+		{Name: prefix + "VERSION", Value: info.Version},
+		{Name: prefix + "SNAPSHOT", Value: strconv.FormatBool(!Release(info))},
+	}
+}
+
+// currentTag returns the latest tag if it is SemVer without component
+// namespace and tag prefix, else ""
+func currentTag(info *version.Info) string {
+	if _, err := info.LatestSemVer(); info.LatestTag == "" || err != nil {
+		return ""
+	}
+	return info.LatestTag
+}
@@ -0,0 +1,132 @@
+package goreleaser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+func TestRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     version.Info
+		expected bool
+	}{
+		{"at tag", version.Info{Version: "v1.2.3", LatestTag: "v1.2.3", GitDescribe: "v1.2.3"}, true},
+		{"stripped tag prefix", version.Info{Version: "1.2.3", LatestTag: "v1.2.3", GitDescribe: "v1.2.3"}, true},
+		{"after tag", version.Info{Version: "v1.2.3-1-gabc123d", LatestTag: "v1.2.3", GitDescribe: "v1.2.3-1-gabc123d"}, false},
+		{"dirty", version.Info{Version: "v1.2.3-20250101000000", LatestTag: "v1.2.3", GitDescribe: "v1.2.3", IsDirty: true}, false},
+		{"feature branch at tag", version.Info{Version: "feature-x-gabc123d", LatestTag: "v1.2.3", GitDescribe: "v1.2.3"}, false},
+		{"no tag", version.Info{Version: "main-gabc123d"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Release(&tt.info); got != tt.expected {
+				t.Errorf("Release() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnv(t *testing.T) {
+	info := &version.Info{Version: "v1.2.3-1-gabc123d", LatestTag: "v1.2.3", GitDescribe: "v1.2.3-1-gabc123d"}
+	expected := []version.Field{
+		{Name: "GORELEASER_CURRENT_TAG", Value: "v1.2.3"},
+		{Name: "GORELEASER_PREVIOUS_TAG", Value: "v1.2.2"},
+		{Name: "APP_VERSION", Value: "v1.2.3-1-gabc123d"},
+		{Name: "APP_SNAPSHOT", Value: "true"},
+	}
+	if got := Env(info, "v1.2.2", "APP_"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Env() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestEnvNonSemVerTag(t *testing.T) {
+	info := &version.Info{Version: "main-gabc123d", LatestTag: "nightly", GitDescribe: "nightly-1-gabc123d"}
+	expected := []version.Field{
+		{Name: "GORELEASER_CURRENT_TAG", Value: ""},
+		{Name: "GORELEASER_PREVIOUS_TAG", Value: ""},
+		{Name: "GITVERSION_VERSION", Value: "main-gabc123d"},
+		{Name: "GITVERSION_SNAPSHOT", Value: "true"},
+	}
+	if got := Env(info, "", "GITVERSION_"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Env() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestPreviousTag(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gitversion-test-goreleaser-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commit := func(message string) plumbing.Hash {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte(message), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		w, err := repo.Worktree()
+		if err != nil {
+			t.Fatalf("Failed to get worktree: %v", err)
+		}
+		if _, err := w.Add("test.txt"); err != nil {
+			t.Fatalf("Failed to add file: %v", err)
+		}
+		sig := &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+		hash, err := w.Commit(message, &git.CommitOptions{Author: sig})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+		return hash
+	}
+	tag := func(name string, hash plumbing.Hash) {
+		t.Helper()
+		if _, err := repo.CreateTag(name, hash, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+
+	first := commit("first")
+	if prev, err := PreviousTag(repo, first); err != nil || prev != "" {
+		t.Errorf("PreviousTag() without tags = %q, %v, want empty", prev, err)
+	}
+	tag("v1.0.0", first)
+	second := commit("second")
+	tag("nightly", second)
+	tag("v1.1.0", commit("third"))
+	head := commit("fourth")
+
+	tests := []struct {
+		name     string
+		hash     plumbing.Hash
+		opts     []version.DescribeOption
+		expected string
+	}{
+		{"root tag", first, nil, ""},
+		{"tag prefix", head, []version.DescribeOption{version.WithTagPrefix("v")}, "v1.0.0"},
+		{"any tag", head, nil, "nightly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, err := PreviousTag(repo, tt.hash, tt.opts...)
+			if err != nil {
+				t.Fatalf("PreviousTag failed: %v", err)
+			}
+			if prev != tt.expected {
+				t.Errorf("PreviousTag() = %q, want %q", prev, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package inject
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// helmCorePattern matches the leading numeric version of a non-SemVer
+// version, e.g. 2024.05 of 2024.05.3-beta, and the rest
+var helmCorePattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(.*)$`)
+
+// invalidIdentifierChars matches characters not allowed in SemVer identifiers
+var invalidIdentifierChars = regexp.MustCompile(`[^0-9A-Za-z.-]+`)
+
+// HelmVersion returns the version as the SemVer 2 version Helm requires for
+// charts, without leading "v". Versions that are not SemVer are made one: a
+// missing minor or patch is 0, leading zeros are removed, e.g. 2024.5.3 for
+// the CalVer 2024.05.3, and versions without a numeric core become
+// prereleases of 0.0.0, e.g. 0.0.0-feature-x-gabc123d.
+func HelmVersion(version string) string {
+	v := strings.TrimPrefix(version, "v")
+	if _, err := semver.Parse(v); err == nil {
+		return v
+	}
+
+	v, build, _ := strings.Cut(v, "+")
+	core := "0.0.0"
+	if m := helmCorePattern.FindStringSubmatch(v); m != nil {
+		nums := make([]string, 3)
+		for i, n := range m[1:4] {
+			nums[i] = trimLeadingZeros(n)
+		}
+		core = strings.Join(nums, ".")
+		v = m[4]
+	}
+
+	out := core
+	if pre := sanitizeIdentifiers(strings.TrimLeft(v, "-.")); pre != "" {
+		out += "-" + pre
+	}
+	if build = sanitizeIdentifiers(build); build != "" {
+		out += "+" + build
+	}
+	return out
+}
+
+// sanitizeIdentifiers replaces invalid characters of dot-separated SemVer
+// identifiers with '-', drops empty identifiers and leading zeros of numeric
+// ones
+func sanitizeIdentifiers(s string) string {
+	var ids []string
+	for _, id := range strings.Split(invalidIdentifierChars.ReplaceAllString(s, "-"), ".") {
+		if id == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(id, 10, 64); err == nil {
+			id = trimLeadingZeros(id)
+		}
+		ids = append(ids, id)
+	}
+	return strings.Join(ids, ".")
+}
+
+// trimLeadingZeros returns the decimal number without leading zeros, or 0 if
+// it is empty
+func trimLeadingZeros(n string) string {
+	n = strings.TrimLeft(n, "0")
+	if n == "" {
+		return "0"
+	}
+	return n
+}
+
+// HelmChart sets version and appVersion in the Chart.yaml of the chart
+// directory, or the given Chart.yaml, preserving formatting and comments.
+// appVersion is added after version if the chart has none. It returns the
+// path of the updated file.
+func HelmChart(chart string, version, appVersion string) (string, error) {
+	path := chart
+	if fi, err := os.Stat(chart); err == nil && fi.IsDir() {
+		path = filepath.Join(chart, "Chart.yaml")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated, err := SetYAMLKey(content, "version", version)
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if withApp, err := SetYAMLKey(updated, "appVersion", appVersion); err == nil {
+		updated = withApp
+	} else {
+		updated = insertAfterYAMLKey(updated, "version", fmt.Sprintf("appVersion: %q", appVersion))
+	}
+
+	if err := os.WriteFile(path, updated, fi.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// insertAfterYAMLKey inserts the line after the top-level scalar key, which
+// must exist
+func insertAfterYAMLKey(content []byte, key string, line string) []byte {
+	pattern := regexp.MustCompile(fmt.Sprintf(yamlKeyPattern, regexp.QuoteMeta(key)))
+	end := pattern.FindIndex(content)[1]
+	out := make([]byte, 0, len(content)+len(line)+1)
+	out = append(out, content[:end]...)
+	out = append(out, '\n')
+	out = append(out, line...)
+	return append(out, content[end:]...)
+}
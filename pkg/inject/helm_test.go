@@ -0,0 +1,101 @@
+package inject
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+func TestHelmVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"v1.2.3", "1.2.3"},
+		{"v1.2.3-5-gabc123d", "1.2.3-5-gabc123d"},
+		{"1.2.3+gabc123d.main", "1.2.3+gabc123d.main"},
+		{"feature-x-gabc123d", "0.0.0-feature-x-gabc123d"},
+		{"main-gabc123d-20250101120000", "0.0.0-main-gabc123d-20250101120000"},
+		{"2024.05.3", "2024.5.3"},
+		{"2024.05", "2024.5.0"},
+		{"v2", "2.0.0"},
+		{"1.2.3.4", "1.2.3-4"},
+		{"1.2.3-rc.01", "1.2.3-rc.1"},
+		{"v1.2.3+g abc/x", "1.2.3+g-abc-x"},
+		{"pr-12-gabc123d", "0.0.0-pr-12-gabc123d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got := HelmVersion(tt.version)
+			if got != tt.expected {
+				t.Errorf("HelmVersion(%q) = %q, want %q", tt.version, got, tt.expected)
+			}
+			if _, err := semver.Parse(got); err != nil {
+				t.Errorf("HelmVersion(%q) = %q is not SemVer: %v", tt.version, got, err)
+			}
+		})
+	}
+}
+
+func TestHelmChart(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name: "keeps comments and quoting",
+			content: `apiVersion: v2
+name: app
+version: 0.1.0 # bumped by CI
+appVersion: "0.1.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`,
+			expected: `apiVersion: v2
+name: app
+version: 0.0.0-feature-x-gabc123d # bumped by CI
+appVersion: "feature-x-gabc123d"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`,
+		},
+		{
+			name:    "adds appVersion",
+			content: "apiVersion: v2\nname: app\nversion: 0.1.0\ntype: application\n",
+			expected: "apiVersion: v2\nname: app\nversion: 0.0.0-feature-x-gabc123d\n" +
+				"appVersion: \"feature-x-gabc123d\"\ntype: application\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "Chart.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			got, err := HelmChart(dir, HelmVersion("feature-x-gabc123d"), "feature-x-gabc123d")
+			if err != nil {
+				t.Fatalf("HelmChart failed: %v", err)
+			}
+			if got != path {
+				t.Errorf("HelmChart() = %q, want %q", got, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Chart.yaml = %q, want %q", data, tt.expected)
+			}
+		})
+	}
+
+	if _, err := HelmChart(t.TempDir(), "1.0.0", "v1.0.0"); err == nil {
+		t.Error("HelmChart expected error for a directory without Chart.yaml")
+	}
+}
@@ -0,0 +1,213 @@
+package inject
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Editor rewrites the version field in the content of a file
+type Editor interface {
+	// Name returns the file type the editor handles, e.g. "package.json"
+	Name() string
+	// Inject returns the content with the version field set to version
+	Inject(content []byte, version string) ([]byte, error)
+}
+
+// editors maps file base names to their editors
+var editors = map[string]Editor{
+	"package.json":   packageJSON{},
+	"Chart.yaml":     chartYAML{},
+	"pyproject.toml": pyprojectTOML{},
+	"VERSION":        plainVersion{},
+}
+
+// EditorFor returns the editor for the file based on its name
+func EditorFor(path string) (Editor, error) {
+	editor, ok := editors[filepath.Base(path)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file %s: expected package.json, Chart.yaml, pyproject.toml or VERSION", path)
+	}
+	return editor, nil
+}
+
+// File rewrites the version field of the file in place, preserving its formatting
+func File(path string, version string) error {
+	editor, err := EditorFor(path)
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated, err := editor.Inject(content, version)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, updated, fi.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// packageJSON sets the top-level "version" of an npm package.json.
+// npm versions have no leading "v".
+type packageJSON struct{}
+
+func (packageJSON) Name() string { return "package.json" }
+
+func (packageJSON) Inject(content []byte, version string) ([]byte, error) {
+	start, end, err := findTopLevelJSONString(content, "version")
+	if err != nil {
+		return nil, err
+	}
+	value := `"` + strings.TrimPrefix(version, "v") + `"`
+
+	out := make([]byte, 0, len(content)+len(value))
+	out = append(out, content[:start]...)
+	out = append(out, value...)
+	out = append(out, content[end:]...)
+	return out, nil
+}
+
+// findTopLevelJSONString returns the byte range of the string value of a key
+// in the top-level object, including the quotes
+func findTopLevelJSONString(content []byte, key string) (int, int, error) {
+	depth := 0
+	expectValue := false
+	lastString := ""
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '{', '[':
+			depth++
+			expectValue = false
+		case '}', ']':
+			depth--
+			expectValue = false
+		case ':':
+			expectValue = depth == 1 && lastString == key
+		case ',':
+			expectValue = false
+		case '"':
+			end, err := jsonStringEnd(content, i)
+			if err != nil {
+				return 0, 0, err
+			}
+			if expectValue {
+				return i, end, nil
+			}
+			lastString = string(content[i+1 : end-1])
+			i = end - 1
+		}
+	}
+	return 0, 0, fmt.Errorf("no top-level %q string field found", key)
+}
+
+// jsonStringEnd returns the index after the closing quote of the string starting at start
+func jsonStringEnd(content []byte, start int) (int, error) {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated JSON string")
+}
+
+// chartYAML sets the version of a Helm Chart.yaml, made SemVer by HelmVersion
+type chartYAML struct{}
+
+func (chartYAML) Name() string { return "Chart.yaml" }
+
+func (chartYAML) Inject(content []byte, version string) ([]byte, error) {
+	return SetYAMLKey(content, "version", HelmVersion(version))
+}
+
+// yamlKeyPattern matches a top-level scalar key, keeping trailing comments
+var yamlKeyPattern = `(?m)^(%s:[ \t]*)("[^"\n]*"|'[^'\n]*'|[^\s#]*)([ \t]*(?:#.*)?)$`
+
+// SetYAMLKey sets a top-level scalar key in YAML content, preserving
+// formatting and comments. String values are double-quoted if the existing
+// value was quoted.
+func SetYAMLKey(content []byte, key string, value string) ([]byte, error) {
+	pattern := regexp.MustCompile(fmt.Sprintf(yamlKeyPattern, regexp.QuoteMeta(key)))
+	loc := pattern.FindSubmatchIndex(content)
+	if loc == nil {
+		return nil, fmt.Errorf("no top-level %q key found", key)
+	}
+
+	old := string(content[loc[4]:loc[5]])
+	if strings.HasPrefix(old, `"`) || strings.HasPrefix(old, "'") {
+		value = old[:1] + value + old[:1]
+	}
+
+	out := make([]byte, 0, len(content)+len(value))
+	out = append(out, content[:loc[4]]...)
+	out = append(out, value...)
+	out = append(out, content[loc[5]:]...)
+	return out, nil
+}
+
+// pyprojectTOML sets the version in the [project] and [tool.poetry] tables of
+// a pyproject.toml, made PEP 440 by PEP440Version
+type pyprojectTOML struct{}
+
+func (pyprojectTOML) Name() string { return "pyproject.toml" }
+
+// tomlVersionPattern matches a version assignment with a basic or literal string
+var tomlVersionPattern = regexp.MustCompile(`^(\s*version\s*=\s*)("[^"]*"|'[^']*')(.*)$`)
+
+func (pyprojectTOML) Inject(content []byte, version string) ([]byte, error) {
+	version, err := PEP440Version(version)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	section := ""
+	found := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			section = strings.Trim(trimmed, "[] \t")
+			continue
+		}
+		if section != "project" && section != "tool.poetry" {
+			continue
+		}
+
+		body := strings.TrimRight(line, "\r\n")
+		match := tomlVersionPattern.FindStringSubmatch(body)
+		if match == nil {
+			continue
+		}
+		quote := match[2][:1]
+		lines[i] = match[1] + quote + version + quote + match[3] + line[len(body):]
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("no version field found in [project] or [tool.poetry]")
+	}
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// plainVersion replaces the whole content of a VERSION file
+type plainVersion struct{}
+
+func (plainVersion) Name() string { return "VERSION" }
+
+func (plainVersion) Inject(content []byte, version string) ([]byte, error) {
+	return []byte(version + "\n"), nil
+}
@@ -0,0 +1,205 @@
+package inject
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		version  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "package.json top-level version",
+			file: "package.json",
+			content: `{
+  "name": "app",
+  "dependencies": {
+    "lib": { "version": "9.9.9" }
+  },
+  "version": "0.1.0",
+  "scripts": {}
+}
+`,
+			version: "v1.2.3",
+			expected: `{
+  "name": "app",
+  "dependencies": {
+    "lib": { "version": "9.9.9" }
+  },
+  "version": "1.2.3",
+  "scripts": {}
+}
+`,
+		},
+		{
+			name:    "package.json without version",
+			file:    "package.json",
+			content: `{"name": "version", "nested": {"version": "1.0.0"}}`,
+			version: "1.2.3",
+			wantErr: true,
+		},
+		{
+			name: "Chart.yaml keeps comments and formatting",
+			file: "Chart.yaml",
+			content: `apiVersion: v2
+name: app
+# chart version
+version: 0.1.0 # bumped by CI
+appVersion: "0.1.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`,
+			version: "v1.2.3",
+			expected: `apiVersion: v2
+name: app
+# chart version
+version: 1.2.3 # bumped by CI
+appVersion: "0.1.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`,
+		},
+		{
+			name: "pyproject.toml project table",
+			file: "pyproject.toml",
+			content: `[build-system]
+requires = ["hatchling"]
+
+[project]
+name = "app"
+version = "0.1.0"  # managed by gitversion
+`,
+			version: "v1.2.3",
+			expected: `[build-system]
+requires = ["hatchling"]
+
+[project]
+name = "app"
+version = "1.2.3"  # managed by gitversion
+`,
+		},
+		{
+			name: "pyproject.toml poetry table",
+			file: "pyproject.toml",
+			content: `[tool.black]
+version = 'ignored'
+
+[tool.poetry]
+version = '0.1.0'
+`,
+			version: "2.0.0",
+			expected: `[tool.black]
+version = 'ignored'
+
+[tool.poetry]
+version = '2.0.0'
+`,
+		},
+		{
+			name: "pyproject.toml project and poetry tables",
+			file: "pyproject.toml",
+			content: `[project]
+name = "app"
+version = "0.1.0"
+
+[tool.poetry]
+version = "0.1.0"
+`,
+			version: "v1.2.3-5-gabc123d",
+			expected: `[project]
+name = "app"
+version = "1.2.4.dev5+gabc123d"
+
+[tool.poetry]
+version = "1.2.4.dev5+gabc123d"
+`,
+		},
+		{
+			name:    "pyproject.toml branch version",
+			file:    "pyproject.toml",
+			content: "[project]\nversion = \"0.1.0\"\n",
+			version: "feature-x-gabc123d",
+			wantErr: true,
+		},
+		{
+			name:     "VERSION file",
+			file:     "VERSION",
+			content:  "0.1.0\n",
+			version:  "v1.2.3-5-gabc123d",
+			expected: "v1.2.3-5-gabc123d\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor, err := EditorFor(filepath.Join("some", "dir", tt.file))
+			if err != nil {
+				t.Fatalf("EditorFor(%q) failed: %v", tt.file, err)
+			}
+			result, err := editor.Inject([]byte(tt.content), tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Inject() expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Inject() failed: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Inject() =\n%s\nwant\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEditorForUnsupported(t *testing.T) {
+	if _, err := EditorFor("setup.py"); err == nil {
+		t.Error("EditorFor(\"setup.py\") expected error")
+	}
+}
+
+func TestSetYAMLKeyQuoted(t *testing.T) {
+	result, err := SetYAMLKey([]byte("appVersion: \"0.1.0\"\n"), "appVersion", "1.2.3")
+	if err != nil {
+		t.Fatalf("SetYAMLKey failed: %v", err)
+	}
+	if string(result) != "appVersion: \"1.2.3\"\n" {
+		t.Errorf("SetYAMLKey() = %q, want quoted value", result)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "VERSION")
+	if err := os.WriteFile(path, []byte("0.0.0\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := File(path, "v1.0.0"); err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "v1.0.0\n" {
+		t.Errorf("VERSION = %q, want %q", data, "v1.0.0\n")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("File mode = %v, want 0600 preserved", fi.Mode().Perm())
+	}
+}
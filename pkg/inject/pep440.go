@@ -0,0 +1,107 @@
+package inject
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// pep440ReleasePattern matches release versions of any number of numeric
+// components, e.g. 1.2.3.5 of the height strategy or the CalVer 2024.05.3
+var pep440ReleasePattern = regexp.MustCompile(`^v?[0-9]+(?:\.[0-9]+)*$`)
+
+// pep440DescribePattern matches the prerelease of git describe output, e.g.
+// 3-gabc123d of 1.2.3-3-gabc123d, with the prerelease of the tag if any
+var pep440DescribePattern = regexp.MustCompile(`^(?:(.+)-)?([0-9]+)-g([0-9a-f]{4,40})$`)
+
+// pep440Labels maps SemVer prerelease labels to PEP 440 pre-release segments
+var pep440Labels = map[string]string{
+	"alpha":   "a",
+	"a":       "a",
+	"beta":    "b",
+	"b":       "b",
+	"rc":      "rc",
+	"c":       "rc",
+	"pre":     "rc",
+	"preview": "rc",
+}
+
+// PEP440Version returns the version as the PEP 440 version Python packages
+// require, without leading "v". Release versions keep their numeric
+// components, SemVer prereleases labeled alpha, beta or rc become pre-releases
+// (1.2.3-rc.1 is 1.2.3rc1) and numeric prereleases dev releases (1.2.3-4 is
+// 1.2.3.dev4). Like setuptools-scm, git describe output is made a dev release
+// of the next version with the commit as local version: 1.2.3-4-gabc123d is
+// 1.2.4.dev4+gabc123d and 1.2.3-rc.1-4-gabc123d is 1.2.3rc2.dev4+gabc123d.
+// Other versions, e.g. branch slug versions, cannot be converted.
+func PEP440Version(version string) (string, error) {
+	if pep440ReleasePattern.MatchString(version) {
+		parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+		for i, n := range parts {
+			parts[i] = trimLeadingZeros(n)
+		}
+		return strings.Join(parts, "."), nil
+	}
+
+	v, err := semver.Parse(version)
+	if err != nil {
+		return "", fmt.Errorf("version %s cannot be converted to PEP 440: %w", version, err)
+	}
+
+	var local []string
+	pre, distance := v.Prerelease, ""
+	if m := pep440DescribePattern.FindStringSubmatch(pre); m != nil {
+		pre, distance = m[1], m[2]
+		local = append(local, "g"+m[3])
+	}
+
+	var ids []string
+	if pre != "" {
+		ids = strings.Split(pre, ".")
+	}
+	label, number, dev := "", uint64(0), distance
+	if len(ids) > 0 {
+		if l, ok := pep440Labels[strings.ToLower(ids[0])]; ok {
+			label, ids = l, ids[1:]
+			if len(ids) > 0 {
+				if n, err := strconv.ParseUint(ids[0], 10, 64); err == nil {
+					number, ids = n, ids[1:]
+				}
+			}
+		}
+	}
+	if len(ids) == 1 && dev == "" {
+		if _, err := strconv.ParseUint(ids[0], 10, 64); err == nil {
+			dev, ids = ids[0], nil
+		}
+	}
+	if len(ids) > 0 {
+		return "", fmt.Errorf("version %s cannot be converted to PEP 440: unsupported prerelease %q", version, v.Prerelease)
+	}
+
+	// Dev releases sort before their release, so those following a tag, like
+	// 1.2.3-4-gabc123d or 1.2.3-rc.1.4, belong to the next version
+	if label != "" && dev != "" {
+		number++
+	} else if distance != "" {
+		v.Patch++
+	}
+
+	out := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if label != "" {
+		out += label + strconv.FormatUint(number, 10)
+	}
+	if dev != "" {
+		out += ".dev" + dev
+	}
+	if v.Build != "" {
+		local = append(local, strings.ReplaceAll(v.Build, "-", "."))
+	}
+	if len(local) > 0 {
+		out += "+" + strings.ToLower(strings.Join(local, "."))
+	}
+	return out, nil
+}
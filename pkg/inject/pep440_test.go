@@ -0,0 +1,44 @@
+package inject
+
+import "testing"
+
+func TestPEP440Version(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+		wantErr  bool
+	}{
+		{version: "v1.2.3", expected: "1.2.3"},
+		{version: "v1.2.3.5", expected: "1.2.3.5"},
+		{version: "2024.05.3", expected: "2024.5.3"},
+		{version: "v1.2.3-rc.1", expected: "1.2.3rc1"},
+		{version: "1.2.3-alpha.2", expected: "1.2.3a2"},
+		{version: "1.2.3-beta", expected: "1.2.3b0"},
+		{version: "v1.2.3-5-gabc123d", expected: "1.2.4.dev5+gabc123d"},
+		{version: "v1.2.3-rc.1-5-gabc123d", expected: "1.2.3rc2.dev5+gabc123d"},
+		{version: "v1.0.1-3+gabc123d.main", expected: "1.0.1.dev3+gabc123d.main"},
+		{version: "v1.0.0-rc.1.3+gabc123d", expected: "1.0.0rc2.dev3+gabc123d"},
+		{version: "1.2.3+build-7", expected: "1.2.3+build.7"},
+		{version: "v1.2.3-20240102150405", expected: "1.2.3.dev20240102150405"},
+		{version: "feature-x-gabc123d", wantErr: true},
+		{version: "v1.2.3-dirty", wantErr: true},
+		{version: "v1.2.3-5-gabc123d-20240102150405", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := PEP440Version(tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("PEP440Version(%q) = %q, want error", tt.version, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PEP440Version(%q) failed: %v", tt.version, err)
+			}
+			if got != tt.expected {
+				t.Errorf("PEP440Version(%q) = %q, want %q", tt.version, got, tt.expected)
+			}
+		})
+	}
+}
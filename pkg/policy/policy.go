@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// Policy describes the rules a version must satisfy
+type Policy struct {
+	// Prefix is removed from the version before the SemVer rules are
+	// checked, e.g. "release/"
+	Prefix string
+	// SemVer requires a valid semantic version, with an optional leading "v"
+	SemVer bool
+	// Stable requires a semantic version without prerelease
+	Stable bool
+	// Pattern, if set, must match the version including the prefix; anchor it
+	// with ^ and $ to match the whole version
+	Pattern *regexp.Regexp
+}
+
+// Check validates the version and returns the violated rules, or nil if the
+// version satisfies the policy
+func (p Policy) Check(version string) []string {
+	var violations []string
+
+	if p.Prefix != "" && !strings.HasPrefix(version, p.Prefix) {
+		violations = append(violations, fmt.Sprintf("missing prefix %q", p.Prefix))
+	}
+	if p.SemVer || p.Stable {
+		v, err := semver.Parse(strings.TrimPrefix(version, p.Prefix))
+		switch {
+		case err != nil:
+			violations = append(violations, err.Error())
+		case p.Stable && v.Prerelease != "":
+			violations = append(violations, fmt.Sprintf("prerelease %q not allowed", v.Prerelease))
+		}
+	}
+	if p.Pattern != nil && !p.Pattern.MatchString(version) {
+		violations = append(violations, fmt.Sprintf("does not match %s", p.Pattern))
+	}
+	return violations
+}
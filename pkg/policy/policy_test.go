@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     Policy
+		version    string
+		violations int
+	}{
+		{"semver valid", Policy{SemVer: true}, "v1.2.3", 0},
+		{"semver prerelease", Policy{SemVer: true}, "1.2.3-rc.1", 0},
+		{"semver invalid", Policy{SemVer: true}, "main-gabc123d", 1},
+		{"stable valid", Policy{Stable: true}, "v1.2.3", 0},
+		{"stable prerelease", Policy{Stable: true}, "v1.2.3-rc.1", 1},
+		{"prefix valid", Policy{Prefix: "release/", SemVer: true}, "release/1.2.3", 0},
+		{"prefix missing", Policy{Prefix: "release/", SemVer: true}, "1.2.3", 1},
+		{"pattern valid", Policy{Pattern: regexp.MustCompile(`^v\d+\.\d+\.\d+$`)}, "v1.2.3", 0},
+		{"pattern invalid", Policy{Pattern: regexp.MustCompile(`^v\d+\.\d+\.\d+$`)}, "1.2.3", 1},
+		{"all violated", Policy{SemVer: true, Pattern: regexp.MustCompile(`^v`)}, "main", 2},
+		{"empty policy", Policy{}, "anything", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.policy.Check(tt.version)
+			if len(violations) != tt.violations {
+				t.Errorf("Check(%q) = %q, want %d violations", tt.version, violations, tt.violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package release
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// defaultUsername is the user name sent with GITVERSION_TOKEN if
+// GITVERSION_USERNAME is not set; hosts accept any name with a token
+const defaultUsername = "x-access-token"
+
+// Auth returns the authentication for pushing to the remote. HTTP(S) remotes
+// authenticate with GITVERSION_TOKEN and GITVERSION_USERNAME like -fetch-tags;
+// SSH remotes with the private key file, decrypted with
+// GITVERSION_SSH_PASSPHRASE, if one is given. nil selects the default of the
+// transport, e.g. the ssh-agent.
+func Auth(repo *git.Repository, remote, sshKeyFile string) (transport.AuthMethod, error) {
+	r, err := repo.Remote(remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find remote %s: %w", remote, err)
+	}
+	urls := r.Config().URLs
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	ep, err := transport.NewEndpoint(urls[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL of remote %s: %w", remote, err)
+	}
+
+	switch ep.Protocol {
+	case "http", "https":
+		token := os.Getenv("GITVERSION_TOKEN")
+		if token == "" {
+			return nil, nil
+		}
+		username := os.Getenv("GITVERSION_USERNAME")
+		if username == "" {
+			username = defaultUsername
+		}
+		return &http.BasicAuth{Username: username, Password: token}, nil
+	case "ssh":
+		if sshKeyFile == "" {
+			return nil, nil
+		}
+		user := ep.User
+		if user == "" {
+			user = ssh.DefaultUsername
+		}
+		auth, err := ssh.NewPublicKeysFromFile(user, sshKeyFile, os.Getenv("GITVERSION_SSH_PASSPHRASE"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSH key %s: %w", sshKeyFile, err)
+		}
+		return auth, nil
+	}
+	return nil, nil
+}
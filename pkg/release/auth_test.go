@@ -0,0 +1,65 @@
+package release
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestAuth(t *testing.T) {
+	_, repo := initTestRepo(t)
+	for name, url := range map[string]string{
+		"https": "https://github.com/org/app.git",
+		"ssh":   "git@github.com:org/app.git",
+		"local": "/srv/git/app.git",
+	} {
+		if _, err := repo.CreateRemote(&config.RemoteConfig{Name: name, URLs: []string{url}}); err != nil {
+			t.Fatalf("Failed to create remote: %v", err)
+		}
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	t.Setenv("GITVERSION_TOKEN", "")
+	for _, remote := range []string{"https", "ssh", "local"} {
+		if auth, err := Auth(repo, remote, ""); err != nil || auth != nil {
+			t.Errorf("Auth(%s) without credentials = %v, %v, want default", remote, auth, err)
+		}
+	}
+
+	t.Setenv("GITVERSION_TOKEN", "secret")
+	auth, err := Auth(repo, "https", "")
+	if basic, ok := auth.(*http.BasicAuth); err != nil || !ok || basic.Username != defaultUsername || basic.Password != "secret" {
+		t.Errorf("Auth(https) with token = %v, %v", auth, err)
+	}
+
+	auth, err = Auth(repo, "ssh", keyFile)
+	if keys, ok := auth.(*gitssh.PublicKeys); err != nil || !ok || keys.User != "git" {
+		t.Errorf("Auth(ssh) with key file = %v, %v", auth, err)
+	}
+	if _, err := Auth(repo, "ssh", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Auth with missing key file expected error")
+	}
+	if _, err := Auth(repo, "upstream", ""); err == nil {
+		t.Error("Auth for unknown remote expected error")
+	}
+}
@@ -0,0 +1,55 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// CommitFiles stages the given paths, relative to the root of the working
+// tree, and commits them with the Tagger identity as author and committer
+func CommitFiles(repo *git.Repository, message string, paths ...string) (plumbing.Hash, error) {
+	signature, err := Tagger(repo)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	for _, path := range paths {
+		if _, err := w.Add(path); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to add %s: %w", path, err)
+		}
+	}
+
+	hash, err := w.Commit(message, &git.CommitOptions{
+		Author:    signature,
+		Committer: signature,
+	})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to commit: %w", err)
+	}
+	return hash, nil
+}
+
+// PushBranch pushes the local branch to the branch of the same name on the
+// given remote with the given authentication, nil for the default of the
+// transport
+func PushBranch(repo *git.Repository, remote string, branch string, auth transport.AuthMethod) error {
+	refName := plumbing.NewBranchReferenceName(branch)
+	err := repo.Push(&git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(refName + ":" + refName)},
+		Auth:       auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push branch %s to %s: %w", branch, remote, err)
+	}
+	return nil
+}
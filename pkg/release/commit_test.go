@@ -0,0 +1,74 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestCommitFiles(t *testing.T) {
+	t.Setenv("GIT_COMMITTER_NAME", "Release Bot")
+	t.Setenv("GIT_COMMITTER_EMAIL", "release@example.com")
+
+	tempDir, repo := initTestRepo(t)
+	parent := commitTestFile(t, repo, tempDir, "Initial commit")
+
+	if err := os.WriteFile(filepath.Join(tempDir, "CHANGELOG.md"), []byte("# Changelog\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	hash, err := CommitFiles(repo, "chore(release): v1.0.0", "CHANGELOG.md")
+	if err != nil {
+		t.Fatalf("CommitFiles failed: %v", err)
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatalf("Failed to get commit: %v", err)
+	}
+	if commit.Message != "chore(release): v1.0.0" {
+		t.Errorf("Message = %q, want %q", commit.Message, "chore(release): v1.0.0")
+	}
+	if len(commit.ParentHashes) != 1 || commit.ParentHashes[0] != parent {
+		t.Errorf("Parents = %v, want [%s]", commit.ParentHashes, parent)
+	}
+	if commit.Author.Name != "Release Bot" || commit.Committer.Email != "release@example.com" {
+		t.Errorf("Author = %s, Committer = %s, want Release Bot <release@example.com>", commit.Author, commit.Committer)
+	}
+	if _, err := commit.File("CHANGELOG.md"); err != nil {
+		t.Errorf("CHANGELOG.md not committed: %v", err)
+	}
+}
+
+func TestPushBranch(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "Initial commit")
+
+	remoteDir := t.TempDir()
+	remote, err := git.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatalf("Failed to init remote: %v", err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: DefaultRemote, URLs: []string{remoteDir}}); err != nil {
+		t.Fatalf("Failed to create remote: %v", err)
+	}
+
+	if err := PushBranch(repo, DefaultRemote, "master", nil); err != nil {
+		t.Fatalf("PushBranch failed: %v", err)
+	}
+	ref, err := remote.Reference(plumbing.NewBranchReferenceName("master"), true)
+	if err != nil {
+		t.Fatalf("Branch not found in remote: %v", err)
+	}
+	if ref.Hash() != head {
+		t.Errorf("Remote branch = %s, want %s", ref.Hash(), head)
+	}
+
+	// Pushing again is a no-op
+	if err := PushBranch(repo, DefaultRemote, "master", nil); err != nil {
+		t.Errorf("PushBranch of up-to-date branch failed: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package release
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/fxsml/gitversion/pkg/version"
+)
+
+func TestCreateSignedTag(t *testing.T) {
+	t.Setenv("GIT_COMMITTER_NAME", "Release Bot")
+	t.Setenv("GIT_COMMITTER_EMAIL", "release@example.com")
+	keyDir := t.TempDir()
+
+	// SSH key with an allowed_signers file
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("secret"))
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	sshKey := filepath.Join(keyDir, "id_ed25519")
+	if err := os.WriteFile(sshKey, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("Failed to convert key: %v", err)
+	}
+	allowedSigners := filepath.Join(keyDir, "allowed_signers")
+	if err := os.WriteFile(allowedSigners, append([]byte("release@example.com "), ssh.MarshalAuthorizedKey(sshPub)...), 0644); err != nil {
+		t.Fatalf("Failed to write allowed signers: %v", err)
+	}
+
+	// OpenPGP key with a public keyring
+	entity, err := openpgp.NewEntity("Release Bot", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	pgpKey := filepath.Join(keyDir, "private.asc")
+	keyring := filepath.Join(keyDir, "keyring.asc")
+	for path, serialize := range map[string]func(*bytes.Buffer) error{
+		pgpKey: func(b *bytes.Buffer) error {
+			w, err := armor.Encode(b, openpgp.PrivateKeyType, nil)
+			if err != nil {
+				return err
+			}
+			if err := entity.SerializePrivate(w, nil); err != nil {
+				return err
+			}
+			return w.Close()
+		},
+		keyring: func(b *bytes.Buffer) error {
+			w, err := armor.Encode(b, openpgp.PublicKeyType, nil)
+			if err != nil {
+				return err
+			}
+			if err := entity.Serialize(w); err != nil {
+				return err
+			}
+			return w.Close()
+		},
+	} {
+		var b bytes.Buffer
+		if err := serialize(&b); err != nil {
+			t.Fatalf("Failed to serialize key: %v", err)
+		}
+		if err := os.WriteFile(path, b.Bytes(), 0600); err != nil {
+			t.Fatalf("Failed to write key: %v", err)
+		}
+	}
+
+	for _, tt := range []struct {
+		name    string
+		key     string
+		signers string
+	}{
+		{"ssh", sshKey, allowedSigners},
+		{"openpgp", pgpKey, keyring},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir, repo := initTestRepo(t)
+			head := commitTestFile(t, repo, tempDir, "Initial commit")
+
+			signer, err := LoadSigner(tt.key, "secret")
+			if err != nil {
+				t.Fatalf("LoadSigner failed: %v", err)
+			}
+			if _, err := CreateSignedTag(repo, "v1.0.0", head, "Release v1.0.0", signer); err != nil {
+				t.Fatalf("CreateSignedTag failed: %v", err)
+			}
+			if _, err := CreateSignedTag(repo, "v1.0.0", head, "Again", signer); err == nil {
+				t.Error("CreateSignedTag for existing tag expected error")
+			}
+
+			info, err := version.GetVersionInfo(tempDir, "", version.WithVerifySignatures(tt.signers))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.LatestTag != "v1.0.0" || !info.TagSigned || info.TagMessage != "Release v1.0.0" {
+				t.Errorf("Tag %s signed = %v with message %q", info.LatestTag, info.TagSigned, info.TagMessage)
+			}
+		})
+	}
+
+	if _, err := LoadSigner(sshKey, "wrong"); err == nil {
+		t.Error("LoadSigner with wrong passphrase expected error")
+	}
+}
@@ -0,0 +1,99 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// DefaultRemote is the remote tags are pushed to unless specified otherwise
+const DefaultRemote = "origin"
+
+// CreateTag creates an annotated tag with the given message pointing at the commit
+func CreateTag(repo *git.Repository, name string, hash plumbing.Hash, message string) (*plumbing.Reference, error) {
+	tagger, err := Tagger(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := repo.CreateTag(name, hash, &git.CreateTagOptions{
+		Tagger:  tagger,
+		Message: message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+	return ref, nil
+}
+
+// PushTag pushes the tag to the given remote with the given authentication,
+// nil for the default of the transport. A tag of the same name pointing
+// elsewhere on the remote is never replaced; pushing fails instead.
+func PushTag(repo *git.Repository, remote string, name string, auth transport.AuthMethod) error {
+	refName := plumbing.NewTagReferenceName(name)
+	local, err := repo.Reference(refName, false)
+	if err != nil {
+		return fmt.Errorf("failed to find tag %s: %w", name, err)
+	}
+
+	r, err := repo.Remote(remote)
+	if err != nil {
+		return fmt.Errorf("failed to find remote %s: %w", remote, err)
+	}
+	refs, err := r.List(&git.ListOptions{Auth: auth})
+	if err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return fmt.Errorf("failed to list refs of %s: %w", remote, err)
+	}
+	for _, ref := range refs {
+		if ref.Name() == refName && ref.Hash() != local.Hash() {
+			return fmt.Errorf("tag %s already exists on %s and points elsewhere, refusing to move it", name, remote)
+		}
+	}
+
+	err = r.Push(&git.PushOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(refName + ":" + refName)},
+		Auth:     auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push tag %s to %s: %w", name, remote, err)
+	}
+	return nil
+}
+
+// Tagger returns the signature used for created tags. Like git, it honors the
+// GIT_COMMITTER_NAME and GIT_COMMITTER_EMAIL environment variables and falls
+// back to user.name and user.email from the repository and global git config.
+func Tagger(repo *git.Repository) (*object.Signature, error) {
+	name := os.Getenv("GIT_COMMITTER_NAME")
+	email := os.Getenv("GIT_COMMITTER_EMAIL")
+
+	if name == "" || email == "" {
+		cfg, err := repo.ConfigScoped(config.GlobalScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read git config: %w", err)
+		}
+		if name == "" {
+			name = cfg.User.Name
+		}
+		if email == "" {
+			email = cfg.User.Email
+		}
+	}
+
+	if name == "" || email == "" {
+		return nil, fmt.Errorf("tagger identity unknown: configure user.name and user.email or set GIT_COMMITTER_NAME and GIT_COMMITTER_EMAIL")
+	}
+
+	return &object.Signature{
+		Name:  name,
+		Email: email,
+		When:  time.Now(),
+	}, nil
+}
@@ -0,0 +1,158 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCreateTag(t *testing.T) {
+	t.Setenv("GIT_COMMITTER_NAME", "Release Bot")
+	t.Setenv("GIT_COMMITTER_EMAIL", "release@example.com")
+
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "Initial commit")
+
+	ref, err := CreateTag(repo, "v1.0.0", head, "Release v1.0.0")
+	if err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+
+	tagObj, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("Created tag is not annotated: %v", err)
+	}
+	if tagObj.Target != head {
+		t.Errorf("Tag target = %s, want %s", tagObj.Target, head)
+	}
+	if tagObj.Message != "Release v1.0.0\n" {
+		t.Errorf("Tag message = %q, want %q", tagObj.Message, "Release v1.0.0\n")
+	}
+	if tagObj.Tagger.Name != "Release Bot" || tagObj.Tagger.Email != "release@example.com" {
+		t.Errorf("Tagger = %s <%s>, want Release Bot <release@example.com>", tagObj.Tagger.Name, tagObj.Tagger.Email)
+	}
+
+	if _, err := CreateTag(repo, "v1.0.0", head, "Again"); err == nil {
+		t.Error("CreateTag for existing tag expected error")
+	}
+}
+
+func TestPushTag(t *testing.T) {
+	t.Setenv("GIT_COMMITTER_NAME", "Release Bot")
+	t.Setenv("GIT_COMMITTER_EMAIL", "release@example.com")
+
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "Initial commit")
+
+	remoteDir, err := os.MkdirTemp("", "gitversion-test-remote-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(remoteDir)
+
+	remote, err := git.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatalf("Failed to init remote: %v", err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: DefaultRemote, URLs: []string{remoteDir}}); err != nil {
+		t.Fatalf("Failed to create remote: %v", err)
+	}
+
+	if _, err := CreateTag(repo, "v1.0.0", head, "Release v1.0.0"); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if err := PushTag(repo, DefaultRemote, "v1.0.0", nil); err != nil {
+		t.Fatalf("PushTag failed: %v", err)
+	}
+
+	if _, err := remote.Tag("v1.0.0"); err != nil {
+		t.Errorf("Tag not found in remote: %v", err)
+	}
+
+	// Pushing again is a no-op
+	if err := PushTag(repo, DefaultRemote, "v1.0.0", nil); err != nil {
+		t.Errorf("PushTag of up-to-date tag failed: %v", err)
+	}
+
+	// A tag that moved locally is not pushed over the remote one
+	if err := repo.DeleteTag("v1.0.0"); err != nil {
+		t.Fatalf("Failed to delete tag: %v", err)
+	}
+	next := commitTestFile(t, repo, tempDir, "Second commit")
+	if _, err := CreateTag(repo, "v1.0.0", next, "Release v1.0.0"); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if err := PushTag(repo, DefaultRemote, "v1.0.0", nil); err == nil {
+		t.Error("PushTag of moved tag expected error")
+	}
+	ref, err := remote.Tag("v1.0.0")
+	if err != nil {
+		t.Fatalf("Tag not found in remote: %v", err)
+	}
+	tag, err := remote.TagObject(ref.Hash())
+	if err != nil || tag.Target != head {
+		t.Errorf("Remote tag moved to %v", tag)
+	}
+}
+
+func TestTaggerMissingIdentity(t *testing.T) {
+	t.Setenv("GIT_COMMITTER_NAME", "")
+	t.Setenv("GIT_COMMITTER_EMAIL", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	_, repo := initTestRepo(t)
+	if _, err := Tagger(repo); err == nil {
+		t.Error("Tagger without identity expected error")
+	}
+}
+
+// initTestRepo creates a temporary Git repository that is removed when the test ends
+func initTestRepo(t *testing.T) (string, *git.Repository) {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "gitversion-test-release-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	return tempDir, repo
+}
+
+// commitTestFile writes test.txt with the message as content and commits it
+func commitTestFile(t *testing.T, repo *git.Repository, dir, message string) plumbing.Hash {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte(message), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add("test.txt"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	hash, err := w.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Test User",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash
+}
@@ -0,0 +1,282 @@
+// Package revwalk walks commit ranges like git rev-list, reading commits from
+// a commit-graph where possible
+package revwalk
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Count counts the commits reachable from hash but not from since for which
+// include returns true, like 'git rev-list --count since..hash'. A nil
+// include counts all commits without reading their objects. A zero since
+// counts the whole history. If firstParent is set, only the chain of first
+// parents is walked and since must be on it. The walk stops with the
+// context's error once it is done.
+func Count(ctx context.Context, nodes commitgraph.CommitNodeIndex, hash, since plumbing.Hash, firstParent bool, include func(*object.Commit) bool) (int, error) {
+	if firstParent {
+		node, err := nodes.Get(hash)
+		if err != nil {
+			return 0, err
+		}
+		count := 0
+		err = (&firstParentIter{nodes: nodes, next: node, stop: since}).ForEach(func(n commitgraph.CommitNode) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			ok, err := includes(n, include)
+			if ok {
+				count++
+			}
+			return err
+		})
+		return count, err
+	}
+
+	w, err := newRangeWalk(ctx, nodes, hash, since)
+	if err != nil {
+		return 0, err
+	}
+	if err := w.walk(include); err != nil {
+		return 0, err
+	}
+	return len(w.included()), nil
+}
+
+// Range returns the commits reachable from hash but not from since, newest
+// first, like 'git rev-list since..hash'. Commits of branches merged after
+// since was created are excluded if since reaches them, even if they were
+// forked before it. A zero since returns the whole history.
+func Range(ctx context.Context, nodes commitgraph.CommitNodeIndex, hash, since plumbing.Hash) ([]plumbing.Hash, error) {
+	w, err := newRangeWalk(ctx, nodes, hash, since)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.walk(nil); err != nil {
+		return nil, err
+	}
+	return w.included(), nil
+}
+
+// newRangeWalk returns a walk of since..hash
+func newRangeWalk(ctx context.Context, nodes commitgraph.CommitNodeIndex, hash, since plumbing.Hash) (*rangeWalk, error) {
+	w := &rangeWalk{ctx: ctx, nodes: nodes, state: make(map[plumbing.Hash]uint8)}
+	if err := w.mark(hash, false); err != nil {
+		return nil, err
+	}
+	if !since.IsZero() {
+		if err := w.mark(since, true); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// includes reports whether include returns true for the commit of the node,
+// or true if include is nil
+func includes(n commitgraph.CommitNode, include func(*object.Commit) bool) (bool, error) {
+	if include == nil {
+		return true, nil
+	}
+	commit, err := n.Commit()
+	if err != nil {
+		return false, err
+	}
+	return include(commit), nil
+}
+
+// rangeWalk flags
+const (
+	walkSeen uint8 = 1 << iota
+	walkUninteresting
+	walkDone
+	walkDoneUninteresting
+	walkIncluded
+)
+
+// rangeWalk walks the commits reachable from the interesting commits but not
+// from the uninteresting ones, newest first like git rev-list. Commits reached
+// from both sides are marked uninteresting and their flag is propagated to all
+// their ancestors, so that the walk can stop once only uninteresting commits
+// are left in the queue that are older than the walked interesting ones.
+// Commits of a commit-graph are walked by descending generation number, which
+// orders them exactly even if commit dates are skewed.
+type rangeWalk struct {
+	ctx         context.Context
+	nodes       commitgraph.CommitNodeIndex
+	queue       commitQueue
+	state       map[plumbing.Hash]uint8
+	interesting int
+	// walked are the walked interesting commits in walk order
+	walked []plumbing.Hash
+	// oldest is the committer date of the oldest walked interesting commit
+	oldest time.Time
+}
+
+// mark queues the commit as interesting or uninteresting unless already done
+func (w *rangeWalk) mark(hash plumbing.Hash, uninteresting bool) error {
+	f := w.state[hash]
+	if uninteresting && f&walkUninteresting != 0 || !uninteresting && f&walkSeen != 0 {
+		return nil
+	}
+
+	node, err := w.nodes.Get(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		// Missing parent, e.g. the boundary of a shallow clone
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	f |= walkSeen
+	if uninteresting {
+		f |= walkUninteresting
+	} else {
+		w.interesting++
+	}
+	w.state[hash] = f
+	heap.Push(&w.queue, queuedCommit{node: node, interesting: !uninteresting})
+	return nil
+}
+
+// walk walks until no interesting commits are left and flags those for which
+// include returns true. Uninteresting commits as new as a walked interesting one are walked
+// too, as they may still reach it, e.g. if commits share a timestamp.
+func (w *rangeWalk) walk(include func(*object.Commit) bool) error {
+	for w.interesting > 0 || len(w.queue) > 0 && !w.oldest.IsZero() && !w.queue[0].node.CommitTime().Before(w.oldest) {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		entry := heap.Pop(&w.queue).(queuedCommit)
+		if entry.interesting {
+			w.interesting--
+		}
+
+		n := entry.node
+		f := w.state[n.ID()]
+		uninteresting := f&walkUninteresting != 0
+		switch {
+		case uninteresting && f&walkDoneUninteresting == 0:
+			w.state[n.ID()] = f | walkDoneUninteresting
+		case !uninteresting && f&walkDone == 0:
+			f |= walkDone
+			if w.oldest.IsZero() || n.CommitTime().Before(w.oldest) {
+				w.oldest = n.CommitTime()
+			}
+			ok, err := includes(n, include)
+			if err != nil {
+				return err
+			}
+			if ok {
+				f |= walkIncluded
+			}
+			w.state[n.ID()] = f
+			w.walked = append(w.walked, n.ID())
+		default:
+			continue
+		}
+
+		for _, parent := range n.ParentHashes() {
+			if err := w.mark(parent, uninteresting); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// included returns the walked commits flagged by walk that turned out not to
+// be reachable from an uninteresting commit, in walk order
+func (w *rangeWalk) included() []plumbing.Hash {
+	var hashes []plumbing.Hash
+	for _, hash := range w.walked {
+		if f := w.state[hash]; f&walkIncluded != 0 && f&walkUninteresting == 0 {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// queuedCommit is an entry of commitQueue
+type queuedCommit struct {
+	node        commitgraph.CommitNode
+	interesting bool
+}
+
+// commitQueue is a heap of commits ordered by descending generation number,
+// then by descending committer date. Commits outside of a commit-graph have
+// the highest generation number, as they cannot be ancestors of its commits.
+type commitQueue []queuedCommit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	if gi, gj := q[i].node.Generation(), q[j].node.Generation(); gi != gj {
+		return gi > gj
+	}
+	return q[i].node.CommitTime().After(q[j].node.CommitTime())
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(queuedCommit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
+
+// firstParentIter walks a commit and its chain of first parents, like
+// git log --first-parent
+type firstParentIter struct {
+	nodes commitgraph.CommitNodeIndex
+	next  commitgraph.CommitNode
+	stop  plumbing.Hash
+}
+
+func (it *firstParentIter) Next() (commitgraph.CommitNode, error) {
+	if it.next == nil || it.next.ID() == it.stop {
+		return nil, io.EOF
+	}
+
+	current := it.next
+	it.next = nil
+	if parents := current.ParentHashes(); len(parents) > 0 {
+		parent, err := it.nodes.Get(parents[0])
+		if err != nil {
+			return nil, err
+		}
+		it.next = parent
+	}
+	return current, nil
+}
+
+func (it *firstParentIter) ForEach(cb func(commitgraph.CommitNode) error) error {
+	for {
+		node, err := it.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := cb(node); err != nil {
+			if errors.Is(err, storer.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (it *firstParentIter) Close() {
+	it.next = nil
+}
@@ -0,0 +1,135 @@
+package revwalk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// history creates commits in memory, one second apart
+type history struct {
+	t    *testing.T
+	repo *git.Repository
+	when time.Time
+}
+
+func newHistory(t *testing.T) *history {
+	t.Helper()
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	return &history{t: t, repo: repo, when: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// commit stores an empty commit with the given parents
+func (h *history) commit(msg string, parents ...plumbing.Hash) plumbing.Hash {
+	h.t.Helper()
+	h.when = h.when.Add(time.Second)
+	tree := &object.Tree{}
+	treeObj := h.repo.Storer.NewEncodedObject()
+	if err := tree.Encode(treeObj); err != nil {
+		h.t.Fatalf("Failed to encode tree: %v", err)
+	}
+	treeHash, err := h.repo.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		h.t.Fatalf("Failed to store tree: %v", err)
+	}
+	sig := object.Signature{Name: "Test User", Email: "test@example.com", When: h.when}
+	c := &object.Commit{Author: sig, Committer: sig, Message: msg, TreeHash: treeHash, ParentHashes: parents}
+	obj := h.repo.Storer.NewEncodedObject()
+	if err := c.Encode(obj); err != nil {
+		h.t.Fatalf("Failed to encode commit: %v", err)
+	}
+	hash, err := h.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		h.t.Fatalf("Failed to store commit: %v", err)
+	}
+	return hash
+}
+
+func TestFirstParentIter(t *testing.T) {
+	h := newHistory(t)
+	a := h.commit("A")
+	b := h.commit("B", a)
+	c := h.commit("C", a)
+	m := h.commit("M", b, c)
+
+	nodes := commitgraph.NewObjectCommitNodeIndex(h.repo.Storer)
+	node, err := nodes.Get(m)
+	if err != nil {
+		t.Fatalf("Failed to get commit: %v", err)
+	}
+	iter := &firstParentIter{nodes: nodes, next: node}
+	defer iter.Close()
+
+	var visited []plumbing.Hash
+	if err := iter.ForEach(func(n commitgraph.CommitNode) error {
+		visited = append(visited, n.ID())
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEach failed: %v", err)
+	}
+	assertHashes(t, visited, []plumbing.Hash{m, b, a})
+}
+
+func TestRange(t *testing.T) {
+	// A feature branch F forked from A before the tag at B and merged at M:
+	//
+	//	A - B (tag) - C - M
+	//	 \               /
+	//	  F ------------
+	h := newHistory(t)
+	a := h.commit("A")
+	f := h.commit("F", a)
+	b := h.commit("B", a)
+	c := h.commit("C", b)
+	m := h.commit("M", c, f)
+	nodes := commitgraph.NewObjectCommitNodeIndex(h.repo.Storer)
+
+	tests := []struct {
+		name     string
+		since    plumbing.Hash
+		expected []plumbing.Hash
+	}{
+		{"since tag", b, []plumbing.Hash{m, c, f}},
+		{"since merged branch", f, []plumbing.Hash{m, c, b}},
+		{"whole history", plumbing.ZeroHash, []plumbing.Hash{m, c, b, f, a}},
+		{"since head", m, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes, err := Range(context.Background(), nodes, m, tt.since)
+			if err != nil {
+				t.Fatalf("Range failed: %v", err)
+			}
+			assertHashes(t, hashes, tt.expected)
+
+			count, err := Count(context.Background(), nodes, m, tt.since, false, nil)
+			if err != nil {
+				t.Fatalf("Count failed: %v", err)
+			}
+			if count != len(tt.expected) {
+				t.Errorf("Count = %d, want %d", count, len(tt.expected))
+			}
+		})
+	}
+}
+
+func assertHashes(t *testing.T, got, want []plumbing.Hash) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d commits %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("commit %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Constraint is a version range, e.g. ">=1.4 <2" or "^1.4 || ~2.1.0". A
+// range is a list of comparators separated by spaces or commas that must all
+// be satisfied; ranges separated by || are alternatives. Comparators are:
+//   - =, !=, >, >=, <, <= followed by a version, e.g. >=1.4.0
+//   - a version, whose missing or x parts match any, e.g. 1.4 or 1.x
+//   - ~1.4.2: >=1.4.2 <1.5.0; ~1: >=1.0.0 <2.0.0
+//   - ^1.4.2: >=1.4.2 <2.0.0; ^0.4.2: >=0.4.2 <0.5.0
+//
+// Missing parts of a version in a comparison are 0, except that they round
+// up in > and <=: >1.4 is >=1.5.0 and <=1.4 is <1.5.0. Versions may have a
+// leading "v".
+type Constraint struct {
+	// IncludePrerelease lets prereleases satisfy a range by precedence alone.
+	// By default a prerelease only satisfies a range with a comparator of a
+	// prerelease of the same MAJOR.MINOR.PATCH, so that 2.0.0-rc.1 does not
+	// satisfy <2 and >=1.4 includes no prereleases of 1.5.0.
+	IncludePrerelease bool
+
+	s      string
+	ranges [][]comparator
+}
+
+// comparator compares versions with a version by an operator
+type comparator struct {
+	op string
+	v  Version
+}
+
+// operatorSpace matches the spaces between an operator and its version
+var operatorSpace = regexp.MustCompile(`(>=|<=|!=|==|[=<>~^])\s+`)
+
+// ParseConstraint parses a constraint like ">=1.4 <2"
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{s: s}
+	normalized := operatorSpace.ReplaceAllString(strings.ReplaceAll(s, ",", " "), "$1")
+	for _, r := range strings.Split(normalized, "||") {
+		fields := strings.Fields(r)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid constraint %q: empty range", s)
+		}
+		var cmps []comparator
+		for _, field := range fields {
+			parsed, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid constraint %q: %w", s, err)
+			}
+			cmps = append(cmps, parsed...)
+		}
+		c.ranges = append(c.ranges, cmps)
+	}
+	return c, nil
+}
+
+// String returns the constraint as it was parsed
+func (c *Constraint) String() string {
+	return c.s
+}
+
+// Check reports whether the version satisfies the constraint
+func (c *Constraint) Check(v Version) bool {
+	for _, cmps := range c.ranges {
+		if c.checkRange(cmps, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRange reports whether the version satisfies all comparators
+func (c *Constraint) checkRange(cmps []comparator, v Version) bool {
+	prereleaseAllowed := c.IncludePrerelease || v.Prerelease == ""
+	for _, cmp := range cmps {
+		if !cmp.check(v) {
+			return false
+		}
+		if cmp.v.Prerelease != "" && cmp.v.Major == v.Major && cmp.v.Minor == v.Minor && cmp.v.Patch == v.Patch {
+			prereleaseAllowed = true
+		}
+	}
+	return prereleaseAllowed
+}
+
+// check reports whether the version satisfies the comparator
+func (c comparator) check(v Version) bool {
+	n := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return n == 0
+	case "!=":
+		return n != 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	case "<":
+		return n < 0
+	default:
+		return n <= 0
+	}
+}
+
+// parseComparator parses a comparator into the primitive comparators it
+// stands for
+func parseComparator(s string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", "==", "=", ">", "<", "~", "^"} {
+		if strings.HasPrefix(s, prefix) {
+			op, s = prefix, s[len(prefix):]
+			break
+		}
+	}
+	if op == "==" {
+		op = "="
+	}
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// next returns the version after the range of the first n parts of v
+	next := func(n int) Version {
+		switch n {
+		case 0:
+			return Version{Major: v.Major + 1}
+		case 1:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	switch {
+	case parts == 0 && (op == "" || op == "=" || op == ">=" || op == "<=" || op == "~" || op == "^"):
+		return []comparator{{">=", Version{}}}, nil
+	case parts == 0:
+		// Nothing is greater or less than every version
+		return []comparator{{"<", Version{}}}, nil
+	case op == "" || op == "=":
+		if parts == 3 {
+			return []comparator{{"=", v}}, nil
+		}
+		return []comparator{{">=", v}, {"<", next(parts - 1)}}, nil
+	case op == "!=":
+		if parts < 3 {
+			return nil, fmt.Errorf("!=%s needs MAJOR.MINOR.PATCH", s)
+		}
+		return []comparator{{"!=", v}}, nil
+	case op == ">" && parts < 3:
+		return []comparator{{">=", next(parts - 1)}}, nil
+	case op == "<=" && parts < 3:
+		return []comparator{{"<", next(parts - 1)}}, nil
+	case op == "~":
+		if parts == 1 {
+			return []comparator{{">=", v}, {"<", next(0)}}, nil
+		}
+		return []comparator{{">=", v}, {"<", next(1)}}, nil
+	case op == "^":
+		// The first non-zero part must not change
+		switch {
+		case v.Major > 0 || parts == 1:
+			return []comparator{{">=", v}, {"<", next(0)}}, nil
+		case v.Minor > 0 || parts == 2:
+			return []comparator{{">=", v}, {"<", next(1)}}, nil
+		}
+		return []comparator{{">=", v}, {"<", next(2)}}, nil
+	}
+	return []comparator{{op, v}}, nil
+}
+
+// parsePartial parses a version whose minor and patch may be missing or x,
+// X or *, and returns the number of given parts. Only full versions may
+// have a prerelease or build metadata.
+func parsePartial(s string) (Version, int, error) {
+	if strings.ContainsAny(s, "-+") {
+		v, err := Parse(s)
+		return v, 3, err
+	}
+	fields := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(fields) > 3 {
+		return Version{}, 0, fmt.Errorf("invalid version %q", s)
+	}
+	var nums [3]uint64
+	parts := 0
+	for i, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			continue
+		}
+		if parts < i {
+			return Version{}, 0, fmt.Errorf("invalid version %q: a number follows a wildcard", s)
+		}
+		n, err := parseNumeric(field)
+		if err != nil {
+			return Version{}, 0, fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		nums[i] = n
+		parts++
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, parts, nil
+}
@@ -0,0 +1,77 @@
+package semver
+
+import "testing"
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{">=1.4 <2", "1.4.0", true},
+		{">=1.4 <2", "v1.9.9", true},
+		{">=1.4 <2", "1.3.9", false},
+		{">=1.4 <2", "2.0.0", false},
+		{">=1.4 <2", "2.0.0-rc.1", false},
+		{">=1.4 <2", "1.5.0-rc.1", false},
+		{">= 1.4, < 2", "1.5.0", true},
+		{"1.x", "1.7.3", true},
+		{"1.x", "2.0.0", false},
+		{"1.4", "1.4.9", true},
+		{"1.4.2", "1.4.2+build.1", true},
+		{"=1.4.2", "1.4.3", false},
+		{"!=1.4.2", "1.4.3", true},
+		{"!=1.4.2", "1.4.2", false},
+		{">1.4", "1.4.9", false},
+		{">1.4", "1.5.0", true},
+		{"<=1.4", "1.4.9", true},
+		{"<=1.4", "1.5.0", false},
+		{"~1.4.2", "1.4.9", true},
+		{"~1.4.2", "1.5.0", false},
+		{"~1", "1.9.0", true},
+		{"^1.4.2", "1.9.0", true},
+		{"^1.4.2", "1.4.1", false},
+		{"^1.4.2", "2.0.0", false},
+		{"^0.4.2", "0.4.9", true},
+		{"^0.4.2", "0.5.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"^1.4 || ~2.1.0", "2.1.5", true},
+		{"^1.4 || ~2.1.0", "2.2.0", false},
+		{"*", "3.0.0", true},
+		{"*", "3.0.0-rc.1", false},
+		{">=2.0.0-rc.1", "2.0.0-rc.2", true},
+		{">=2.0.0-rc.1", "2.1.0-rc.1", false},
+		{">=2.0.0-rc.1", "2.1.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint failed: %v", err)
+			}
+			v, err := Parse(tt.version)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := c.Check(v); got != tt.expected {
+				t.Errorf("Check(%s) = %v, want %v", tt.version, got, tt.expected)
+			}
+		})
+	}
+
+	c, err := ParseConstraint("<2")
+	if err != nil {
+		t.Fatalf("ParseConstraint failed: %v", err)
+	}
+	c.IncludePrerelease = true
+	if !c.Check(Version{Major: 2, Prerelease: "rc.1"}) {
+		t.Error("Check(2.0.0-rc.1) = false with IncludePrerelease, want true")
+	}
+
+	for _, s := range []string{"", "||", ">=", "1.2.3.4", "1.x.3", "!=1.4", "01.2", "1.2.3-", ">=a"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) expected error", s)
+		}
+	}
+}
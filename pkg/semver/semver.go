@@ -0,0 +1,238 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed Semantic Version 2.0.0 (https://semver.org)
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string
+	Build      string
+}
+
+// Bump is the kind of version increment to apply
+type Bump int
+
+const (
+	// None keeps the version unchanged
+	None Bump = iota
+	// Patch increments the patch version
+	Patch
+	// Minor increments the minor version and resets patch
+	Minor
+	// Major increments the major version and resets minor and patch
+	Major
+)
+
+// String returns the lower-case name of the bump
+func (b Bump) String() string {
+	switch b {
+	case Patch:
+		return "patch"
+	case Minor:
+		return "minor"
+	case Major:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// ParseBump parses a bump name (none, patch, minor or major)
+func ParseBump(s string) (Bump, error) {
+	switch strings.ToLower(s) {
+	case "none":
+		return None, nil
+	case "patch":
+		return Patch, nil
+	case "minor":
+		return Minor, nil
+	case "major":
+		return Major, nil
+	default:
+		return None, fmt.Errorf("invalid bump %q: must be one of none, patch, minor, major", s)
+	}
+}
+
+// Parse parses a version string, accepting an optional leading "v"
+func Parse(s string) (Version, error) {
+	var v Version
+	str := strings.TrimPrefix(s, "v")
+
+	if idx := strings.Index(str, "+"); idx >= 0 {
+		v.Build = str[idx+1:]
+		str = str[:idx]
+		if err := validateIdentifiers(v.Build, false); err != nil {
+			return Version{}, fmt.Errorf("invalid semver %q: build metadata: %w", s, err)
+		}
+	}
+	if idx := strings.Index(str, "-"); idx >= 0 {
+		v.Prerelease = str[idx+1:]
+		str = str[:idx]
+		if err := validateIdentifiers(v.Prerelease, true); err != nil {
+			return Version{}, fmt.Errorf("invalid semver %q: prerelease: %w", s, err)
+		}
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid semver %q: expected MAJOR.MINOR.PATCH", s)
+	}
+	nums := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := parseNumeric(part)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid semver %q: %w", s, err)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+
+	return v, nil
+}
+
+// parseNumeric parses a numeric identifier without leading zeros
+func parseNumeric(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty numeric identifier")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("numeric identifier %q has leading zero", s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid numeric identifier %q", s)
+	}
+	return n, nil
+}
+
+// validateIdentifiers checks dot-separated prerelease or build identifiers
+func validateIdentifiers(s string, prerelease bool) error {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return fmt.Errorf("empty identifier")
+		}
+		numeric := true
+		for _, r := range id {
+			switch {
+			case r >= '0' && r <= '9':
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+				numeric = false
+			default:
+				return fmt.Errorf("invalid character %q in identifier %q", r, id)
+			}
+		}
+		if prerelease && numeric && len(id) > 1 && id[0] == '0' {
+			return fmt.Errorf("numeric identifier %q has leading zero", id)
+		}
+	}
+	return nil
+}
+
+// String returns the version without a "v" prefix
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Bump returns the version incremented by b. A prerelease version is
+// released rather than incremented when it is already ahead of the bump,
+// e.g. 1.3.0-rc.1 bumped by minor yields 1.3.0. Build metadata is dropped.
+func (v Version) Bump(b Bump) Version {
+	pre := v.Prerelease != ""
+	next := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch b {
+	case Patch:
+		if !pre {
+			next.Patch++
+		}
+	case Minor:
+		if !pre || next.Patch != 0 {
+			next.Minor++
+			next.Patch = 0
+		}
+	case Major:
+		if !pre || next.Minor != 0 || next.Patch != 0 {
+			next.Major++
+			next.Minor = 0
+			next.Patch = 0
+		}
+	default:
+		return v
+	}
+	return next
+}
+
+// Compare returns -1, 0 or 1 depending on whether v has lower, equal or
+// higher precedence than o. Build metadata is ignored.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePrerelease compares prerelease strings by semver precedence rules;
+// a version without prerelease has higher precedence
+func comparePrerelease(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.ParseUint(aIDs[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bIDs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareUint(aNum, bNum); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			// Numeric identifiers have lower precedence than alphanumeric
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareUint(uint64(len(aIDs)), uint64(len(bIDs)))
+}
@@ -0,0 +1,169 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Version
+		wantErr  bool
+	}{
+		{
+			name:     "plain version",
+			input:    "1.2.3",
+			expected: Version{Major: 1, Minor: 2, Patch: 3},
+		},
+		{
+			name:     "v prefix",
+			input:    "v1.2.3",
+			expected: Version{Major: 1, Minor: 2, Patch: 3},
+		},
+		{
+			name:     "prerelease and build",
+			input:    "1.0.0-rc.1+build.5",
+			expected: Version{Major: 1, Minor: 0, Patch: 0, Prerelease: "rc.1", Build: "build.5"},
+		},
+		{
+			name:     "build with leading zero",
+			input:    "1.0.0+001",
+			expected: Version{Major: 1, Build: "001"},
+		},
+		{
+			name:    "missing patch",
+			input:   "1.2",
+			wantErr: true,
+		},
+		{
+			name:    "leading zero",
+			input:   "01.2.3",
+			wantErr: true,
+		},
+		{
+			name:    "prerelease leading zero",
+			input:   "1.2.3-01",
+			wantErr: true,
+		},
+		{
+			name:    "empty prerelease identifier",
+			input:   "1.2.3-rc..1",
+			wantErr: true,
+		},
+		{
+			name:    "not a version",
+			input:   "release-foo",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse(%q) expected error, got %v", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta.2", Build: "g1234567"}
+	if result := v.String(); result != "1.2.3-beta.2+g1234567" {
+		t.Errorf("String() = %q, want %q", result, "1.2.3-beta.2+g1234567")
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		input    string
+		bump     Bump
+		expected string
+	}{
+		{"1.2.3", None, "1.2.3"},
+		{"1.2.3", Patch, "1.2.4"},
+		{"1.2.3", Minor, "1.3.0"},
+		{"1.2.3", Major, "2.0.0"},
+		{"1.2.3+build", Patch, "1.2.4"},
+		{"1.2.3-rc.1", Patch, "1.2.3"},
+		{"1.3.0-rc.1", Minor, "1.3.0"},
+		{"1.2.3-rc.1", Minor, "1.3.0"},
+		{"2.0.0-rc.1", Major, "2.0.0"},
+		{"1.2.0-rc.1", Major, "2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+"/"+tt.bump.String(), func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if result := v.Bump(tt.bump).String(); result != tt.expected {
+				t.Errorf("Bump(%s) = %q, want %q", tt.bump, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Ordered by increasing precedence, as in the semver specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := Parse(ordered[i])
+			b, _ := Parse(ordered[j])
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			if result := a.Compare(b); result != expected {
+				t.Errorf("Compare(%q, %q) = %d, want %d", ordered[i], ordered[j], result, expected)
+			}
+		}
+	}
+
+	a, _ := Parse("1.0.0+build.1")
+	b, _ := Parse("1.0.0+build.2")
+	if result := a.Compare(b); result != 0 {
+		t.Errorf("Compare() should ignore build metadata, got %d", result)
+	}
+}
+
+func TestParseBump(t *testing.T) {
+	for _, name := range []string{"none", "patch", "minor", "major"} {
+		b, err := ParseBump(name)
+		if err != nil {
+			t.Fatalf("ParseBump(%q) failed: %v", name, err)
+		}
+		if b.String() != name {
+			t.Errorf("ParseBump(%q).String() = %q", name, b.String())
+		}
+	}
+
+	if _, err := ParseBump("huge"); err == nil {
+		t.Error("ParseBump(\"huge\") expected error")
+	}
+}
@@ -0,0 +1,130 @@
+package version
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const (
+	// AbbrevAuto abbreviates commit hashes to the shortest unique prefix of at
+	// least 7 characters
+	AbbrevAuto = -1
+
+	// defaultAbbrev is the abbreviation used if neither WithAbbrev nor
+	// core.abbrev is set
+	defaultAbbrev = 7
+	// minAbbrev and maxAbbrev are the bounds of fixed abbreviations, like git
+	minAbbrev = 4
+	maxAbbrev = 40
+)
+
+// ParseAbbrev parses an abbreviation like core.abbrev: "auto", "no" for full
+// hashes or a length from 4 to 40
+func ParseAbbrev(s string) (int, error) {
+	switch s {
+	case "auto":
+		return AbbrevAuto, nil
+	case "no":
+		return maxAbbrev, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < minAbbrev || n > maxAbbrev {
+		return 0, fmt.Errorf("invalid abbreviation %q: must be auto, no or a length from %d to %d", s, minAbbrev, maxAbbrev)
+	}
+	return n, nil
+}
+
+// configAbbrev returns the abbreviation of the option clamped to the valid
+// lengths, else of core.abbrev, else the default
+func configAbbrev(abbrev int, coreAbbrev string) int {
+	switch {
+	case abbrev == AbbrevAuto:
+		return AbbrevAuto
+	case abbrev > maxAbbrev:
+		return maxAbbrev
+	case abbrev > 0:
+		return max(abbrev, minAbbrev)
+	}
+	if n, err := ParseAbbrev(coreAbbrev); err == nil {
+		return n
+	}
+	return defaultAbbrev
+}
+
+// repoAbbrev resolves the abbreviation for a go-git repository, with
+// core.abbrev of the repository, global or system git config
+func repoAbbrev(repo *git.Repository, abbrev int) int {
+	if abbrev != 0 {
+		return configAbbrev(abbrev, "")
+	}
+	coreAbbrev, _ := ReadGitConfig(repo).Get("core.abbrev")
+	return configAbbrev(abbrev, coreAbbrev)
+}
+
+// abbreviate shortens the hash to the given length, or for AbbrevAuto to the
+// shortest prefix of at least 7 characters no other object starts with
+func abbreviate(repo *git.Repository, hash plumbing.Hash, length int) string {
+	full := hash.String()
+	if length != AbbrevAuto {
+		return full[:length]
+	}
+
+	// Objects sharing the first two bytes are the only candidates for a
+	// shared prefix of 7 or more characters
+	others := hashesWithPrefix(repo, hash[:2])
+	length = defaultAbbrev
+	for _, other := range others {
+		if other == hash {
+			continue
+		}
+		if n := commonHexPrefix(hash, other) + 1; n > length {
+			length = n
+		}
+	}
+	if length > maxAbbrev {
+		length = maxAbbrev
+	}
+	return full[:length]
+}
+
+// hashesWithPrefix returns the hashes of all objects starting with prefix
+func hashesWithPrefix(repo *git.Repository, prefix []byte) []plumbing.Hash {
+	// The filesystem storage looks them up in the pack indexes
+	type prefixLookup interface {
+		HashesWithPrefix(prefix []byte) ([]plumbing.Hash, error)
+	}
+	if s, ok := repo.Storer.(prefixLookup); ok {
+		if hashes, err := s.HashesWithPrefix(prefix); err == nil {
+			return hashes
+		}
+	}
+
+	var hashes []plumbing.Hash
+	iter, err := repo.Storer.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return nil
+	}
+	defer iter.Close()
+	_ = iter.ForEach(func(obj plumbing.EncodedObject) error {
+		if h := obj.Hash(); bytes.HasPrefix(h[:], prefix) {
+			hashes = append(hashes, h)
+		}
+		return nil
+	})
+	return hashes
+}
+
+// commonHexPrefix returns the number of leading hex characters a and b share
+func commonHexPrefix(a, b plumbing.Hash) int {
+	ha, hb := hex.EncodeToString(a[:]), hex.EncodeToString(b[:])
+	n := 0
+	for n < len(ha) && ha[n] == hb[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestParseAbbrev(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{input: "auto", expected: AbbrevAuto},
+		{input: "no", expected: 40},
+		{input: "12", expected: 12},
+		{input: "3", wantErr: true},
+		{input: "41", wantErr: true},
+		{input: "short", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := ParseAbbrev(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAbbrev(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if n != tt.expected {
+				t.Errorf("ParseAbbrev(%q) = %d, want %d", tt.input, n, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommonHexPrefix(t *testing.T) {
+	a := plumbing.NewHash("abcdef0123456789abcdef0123456789abcdef01")
+	b := plumbing.NewHash("abcdef0f23456789abcdef0123456789abcdef01")
+	if n := commonHexPrefix(a, b); n != 7 {
+		t.Errorf("commonHexPrefix = %d, want 7", n)
+	}
+	if n := commonHexPrefix(a, a); n != 40 {
+		t.Errorf("commonHexPrefix of equal hashes = %d, want 40", n)
+	}
+}
+
+func TestGetVersionInfoWithAbbrev(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		config   string
+		expected string
+	}{
+		{name: "default", expected: head.String()[:7]},
+		{name: "fixed", opts: []Option{WithAbbrev(12)}, expected: head.String()[:12]},
+		{name: "auto without collisions", opts: []Option{WithAbbrev(AbbrevAuto)}, expected: head.String()[:7]},
+		{name: "core.abbrev", config: "10", expected: head.String()[:10]},
+		{name: "option overrides core.abbrev", opts: []Option{WithAbbrev(8)}, config: "10", expected: head.String()[:8]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := repo.Config()
+			if err != nil {
+				t.Fatalf("Failed to read config: %v", err)
+			}
+			cfg.Raw.Section("core").SetOption("abbrev", tt.config)
+			if tt.config == "" {
+				cfg.Raw.Section("core").RemoveOption("abbrev")
+			}
+			if err := repo.SetConfig(cfg); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			for _, backend := range []Backend{GoGit(), GitCLI()} {
+				if backend.Name() == "git-cli" && !hasGit() {
+					continue
+				}
+				info, err := GetVersionInfo(tempDir, "", append(tt.opts, WithBackend(backend))...)
+				if err != nil {
+					t.Fatalf("GetVersionInfo with %s failed: %v", backend.Name(), err)
+				}
+				if info.GitCommitShort != tt.expected {
+					t.Errorf("%s: GitCommitShort = %q, want %q", backend.Name(), info.GitCommitShort, tt.expected)
+				}
+				if expected := "v1.0.0-1-g" + tt.expected; info.GitDescribe != expected {
+					t.Errorf("%s: GitDescribe = %q, want %q", backend.Name(), info.GitDescribe, expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ChangedPaths returns the slash-separated paths of the files changed on HEAD,
+// or the ref of WithRef, since its merge base with since, in sorted order.
+// As with git diff since...HEAD, changes on since after the merge base are
+// not included. Renamed files are listed with both paths.
+func ChangedPaths(repoPath, since string, opts ...Option) ([]string, error) {
+	return ChangedPathsContext(context.Background(), repoPath, since, opts...)
+}
+
+// ChangedPathsContext is like ChangedPaths but aborts comparing the trees
+// once the context is done
+func ChangedPathsContext(ctx context.Context, repoPath, since string, opts ...Option) ([]string, error) {
+	if since == "" {
+		return nil, errors.New("since ref is required")
+	}
+	o := newOptions(opts)
+	o.ctx = ctx
+
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	head, err := resolveHead(repo, o.ref)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	sinceHash, err := repo.ResolveRevision(plumbing.Revision(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref %q: %w", since, err)
+	}
+	sinceCommit, err := repo.CommitObject(*sinceHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit of %s: %w", since, err)
+	}
+
+	bases, err := sinceCommit.MergeBase(headCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base of %s: %w", since, err)
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%s and HEAD have no common history", since)
+	}
+	o.log().Debug("merge base", "since", since, "commit", bases[0].Hash.String())
+
+	from, err := bases[0].Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", bases[0].Hash, err)
+	}
+	to, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", headCommit.Hash, err)
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare trees: %w", err)
+	}
+
+	seen := make(map[string]bool, len(changes))
+	var paths []string
+	for _, c := range changes {
+		for _, name := range []string{c.From.Name, c.To.Name} {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				paths = append(paths, name)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// MatchPath reports whether the slash-separated path matches one of the
+// patterns of WithDirtyExcludes and WithSharedPaths: a pattern matches the
+// path itself, any path below it and, if it contains glob characters, a path
+// or base name it matches
+func MatchPath(name string, patterns ...string) bool {
+	return isExcluded(name, patterns)
+}
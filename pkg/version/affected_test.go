@@ -0,0 +1,68 @@
+package version
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestChangedPaths(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "a/a.txt", "a", "Add a")
+	base := commitTestFile(t, repo, tempDir, "b/b.txt", "b", "Add b")
+
+	// A commit on master after main branched off is not a change of main
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "c/c.txt", "c", "Add c")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/main", base)); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	if err := w.Checkout(&git.CheckoutOptions{Branch: "refs/heads/main"}); err != nil {
+		t.Fatalf("Failed to checkout: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "a/a.txt", "changed", "Change a")
+	commitTestFile(t, repo, tempDir, "d.txt", "d", "Add d")
+
+	paths, err := ChangedPaths(tempDir, "master")
+	if err != nil {
+		t.Fatalf("ChangedPaths failed: %v", err)
+	}
+	if want := []string{"a/a.txt", "d.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("ChangedPaths() = %v, want %v", paths, want)
+	}
+
+	paths, err = ChangedPaths(tempDir, "HEAD~1", WithRef("master"))
+	if err != nil {
+		t.Fatalf("ChangedPaths failed: %v", err)
+	}
+	if want := []string{"c/c.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("ChangedPaths() with ref = %v, want %v", paths, want)
+	}
+
+	if _, err := ChangedPaths(tempDir, "missing"); err == nil {
+		t.Error("ChangedPaths() with unknown ref expected error")
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		patterns []string
+		want     bool
+	}{
+		{"go.mod", []string{"go.mod"}, true},
+		{"libs/common/x.go", []string{"libs/common/"}, true},
+		{"libs/commonx/x.go", []string{"libs/common"}, false},
+		{"services/api/package.json", []string{"*.json"}, true},
+		{"README.md", nil, false},
+	} {
+		if got := MatchPath(tt.name, tt.patterns...); got != tt.want {
+			t.Errorf("MatchPath(%q, %v) = %v, want %v", tt.name, tt.patterns, got, tt.want)
+		}
+	}
+}
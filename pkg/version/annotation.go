@@ -0,0 +1,72 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// tagAnnotation is the annotation of an annotated tag
+type tagAnnotation struct {
+	// message is the tag message without signature and surrounding whitespace
+	message string
+	// tagger is the tagger as "Name <email>"
+	tagger string
+	// time is the tagger date
+	time time.Time
+}
+
+// annotation returns the annotation of the tag, or the zero annotation for a
+// lightweight tag
+func annotation(repo *git.Repository, name string) (tagAnnotation, error) {
+	ref, err := repo.Tag(name)
+	if err != nil {
+		return tagAnnotation{}, fmt.Errorf("failed to find tag %s: %w", name, err)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return tagAnnotation{}, nil
+	}
+	if err != nil {
+		return tagAnnotation{}, fmt.Errorf("failed to read tag %s: %w", name, err)
+	}
+	return tagAnnotation{
+		message: strings.TrimSpace(tag.Message),
+		tagger:  fmt.Sprintf("%s <%s>", tag.Tagger.Name, tag.Tagger.Email),
+		time:    tag.Tagger.When,
+	}, nil
+}
+
+// annotation returns the annotation of the tag like annotation
+func (g gitCommand) annotation(name string) (tagAnnotation, error) {
+	out, err := g.raw("for-each-ref", "--format=%(objecttype)%00%(taggername) %(taggeremail)%00%(taggerdate:unix)%00%(contents:signature)%00%(contents)", "refs/tags/"+name)
+	if err != nil {
+		return tagAnnotation{}, fmt.Errorf("failed to read tag %s: %w", name, err)
+	}
+	parts := strings.SplitN(out, "\x00", 5)
+	if len(parts) != 5 {
+		if out == "" {
+			return tagAnnotation{}, fmt.Errorf("failed to find tag %s", name)
+		}
+		return tagAnnotation{}, fmt.Errorf("failed to parse tag %s: %q", name, out)
+	}
+	if parts[0] != "tag" {
+		return tagAnnotation{}, nil
+	}
+	seconds, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return tagAnnotation{}, fmt.Errorf("failed to parse tag date %q: %w", parts[2], err)
+	}
+	// for-each-ref adds a newline after the format
+	contents := strings.TrimSuffix(parts[4], "\n")
+	return tagAnnotation{
+		message: strings.TrimSpace(strings.TrimSuffix(contents, parts[3])),
+		tagger:  parts[1],
+		time:    time.Unix(seconds, 0),
+	}, nil
+}
@@ -0,0 +1,66 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestGetVersionInfoTagAnnotation(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	second := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	tagger := testSignature()
+	ref, err := repo.CreateTag("v2.0.0", second, &git.CreateTagOptions{Tagger: tagger, Message: "Release v2.0.0\n\n- Breaking change\n"})
+	if err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read tag: %v", err)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", WithBackend(backend))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.TagMessage != "Release v2.0.0\n\n- Breaking change" {
+				t.Errorf("TagMessage = %q, want the annotation", info.TagMessage)
+			}
+			if info.Tagger != "Test User <test@example.com>" {
+				t.Errorf("Tagger = %q, want %q", info.Tagger, "Test User <test@example.com>")
+			}
+			if want := tag.Tagger.When.UTC().Format("2006-01-02T15:04:05Z"); info.TagTime != want {
+				t.Errorf("TagTime = %q, want %q", info.TagTime, want)
+			}
+
+			// A lightweight tag has no annotation
+			info, err = GetVersionInfo(tempDir, "", WithBackend(backend), WithRef(first.String()))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.LatestTag != "v1.0.0" || info.TagMessage != "" || info.Tagger != "" || info.TagTime != "" {
+				t.Errorf("annotation of lightweight tag %s = %q, %q, %q, want empty", info.LatestTag, info.TagMessage, info.Tagger, info.TagTime)
+			}
+		})
+	}
+
+	// The annotation is only read at the tag
+	commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+	info, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Tagger != "" {
+		t.Errorf("Tagger = %q after the tag, want empty", info.Tagger)
+	}
+}
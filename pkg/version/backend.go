@@ -0,0 +1,283 @@
+package version
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Backend reads the repository state versions are computed from
+type Backend interface {
+	// Name returns the name the backend is selected by, e.g. "git-cli"
+	Name() string
+	// inspect reads the state of the repository at the given path
+	inspect(repoPath string, o *options) (*repoState, error)
+	// checkWorktree checks the working tree for uncommitted changes and
+	// returns its dirty hash, if requested
+	checkWorktree(repoPath string, o *options) (bool, string, error)
+}
+
+// repoState is the repository state read by a backend
+type repoState struct {
+	// commit is the full hash of HEAD or the configured ref
+	commit string
+	// shortCommit is the abbreviated hash of commit
+	shortCommit string
+	// branch is the checked out branch, "HEAD" if detached
+	branch string
+	// commitTime is the committer date of commit
+	commitTime time.Time
+	// committerName is the committer name of commit
+	committerName string
+	// authorEmail is the author email of commit
+	authorEmail string
+	// subject is the subject of the commit message
+	subject string
+	// remoteURL is the URL of the origin remote, if configured
+	remoteURL string
+	// defaultBranch is the configured or detected default branch
+	defaultBranch string
+	// describe is the nearest tag of commit
+	describe Description
+	// annotation is the annotation of the tag describe found if it points
+	// to commit and is annotated
+	annotation tagAnnotation
+	// commitSigned and tagSigned report valid signatures of commit and the
+	// latest tag, if verified
+	commitSigned bool
+	tagSigned    bool
+	// dirty reports uncommitted changes, if checked
+	dirty bool
+	// shallow reports that the repository is a shallow clone
+	shallow bool
+	// dirtyHash is the hash of the dirty working tree, if requested
+	dirtyHash string
+	// superproject is the working tree of the superproject if the repository
+	// is a submodule, if requested
+	superproject string
+	// unborn reports that HEAD points to a branch without commits
+	unborn bool
+	// tags are the names of all tags, if WithPrereleaseLabels or
+	// WithMonotonic needs them
+	tags []string
+	// headTagCommit is the commit of the local tag HEAD is treated as tagged
+	// with by WithHeadTag or WithCI, if it exists
+	headTagCommit string
+}
+
+// setUnborn sets the state of a repository without commits on the given
+// branch: the commit is the zero hash and the commit time is now
+func (s *repoState) setUnborn(branch string, abbrev int) {
+	if abbrev == AbbrevAuto {
+		abbrev = defaultAbbrev
+	}
+	s.unborn = true
+	s.branch = branch
+	s.commit = plumbing.ZeroHash.String()
+	s.shortCommit = s.commit[:abbrev]
+	s.commitTime = time.Now()
+}
+
+// commitSubject returns the subject of a commit message like git's %s: the
+// first paragraph with its lines joined by spaces
+func commitSubject(message string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimLeft(message, "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
+
+// backends contains the built-in backends by name
+var backends = map[string]Backend{
+	"go-git":  GoGit(),
+	"git-cli": GitCLI(),
+}
+
+// BackendByName returns the built-in backend with the given name
+func BackendByName(name string) (Backend, error) {
+	b, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q: must be one of %s", name, strings.Join(BackendNames(), ", "))
+	}
+	return b, nil
+}
+
+// BackendNames returns the sorted names of the built-in backends
+func BackendNames() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GoGit returns the default pure-Go backend based on go-git
+func GoGit() Backend {
+	return goGit{}
+}
+
+type goGit struct{}
+
+func (goGit) Name() string { return "go-git" }
+
+func (goGit) inspect(repoPath string, o *options) (*repoState, error) {
+	stop := o.phase(PhaseOpen)
+	repo, err := o.openRepository(repoPath)
+	stop()
+	if err != nil {
+		return nil, err
+	}
+	if o.fetchRemote != "" {
+		if err := fetchTags(o.ctx, repo, o.fetchRemote); err != nil {
+			return nil, err
+		}
+	}
+	if o.tagCheckRemote != "" {
+		diff, err := compareRemoteTags(repo, o.tagCheckRemote, o)
+		if err != nil {
+			return nil, err
+		}
+		if err := diff.staleError(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Auto-detect default branch if not specified
+	s := &repoState{defaultBranch: o.defaultBranch, remoteURL: originURL(repo)}
+	if s.defaultBranch == "" {
+		s.defaultBranch = detectDefaultBranch(repo, o)
+	}
+
+	// A new repository has no commits yet; HEAD points to an unborn branch
+	if branch, ok := unbornBranch(repo); ok && o.ref == "" {
+		s.setUnborn(branch, repoAbbrev(repo, o.abbrev))
+		if o.dirtyCheck {
+			s.dirty, s.dirtyHash, err = worktreeState(o.ctx, repo, o)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+			}
+		}
+		return s, nil
+	}
+
+	// Get HEAD reference, or the configured ref
+	head, err := resolveHead(repo, o.ref)
+	if err != nil {
+		return nil, err
+	}
+	s.commit = head.Hash().String()
+	s.shortCommit = abbreviate(repo, head.Hash(), repoAbbrev(repo, o.abbrev))
+
+	// Get commit date, committer, author and subject
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	s.commitTime = commit.Committer.When
+	s.committerName = commit.Committer.Name
+	s.authorEmail = commit.Author.Email
+	s.subject = commitSubject(commit.Message)
+
+	// Get branch name
+	if head.Name().IsBranch() {
+		s.branch = head.Name().Short()
+	} else {
+		// Detached HEAD state
+		s.branch = "HEAD"
+	}
+
+	// Get git describe (tags)
+	s.describe, err = describe(repo, head.Hash(), o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe HEAD: %w", err)
+	}
+
+	// A shallow clone may lack the latest tag; deepen the history if enabled
+	s.shallow = len(shallowCommits(repo)) > 0
+	for depth := deepenStart; o.deepen && s.shallow && s.describe.Tag == "" && depth <= deepenMax; depth *= 2 {
+		grew, err := deepen(o.ctx, repo, o.deepenRemote(), depth)
+		if err != nil {
+			return nil, err
+		}
+		if !grew {
+			continue
+		}
+		s.describe, err = describe(repo, head.Hash(), o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe HEAD: %w", err)
+		}
+		s.shallow = len(shallowCommits(repo)) > 0
+	}
+
+	// List the tags prerelease labels are numbered after and versions must
+	// be higher than
+	if o.listsTags() {
+		tags, err := localTags(repo)
+		if err != nil {
+			return nil, err
+		}
+		for name := range tags {
+			s.tags = append(s.tags, name)
+		}
+		sort.Strings(s.tags)
+	}
+
+	// Resolve the local tag HEAD is treated as tagged with, if any
+	if tag := o.headTagName(); tag != "" {
+		if hash, err := repo.ResolveRevision(plumbing.Revision(plumbing.NewTagReferenceName(tag))); err == nil {
+			s.headTagCommit = hash.String()
+		}
+	}
+
+	// Read the annotation if HEAD is at an annotated tag
+	if s.describe.Tag != "" && s.describe.TagHash == head.Hash() {
+		s.annotation, err = annotation(repo, s.describe.Tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify the signatures of HEAD and the latest tag if requested
+	if o.signersFile != "" {
+		signers, err := loadSigners(o.signersFile)
+		if err != nil {
+			return nil, err
+		}
+		s.commitSigned, s.tagSigned, err = verifySignatures(repo, signers, commit, s.describe.Tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Check for uncommitted changes, which only apply to the checked out HEAD
+	if o.dirtyCheck && o.ref == "" {
+		s.dirty, s.dirtyHash, err = worktreeState(o.ctx, repo, o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+		}
+	}
+
+	if o.superproject {
+		s.superproject = superprojectPath(repo)
+	}
+	return s, nil
+}
+
+func (goGit) checkWorktree(repoPath string, o *options) (bool, string, error) {
+	stop := o.phase(PhaseOpen)
+	repo, err := o.openRepository(repoPath)
+	stop()
+	if err != nil {
+		return false, "", err
+	}
+	return worktreeState(o.ctx, repo, o)
+}
@@ -0,0 +1,286 @@
+package version
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// DefaultReleaseLabel is the prerelease label of release branch versions
+// unless the rule specifies one
+const DefaultReleaseLabel = "rc"
+
+// ReleaseBranch is a rule deriving the version from the name of matching
+// branches, e.g. release/1.4 becomes v1.4.0-rc.N
+type ReleaseBranch struct {
+	// Pattern is a glob the branch name must match, e.g. "release/*"; * does
+	// not match /
+	Pattern string
+	// Label is the prerelease label (default: DefaultReleaseLabel)
+	Label string
+}
+
+// DefaultReleaseBranches matches release/X.Y[.Z] and release-X.Y[.x] branches
+var DefaultReleaseBranches = []ReleaseBranch{
+	{Pattern: "release/*"},
+	{Pattern: "release-*"},
+}
+
+// matchReleaseBranch returns the first rule matching the branch whose name
+// contains a version
+func matchReleaseBranch(branch string, rules []ReleaseBranch) (ReleaseBranch, bool, error) {
+	if _, ok := parseBranchVersion(branch); !ok {
+		return ReleaseBranch{}, false, nil
+	}
+	for _, rule := range rules {
+		matched, err := path.Match(rule.Pattern, branch)
+		if err != nil {
+			return ReleaseBranch{}, false, fmt.Errorf("invalid release branch pattern %q: %w", rule.Pattern, err)
+		}
+		if matched {
+			if rule.Label == "" {
+				rule.Label = DefaultReleaseLabel
+			}
+			return rule, true, nil
+		}
+	}
+	return ReleaseBranch{}, false, nil
+}
+
+// Numbering schemes of prerelease labels
+const (
+	// NumberingTags numbers prereleases after the existing tags of the
+	// prerelease: v1.5.0-beta.12 after the tag v1.5.0-beta.11
+	NumberingTags = "tags"
+	// NumberingHeight numbers prereleases by the number of commits since the
+	// latest tag, added to its number if it is a tag of the prerelease, so
+	// that every commit gets a unique, ordered version without tagging
+	NumberingHeight = "height"
+)
+
+// PrereleaseLabel is a rule versioning matching branches as prereleases of
+// a label, e.g. develop as v1.5.0-beta.N. The label may contain the
+// placeholders of BranchTemplate, e.g. "alpha.{slug}" for feature branches.
+type PrereleaseLabel struct {
+	// Pattern is a glob the branch name must match, e.g. "feature/*"; * does
+	// not match /
+	Pattern string
+	// Label is the prerelease label, e.g. "beta"
+	Label string
+	// Numbering is NumberingTags (default) or NumberingHeight
+	Numbering string
+}
+
+// matchPrereleaseLabel returns the first rule whose pattern matches the branch
+func matchPrereleaseLabel(branch string, rules []PrereleaseLabel) (PrereleaseLabel, bool, error) {
+	for _, rule := range rules {
+		matched, err := path.Match(rule.Pattern, branch)
+		if err != nil {
+			return PrereleaseLabel{}, false, fmt.Errorf("invalid prerelease label pattern %q: %w", rule.Pattern, err)
+		}
+		if matched {
+			switch rule.Numbering {
+			case "":
+				rule.Numbering = NumberingTags
+			case NumberingTags, NumberingHeight:
+			default:
+				return PrereleaseLabel{}, false, fmt.Errorf("unknown numbering %q of prerelease label pattern %q: must be %s or %s", rule.Numbering, rule.Pattern, NumberingTags, NumberingHeight)
+			}
+			return rule, true, nil
+		}
+	}
+	return PrereleaseLabel{}, false, nil
+}
+
+// labelVersion returns the prerelease of the label of the version in the
+// branch name, else of the next minor version, numbered by the numbering
+// scheme. Tagged commits keep their tag.
+func (i *Info) labelVersion(label, numbering string) (string, error) {
+	if i.LatestTag != "" && i.CommitsSinceTag == 0 {
+		return i.describeVersion(), nil
+	}
+	current, vPrefix, err := i.latestSemVer()
+	next, ok := parseBranchVersion(i.GitBranch)
+	switch {
+	case ok && err != nil:
+		// The base version does not depend on the latest tag
+		vPrefix = i.tagPrefix == ""
+	case err != nil:
+		return "", err
+	case !ok:
+		next = current.Bump(semver.Minor)
+	}
+
+	var number uint64
+	if numbering == NumberingHeight {
+		number = uint64(i.CommitsSinceTag)
+		if n, ok := prereleaseNumberOf(current, next, label); ok && i.LatestTag != "" {
+			number += n
+		}
+	} else {
+		number = prereleaseNumber(i.tags, i.componentPrefix+i.tagPrefix, next, label) + 1
+	}
+	next.Prerelease = fmt.Sprintf("%s.%d", label, number)
+	if _, err := semver.Parse(next.String()); err != nil {
+		return "", fmt.Errorf("invalid prerelease label %q: %w", label, err)
+	}
+	return i.formatSemVer(next, vPrefix), nil
+}
+
+// prereleaseNumber returns the highest number of the tags named after the
+// prerelease of the label of version, e.g. 2 of v1.4.0-rc.2, or 0
+func prereleaseNumber(tags []string, prefix string, version semver.Version, label string) uint64 {
+	number := uint64(0)
+	for _, name := range tags {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		v, err := semver.Parse(strings.TrimPrefix(name, prefix))
+		if err != nil {
+			continue
+		}
+		if n, ok := prereleaseNumberOf(v, version, label); ok && n > number {
+			number = n
+		}
+	}
+	return number
+}
+
+// prereleaseNumberOf returns the number of v if it is a prerelease of the
+// label of version, e.g. 2 of 1.4.0-rc.2 for 1.4.0 and rc
+func prereleaseNumberOf(v, version semver.Version, label string) (uint64, bool) {
+	if v.Major != version.Major || v.Minor != version.Minor || v.Patch != version.Patch {
+		return 0, false
+	}
+	n, ok := strings.CutPrefix(v.Prerelease, label+".")
+	if !ok {
+		return 0, false
+	}
+	number, err := strconv.ParseUint(n, 10, 64)
+	return number, err == nil
+}
+
+// BranchTemplate is a rule rendering the version of matching branches from a
+// template with {placeholder} fields, e.g. "{slug}-g{sha}" or
+// "{base}-hotfix.{height}":
+//   - branch, slug: the branch name and its slug
+//   - sha, commit: the abbreviated and full commit hash
+//   - tag, describe: the latest tag and git describe, without component namespace
+//   - height: the number of commits since the latest tag
+//   - base: the version in the branch name, e.g. v1.2.4 on hotfix/1.2.4, else
+//     the latest tag without prerelease
+//   - next: the next patch version after the latest tag
+//
+// Use {{ and }} for literal braces.
+type BranchTemplate struct {
+	// Pattern is a glob the branch name must match, e.g. "feature/*"; * does
+	// not match /
+	Pattern string
+	// Template renders the version
+	Template string
+}
+
+// matchBranchTemplate returns the first template whose pattern matches the branch
+func matchBranchTemplate(branch string, templates []BranchTemplate) (BranchTemplate, bool, error) {
+	for _, t := range templates {
+		matched, err := path.Match(t.Pattern, branch)
+		if err != nil {
+			return BranchTemplate{}, false, fmt.Errorf("invalid branch pattern %q: %w", t.Pattern, err)
+		}
+		if matched {
+			return t, true, nil
+		}
+	}
+	return BranchTemplate{}, false, nil
+}
+
+// renderBranchTemplate renders the template with the fields of the info
+func (i *Info) renderBranchTemplate(tmpl string) (string, error) {
+	return expandTemplate(tmpl, func(name string) (string, error) {
+		switch name {
+		case "branch":
+			return i.GitBranch, nil
+		case "slug":
+			return i.GitBranchSlug, nil
+		case "sha":
+			return i.GitCommitShort, nil
+		case "commit":
+			return i.GitCommit, nil
+		case "tag":
+			return strings.TrimPrefix(i.LatestTag, i.componentPrefix), nil
+		case "describe":
+			return i.describeVersion(), nil
+		case "height":
+			return strconv.Itoa(i.CommitsSinceTag), nil
+		case "pr":
+			if i.PullRequestNumber == 0 {
+				return "", nil
+			}
+			return strconv.Itoa(i.PullRequestNumber), nil
+		case "base":
+			return i.baseVersion()
+		case "next":
+			current, vPrefix, err := i.latestSemVer()
+			if err != nil {
+				return "", err
+			}
+			return i.formatSemVer(current.Bump(semver.Patch), vPrefix), nil
+		default:
+			return "", fmt.Errorf("unknown placeholder {%s}", name)
+		}
+	})
+}
+
+// baseVersion returns the version in the branch name, else the latest tag
+// without prerelease and build metadata
+func (i *Info) baseVersion() (string, error) {
+	current, vPrefix, err := i.latestSemVer()
+	if v, ok := parseBranchVersion(i.GitBranch); ok {
+		if err != nil {
+			// The base version does not depend on the latest tag
+			vPrefix = i.tagPrefix == ""
+		}
+		return i.formatSemVer(v, vPrefix), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	current.Prerelease, current.Build = "", ""
+	return i.formatSemVer(current, vPrefix), nil
+}
+
+// expandTemplate replaces the {name} placeholders in tmpl with the values
+// returned by lookup. {{ and }} are literal braces.
+func expandTemplate(tmpl string, lookup func(name string) (string, error)) (string, error) {
+	var sb strings.Builder
+	for len(tmpl) > 0 {
+		switch {
+		case strings.HasPrefix(tmpl, "{{"):
+			sb.WriteByte('{')
+			tmpl = tmpl[2:]
+		case strings.HasPrefix(tmpl, "}}"):
+			sb.WriteByte('}')
+			tmpl = tmpl[2:]
+		case tmpl[0] == '{':
+			end := strings.IndexByte(tmpl, '}')
+			if end < 0 {
+				return "", fmt.Errorf("unclosed placeholder in template %q", tmpl)
+			}
+			value, err := lookup(strings.TrimSpace(tmpl[1:end]))
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+			tmpl = tmpl[end+1:]
+		case tmpl[0] == '}':
+			return "", fmt.Errorf("unexpected } in template %q", tmpl)
+		default:
+			sb.WriteByte(tmpl[0])
+			tmpl = tmpl[1:]
+		}
+	}
+	return sb.String(), nil
+}
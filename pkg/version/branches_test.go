@@ -0,0 +1,209 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestGetVersionInfoWithReleaseBranches(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.3.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	for _, branch := range []string{"release/1.4", "release-2.0.x", "release/next", "releases/1.5", "feature/x"} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head)); err != nil {
+			t.Fatalf("Failed to create branch: %v", err)
+		}
+	}
+	short := head.String()[:7]
+
+	tests := []struct {
+		name     string
+		ref      string
+		rules    []ReleaseBranch
+		expected string
+	}{
+		{"default rules slash", "release/1.4", nil, "v1.4.0-rc.1"},
+		{"default rules dash", "release-2.0.x", nil, "v2.0.0-rc.1"},
+		{"no version in name", "release/next", nil, "release-next-g" + short},
+		{"no match", "releases/1.5", nil, "releases-15-g" + short},
+		{"feature branch", "feature/x", nil, "feature-x-g" + short},
+		{"custom rule", "releases/1.5", []ReleaseBranch{{Pattern: "releases/*", Label: "beta"}}, "v1.5.0-beta.1"},
+		{"custom rule replaces defaults", "release/1.4", []ReleaseBranch{{Pattern: "releases/*"}}, "release-14-g" + short},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "master", WithRef(tt.ref), WithReleaseBranches(tt.rules...))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.Version != tt.expected {
+				t.Errorf("Version = %q, want %q", info.Version, tt.expected)
+			}
+		})
+	}
+
+	// Without WithReleaseBranches the strategy alone decides
+	info, err := GetVersionInfo(tempDir, "master", WithRef("release/1.4"))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if expected := "release-14-g" + short; info.Version != expected {
+		t.Errorf("Version = %q, want %q", info.Version, expected)
+	}
+
+	if _, err := GetVersionInfo(tempDir, "master", WithRef("release/1.4"), WithReleaseBranches(ReleaseBranch{Pattern: "["})); err == nil {
+		t.Error("GetVersionInfo should fail for an invalid pattern")
+	}
+}
+
+func TestBranchTemplates(t *testing.T) {
+	base := Info{
+		GitCommit:       "abc123def4567890",
+		GitCommitShort:  "abc123d",
+		GitDescribe:     "v1.2.3-5-gabc123d",
+		LatestTag:       "v1.2.3",
+		DefaultBranch:   "main",
+		CommitsSinceTag: 5,
+	}
+	templates := []BranchTemplate{
+		{Pattern: "feature/*", Template: "{slug}-g{sha}"},
+		{Pattern: "hotfix/*", Template: "{base}-hotfix.{height}"},
+		{Pattern: "support/*", Template: "{next}-{ branch }+{{{commit}}}"},
+		{Pattern: "main", Template: "{describe}"},
+		{Pattern: "tag/*", Template: "{tag}.{height}"},
+	}
+
+	tests := []struct {
+		branch   string
+		expected string
+	}{
+		{"feature/x", "feature-x-gabc123d"},
+		{"hotfix/1.2.4", "v1.2.4-hotfix.5"},
+		{"hotfix/urgent", "v1.2.3-hotfix.5"},
+		{"support/1.x", "v1.2.4-support/1.x+{abc123def4567890}"},
+		{"main", "v1.2.3-5-gabc123d"},
+		{"tag/x", "v1.2.3.5"},
+		{"develop", "develop-gabc123d"},
+	}
+
+	o := newOptions([]Option{WithBranchTemplates(templates...)})
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			info := base
+			info.GitBranch = tt.branch
+			info.GitBranchSlug = createBranchSlug(tt.branch)
+
+			result, err := o.version(&info)
+			if err != nil {
+				t.Fatalf("version() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("version() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	for _, tmpl := range []string{"{unknown}", "{slug", "slug}", "{base}"} {
+		o := newOptions([]Option{WithBranchTemplates(BranchTemplate{Pattern: "*", Template: tmpl})})
+		info := base
+		info.GitBranch = "x"
+		info.LatestTag = "not-semver"
+		if _, err := o.version(&info); err == nil {
+			t.Errorf("version() with template %q expected error", tmpl)
+		}
+	}
+}
+
+func TestGetVersionInfoWithPrereleaseLabels(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	for _, tag := range []string{"v1.4.0", "v1.5.0-beta.3", "v1.5.0-rc.1", "v1.6.0-beta.20"} {
+		if _, err := repo.CreateTag(tag, first, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+	second := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	if _, err := repo.CreateTag("v1.5.0-beta.11", second, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+	for branch, hash := range map[string]plumbing.Hash{"develop": head, "release/2.0": head, "feature/login": head, "other": head, "release/1.5": second} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), hash)); err != nil {
+			t.Fatalf("Failed to create branch: %v", err)
+		}
+	}
+	rules := []PrereleaseLabel{
+		{Pattern: "develop", Label: "beta"},
+		{Pattern: "release/*", Label: "rc"},
+		{Pattern: "feature/*", Label: "alpha.{slug}"},
+	}
+
+	heightRules := []PrereleaseLabel{
+		{Pattern: "develop", Label: "beta", Numbering: NumberingHeight},
+		{Pattern: "release/*", Label: "rc", Numbering: NumberingHeight},
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		rules    []PrereleaseLabel
+		expected string
+	}{
+		{"numbered after existing tags", "develop", rules, "v1.5.0-beta.12"},
+		{"version in branch name", "release/2.0", rules, "v2.0.0-rc.1"},
+		{"label template", "feature/login", rules, "v1.5.0-alpha.feature-login.1"},
+		{"no match", "other", rules, "other-g" + head.String()[:7]},
+		{"tagged commit", "release/1.5", rules, "v1.5.0-beta.11"},
+		{"height after tag of label", "develop", heightRules, "v1.5.0-beta.12"},
+		{"height since latest tag", "release/2.0", heightRules, "v2.0.0-rc.1"},
+		{"height tagged commit", "release/1.5", heightRules, "v1.5.0-beta.11"},
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.Name()+"/"+tt.name, func(t *testing.T) {
+				info, err := GetVersionInfo(tempDir, "master", WithBackend(backend), WithRef(tt.ref), WithPrereleaseLabels(tt.rules...))
+				if err != nil {
+					t.Fatalf("GetVersionInfo failed: %v", err)
+				}
+				if info.Version != tt.expected {
+					t.Errorf("Version = %q, want %q", info.Version, tt.expected)
+				}
+			})
+		}
+	}
+
+	if _, err := GetVersionInfo(tempDir, "master", WithRef("develop"), WithPrereleaseLabels(PrereleaseLabel{Pattern: "develop", Label: "beta_1"})); err == nil {
+		t.Error("GetVersionInfo should fail for an invalid label")
+	}
+	// Every commit gets its own number without tagging
+	next := commitTestFile(t, repo, tempDir, "test.txt", "v4", "Fourth commit")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("release/2.0"), next)); err != nil {
+		t.Fatalf("Failed to move branch: %v", err)
+	}
+	info, err := GetVersionInfo(tempDir, "master", WithRef("release/2.0"), WithPrereleaseLabels(heightRules...))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Version != "v2.0.0-rc.2" {
+		t.Errorf("Version = %q, want v2.0.0-rc.2", info.Version)
+	}
+
+	if _, err := GetVersionInfo(tempDir, "master", WithRef("develop"), WithPrereleaseLabels(PrereleaseLabel{Pattern: "develop", Label: "beta", Numbering: "count"})); err == nil {
+		t.Error("GetVersionInfo should fail for an unknown numbering")
+	}
+	if _, err := GetVersionInfo(tempDir, "master", WithRef("develop"), WithPrereleaseLabels(PrereleaseLabel{Pattern: "["})); err == nil {
+		t.Error("GetVersionInfo should fail for an invalid pattern")
+	}
+}
@@ -0,0 +1,75 @@
+package version
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// buildInfoVersion returns the version info of the running binary for
+// WithBuildInfoFallback, or the error of opening the repository if its build
+// info has neither a module version nor VCS settings
+func (o *options) buildInfoVersion(openErr error) (*Info, error) {
+	bi, ok := o.buildInfo()
+	if !ok {
+		return nil, openErr
+	}
+	abbrev := configAbbrev(o.abbrev, "")
+	if abbrev == AbbrevAuto {
+		abbrev = defaultAbbrev
+	}
+	info := infoFromBuildInfo(bi, abbrev)
+	if info == nil {
+		return nil, openErr
+	}
+	info.DefaultBranch = o.defaultBranch
+	return info, nil
+}
+
+// BuildInfo returns the version info of the running binary from the build
+// info the go command stamped into it, like WithBuildInfoFallback. It reports
+// false if the binary records neither a module version nor VCS settings.
+func BuildInfo() (*Info, bool) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, false
+	}
+	info := infoFromBuildInfo(bi, defaultAbbrev)
+	return info, info != nil
+}
+
+// infoFromBuildInfo returns the version info recorded in the build info: the
+// module version if the binary was built from a module, e.g. with go install
+// module@version, else a Go pseudo-version of the VCS revision. It returns nil
+// if neither is recorded.
+func infoFromBuildInfo(bi *debug.BuildInfo, abbrev int) *Info {
+	info := &Info{BuildTime: time.Now().UTC().Format("2006-01-02T15:04:05Z")}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.GitCommit = s.Value
+		case "vcs.time":
+			if t, err := time.Parse(time.RFC3339, s.Value); err == nil {
+				info.commitTime = t
+				info.CommitTime = t.UTC().Format("2006-01-02T15:04:05Z")
+			}
+		case "vcs.modified":
+			info.IsDirty = s.Value == "true"
+		}
+	}
+	info.HasCommits = info.GitCommit != ""
+	info.GitCommitShort = info.GitCommit[:min(abbrev, len(info.GitCommit))]
+
+	switch {
+	case bi.Main.Version != "" && bi.Main.Version != "(devel)":
+		// Since Go 1.24 this is also stamped from VCS, including +dirty
+		info.Version = bi.Main.Version
+	case len(info.GitCommit) >= goPseudoHashLength:
+		info.Version = "v0.0.0-" + info.goPseudoSuffix()
+		if info.IsDirty {
+			info.Version += "+dirty"
+		}
+	default:
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,109 @@
+package version
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+)
+
+func TestInfoFromBuildInfo(t *testing.T) {
+	vcs := []debug.BuildSetting{
+		{Key: "vcs", Value: "git"},
+		{Key: "vcs.revision", Value: "abcdef1234567890abcdef1234567890abcdef12"},
+		{Key: "vcs.time", Value: "2024-01-02T15:04:05Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	tests := []struct {
+		name       string
+		bi         debug.BuildInfo
+		version    string
+		commit     string
+		commitTime string
+		dirty      bool
+	}{
+		{
+			name:    "module version",
+			bi:      debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}},
+			version: "v1.2.3",
+		},
+		{
+			name:       "VCS settings of a devel build",
+			bi:         debug.BuildInfo{Main: debug.Module{Version: "(devel)"}, Settings: vcs},
+			version:    "v0.0.0-20240102150405-abcdef123456+dirty",
+			commit:     "abcdef1234567890abcdef1234567890abcdef12",
+			commitTime: "2024-01-02T15:04:05Z",
+			dirty:      true,
+		},
+		{
+			name:       "module version stamped from VCS",
+			bi:         debug.BuildInfo{Main: debug.Module{Version: "v1.2.4-0.20240102150405-abcdef123456+dirty"}, Settings: vcs},
+			version:    "v1.2.4-0.20240102150405-abcdef123456+dirty",
+			commit:     "abcdef1234567890abcdef1234567890abcdef12",
+			commitTime: "2024-01-02T15:04:05Z",
+			dirty:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := infoFromBuildInfo(&tt.bi, 7)
+			if info == nil {
+				t.Fatal("infoFromBuildInfo returned nil")
+			}
+			if info.Version != tt.version {
+				t.Errorf("Version = %q, want %q", info.Version, tt.version)
+			}
+			if info.GitCommit != tt.commit || info.CommitTime != tt.commitTime || info.IsDirty != tt.dirty {
+				t.Errorf("got commit %q, time %q, dirty %v", info.GitCommit, info.CommitTime, info.IsDirty)
+			}
+			if info.HasCommits != (tt.commit != "") {
+				t.Errorf("HasCommits = %v", info.HasCommits)
+			}
+			if tt.commit != "" && info.GitCommitShort != tt.commit[:7] {
+				t.Errorf("GitCommitShort = %q", info.GitCommitShort)
+			}
+		})
+	}
+
+	if info := infoFromBuildInfo(&debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, 7); info != nil {
+		t.Errorf("infoFromBuildInfo without version = %+v, want nil", info)
+	}
+}
+
+func TestGetVersionInfoBuildInfoFallback(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	_, err := GetVersionInfo(dir, "")
+	if !errors.Is(err, ErrNoRepository) {
+		t.Fatalf("GetVersionInfo outside a repository = %v, want ErrNoRepository", err)
+	}
+
+	fallback := func(o *options) {
+		o.buildInfo = func() (*debug.BuildInfo, bool) {
+			return &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}, true
+		}
+	}
+	info, err := GetVersionInfo(dir, "main", fallback)
+	if err != nil {
+		t.Fatalf("GetVersionInfo with fallback failed: %v", err)
+	}
+	if info.Version != "v1.2.3" || info.DefaultBranch != "main" {
+		t.Errorf("got version %q, default branch %q", info.Version, info.DefaultBranch)
+	}
+
+	// Binaries without build info keep the error
+	noBuildInfo := func(o *options) {
+		o.buildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	}
+	if _, err := GetVersionInfo(dir, "", noBuildInfo); !errors.Is(err, ErrNoRepository) {
+		t.Errorf("GetVersionInfo without build info = %v, want ErrNoRepository", err)
+	}
+
+	if hasGit() {
+		if _, err := GetVersionInfo(dir, "", WithBackend(GitCLI())); !errors.Is(err, ErrNoRepository) {
+			t.Errorf("git-cli outside a repository = %v, want ErrNoRepository", err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// cacheFormat is part of every cache key, so entries of other formats are
+// never read
+const cacheFormat = 1
+
+// cacheMaxAge is the age after which unused cache entries are pruned
+const cacheMaxAge = 7 * 24 * time.Hour
+
+// cacheFiles are the files of the git directory whose modification affects
+// the repository state besides the refs
+var cacheFiles = []string{"index", "config", "shallow"}
+
+// cachedState is the serialized repoState without the working tree state,
+// which is checked on every call
+type cachedState struct {
+	Commit        string      `json:"commit"`
+	ShortCommit   string      `json:"shortCommit"`
+	Branch        string      `json:"branch"`
+	CommitTime    time.Time   `json:"commitTime"`
+	CommitterName string      `json:"committerName"`
+	AuthorEmail   string      `json:"authorEmail"`
+	Subject       string      `json:"subject"`
+	RemoteURL     string      `json:"remoteURL"`
+	DefaultBranch string      `json:"defaultBranch"`
+	Describe      Description `json:"describe"`
+	Annotation    struct {
+		Message string    `json:"message"`
+		Tagger  string    `json:"tagger"`
+		Time    time.Time `json:"time"`
+	} `json:"annotation"`
+	CommitSigned  bool     `json:"commitSigned"`
+	TagSigned     bool     `json:"tagSigned"`
+	Shallow       bool     `json:"shallow"`
+	Superproject  string   `json:"superproject"`
+	Tags          []string `json:"tags,omitempty"`
+	HeadTagCommit string   `json:"headTagCommit,omitempty"`
+}
+
+// newCachedState returns the cacheable part of the state
+func newCachedState(s *repoState) cachedState {
+	c := cachedState{
+		Commit:        s.commit,
+		ShortCommit:   s.shortCommit,
+		Branch:        s.branch,
+		CommitTime:    s.commitTime,
+		CommitterName: s.committerName,
+		AuthorEmail:   s.authorEmail,
+		Subject:       s.subject,
+		RemoteURL:     s.remoteURL,
+		DefaultBranch: s.defaultBranch,
+		Describe:      s.describe,
+		CommitSigned:  s.commitSigned,
+		TagSigned:     s.tagSigned,
+		Shallow:       s.shallow,
+		Superproject:  s.superproject,
+		Tags:          s.tags,
+		HeadTagCommit: s.headTagCommit,
+	}
+	c.Annotation.Message, c.Annotation.Tagger, c.Annotation.Time = s.annotation.message, s.annotation.tagger, s.annotation.time
+	return c
+}
+
+// state restores the cached state
+func (c cachedState) state() *repoState {
+	s := &repoState{
+		commit:        c.Commit,
+		shortCommit:   c.ShortCommit,
+		branch:        c.Branch,
+		commitTime:    c.CommitTime,
+		committerName: c.CommitterName,
+		authorEmail:   c.AuthorEmail,
+		subject:       c.Subject,
+		remoteURL:     c.RemoteURL,
+		defaultBranch: c.DefaultBranch,
+		describe:      c.Describe,
+		commitSigned:  c.CommitSigned,
+		tagSigned:     c.TagSigned,
+		shallow:       c.Shallow,
+		superproject:  c.Superproject,
+		tags:          c.Tags,
+		headTagCommit: c.HeadTagCommit,
+	}
+	s.annotation = tagAnnotation{message: c.Annotation.Message, tagger: c.Annotation.Tagger, time: c.Annotation.Time}
+	return s
+}
+
+// inspect reads the repository state with the backend, or from the cache of
+// WithCache if HEAD, the refs, the index and the options are unchanged. The
+// working tree is always checked. Fetching options and the remote tag check
+// bypass the cache, unborn branches are not cached, and failures to read or
+// write it are ignored. Entries unused for cacheMaxAge are pruned on writes.
+func (o *options) inspect(repoPath string) (*repoState, error) {
+	if o.cacheDir == "" || o.fetchRemote != "" || o.deepen || o.tagCheckRemote != "" {
+		return o.backend.inspect(repoPath, o)
+	}
+	key, err := cacheKey(repoPath, o)
+	if err != nil {
+		return o.backend.inspect(repoPath, o)
+	}
+	path := filepath.Join(o.cacheDir, key+".json")
+	if data, err := os.ReadFile(path); err == nil {
+		var c cachedState
+		if json.Unmarshal(data, &c) == nil {
+			// Entries in use are kept from being pruned
+			now := time.Now()
+			os.Chtimes(path, now, now)
+			s := c.state()
+			if o.dirtyCheck && o.ref == "" {
+				s.dirty, s.dirtyHash, err = o.backend.checkWorktree(repoPath, o)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+				}
+			}
+			return s, nil
+		}
+	}
+
+	s, err := o.backend.inspect(repoPath, o)
+	if err != nil {
+		return nil, err
+	}
+	// The status check of the backend may have refreshed the index, so the
+	// entry is keyed by the state after it
+	if key, err := cacheKey(repoPath, o); err == nil && !s.unborn {
+		writeCache(filepath.Join(o.cacheDir, key+".json"), newCachedState(s))
+		pruneCache(o.cacheDir, time.Now().Add(-cacheMaxAge))
+	}
+	return s, nil
+}
+
+// cacheKey hashes everything the cached state depends on: HEAD, all refs,
+// the modification times of the index, config and shallow files, and the
+// options that affect the state
+func cacheKey(repoPath string, o *options) (string, error) {
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "format %d\nrepo %s\ngit %q %q\n", cacheFormat, abs, os.Getenv("GIT_DIR"), os.Getenv("GIT_WORK_TREE"))
+
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "head %s\n", head)
+	refs, err := repo.References()
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		lines = append(lines, ref.String())
+		return nil
+	})
+	if err != nil && err != storer.ErrStop {
+		return "", err
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintf(h, "ref %s\n", line)
+	}
+
+	if fs, ok := repo.Storer.(*filesystem.Storage); ok {
+		for _, name := range cacheFiles {
+			if fi, err := fs.Filesystem().Stat(name); err == nil {
+				fmt.Fprintf(h, "file %s %d %d\n", name, fi.ModTime().UnixNano(), fi.Size())
+			}
+		}
+	}
+
+	fmt.Fprintf(h, "options %s %q %q %q %q %v %d %q %d %q %v %v %v %q\n", o.backend.Name(), o.tagPrefix, o.tagExcludes,
+		o.componentPath, o.componentTagPrefix(), o.firstParent, o.maxDescribeDepth, o.ref, o.abbrev, o.defaultBranch, o.superproject,
+		o.remoteHEADTimeout > 0, o.listsTags(), o.headTagName())
+	if o.signersFile != "" {
+		fi, err := os.Stat(o.signersFile)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "signers %q %d %d\n", o.signersFile, fi.ModTime().UnixNano(), fi.Size())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeCache writes the state atomically, so that concurrent invocations
+// never read a partial entry
+func writeCache(path string, c cachedState) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(f.Name(), path) != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// pruneCache removes the entries and leftover temporary files of the cache
+// directory last modified before the given time
+func pruneCache(dir string, before time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (filepath.Ext(name) != ".json" && !strings.HasPrefix(name, ".tmp-")) {
+			continue
+		}
+		if fi, err := entry.Info(); err == nil && fi.ModTime().Before(before) {
+			os.Remove(filepath.Join(dir, name))
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// cacheEntries returns the number of entries in the cache directory
+func cacheEntries(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("Failed to list cache: %v", err)
+	}
+	return len(entries)
+}
+
+func TestGetVersionInfoCache(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			cacheDir := t.TempDir()
+			opts := []Option{WithBackend(backend), WithCache(cacheDir)}
+			want, err := GetVersionInfo(tempDir, "", opts...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if n := cacheEntries(t, cacheDir); n != 1 {
+				t.Fatalf("cache has %d entries, want 1", n)
+			}
+
+			got, err := GetVersionInfo(tempDir, "", opts...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if got.Version != want.Version || got.GitCommit != want.GitCommit || got.CommitTime != want.CommitTime || got.LatestTag != want.LatestTag {
+				t.Errorf("cached info = %s %s %s %s, want %s %s %s %s", got.Version, got.GitCommit, got.CommitTime, got.LatestTag,
+					want.Version, want.GitCommit, want.CommitTime, want.LatestTag)
+			}
+			if n := cacheEntries(t, cacheDir); n != 1 {
+				t.Errorf("cache has %d entries after a hit, want 1", n)
+			}
+
+			// Other options use other entries
+			if _, err := GetVersionInfo(tempDir, "", append(opts, WithTagPrefix("release/"))...); err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if n := cacheEntries(t, cacheDir); n != 2 {
+				t.Errorf("cache has %d entries after other options, want 2", n)
+			}
+
+			// The working tree is checked on cache hits
+			writeFile(t, filepath.Join(tempDir, "test.txt"), "changed")
+			got, err = GetVersionInfo(tempDir, "", opts...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if !got.IsDirty {
+				t.Error("cached info is not dirty after a change")
+			}
+			writeFile(t, filepath.Join(tempDir, "test.txt"), "v1")
+		})
+	}
+}
+
+func TestGetVersionInfoCacheInvalidation(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	cacheDir := t.TempDir()
+
+	version := func() *Info {
+		t.Helper()
+		info, err := GetVersionInfo(tempDir, "", WithCache(cacheDir))
+		if err != nil {
+			t.Fatalf("GetVersionInfo failed: %v", err)
+		}
+		return info
+	}
+
+	if info := version(); info.LatestTag != "" {
+		t.Fatalf("LatestTag = %q, want none", info.LatestTag)
+	}
+
+	// A new tag changes the refs
+	if _, err := repo.CreateTag("v1.0.0", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if info := version(); info.LatestTag != "v1.0.0" {
+		t.Errorf("LatestTag = %q after tagging, want v1.0.0", info.LatestTag)
+	}
+
+	// A new commit moves HEAD
+	next := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	if info := version(); info.GitCommit != next.String() {
+		t.Errorf("GitCommit = %s after committing, want %s", info.GitCommit, next)
+	}
+
+	// A tag replaced in place is detected as well
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.0.0"), next)); err != nil {
+		t.Fatalf("Failed to move tag: %v", err)
+	}
+	if info := version(); info.Version != "v1.0.0" {
+		t.Errorf("Version = %q after moving the tag, want v1.0.0", info.Version)
+	}
+}
+
+func TestGetVersionInfoCacheCorrupt(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	cacheDir := t.TempDir()
+
+	want, err := GetVersionInfo(tempDir, "", WithCache(cacheDir))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	for _, entry := range entries {
+		if err := os.WriteFile(entry, []byte("{"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	got, err := GetVersionInfo(tempDir, "", WithCache(cacheDir))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if got.Version != want.Version {
+		t.Errorf("Version = %q with a corrupt entry, want %q", got.Version, want.Version)
+	}
+}
+
+func TestGetVersionInfoCachePrune(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	cacheDir := t.TempDir()
+
+	if _, err := GetVersionInfo(tempDir, "", WithCache(cacheDir)); err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	used, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if len(used) != 1 {
+		t.Fatalf("cache has %d entries, want 1", len(used))
+	}
+	old := time.Now().Add(-cacheMaxAge - time.Hour)
+	stale := filepath.Join(cacheDir, "stale.json")
+	tmp := filepath.Join(cacheDir, ".tmp-stale")
+	writeFile(t, stale, "{}")
+	writeFile(t, tmp, "{}")
+	for _, path := range append([]string{stale, tmp}, used...) {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+	}
+	// A cache hit keeps the used entry
+	if _, err := GetVersionInfo(tempDir, "", WithCache(cacheDir)); err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+
+	// Writing a new entry prunes the old ones
+	if _, err := GetVersionInfo(tempDir, "", WithCache(cacheDir), WithTagPrefix("release/")); err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	for _, path := range []string{stale, tmp} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not pruned", filepath.Base(path))
+		}
+	}
+	if _, err := os.Stat(used[0]); err != nil {
+		t.Errorf("used entry was pruned: %v", err)
+	}
+	if n := cacheEntries(t, cacheDir); n != 2 {
+		t.Errorf("cache has %d entries, want 2", n)
+	}
+}
@@ -0,0 +1,39 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/calver"
+)
+
+// CalVer returns the Calendar Versioning strategy: versions are rendered from
+// the commit date with the given format, the micro counter continues from the
+// latest tag of the same period and is reset otherwise. Tagged commits use the
+// tag, other branches get a prerelease named after the branch, e.g.
+// 2024.5.3-feature-x.2
+func CalVer(format *calver.Format) Strategy {
+	return calVer{format: format}
+}
+
+type calVer struct {
+	format *calver.Format
+}
+
+func (calVer) Name() string { return "calver" }
+
+func (s calVer) Version(info *Info) (string, error) {
+	if info.LatestTag != "" && info.CommitsSinceTag == 0 {
+		return info.describeVersion(), nil
+	}
+
+	latest := strings.TrimPrefix(info.LatestTag, info.componentPrefix+info.tagPrefix)
+	next := s.format.Next(latest, info.commitTime.UTC())
+	if !info.IsDefaultBranch() {
+		next = fmt.Sprintf("%s-%s.%d", next, info.GitBranchSlug, info.CommitsSinceTag)
+	}
+	if !info.stripTagPrefix {
+		next = info.tagPrefix + next
+	}
+	return next, nil
+}
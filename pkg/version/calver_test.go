@@ -0,0 +1,70 @@
+package version
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fxsml/gitversion/pkg/calver"
+)
+
+func TestCalVerStrategy(t *testing.T) {
+	format, err := calver.ParseFormat("YYYY.MM.MICRO")
+	if err != nil {
+		t.Fatalf("ParseFormat failed: %v", err)
+	}
+	commitTime := time.Date(2024, time.May, 14, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		info     Info
+		expected string
+	}{
+		{
+			name:     "no tags",
+			info:     Info{GitBranch: "main", CommitsSinceTag: 3},
+			expected: "2024.5.0",
+		},
+		{
+			name:     "at tag",
+			info:     Info{GitBranch: "main", GitDescribe: "2024.5.2", LatestTag: "2024.5.2"},
+			expected: "2024.5.2",
+		},
+		{
+			name:     "after tag in same month",
+			info:     Info{GitBranch: "main", GitDescribe: "2024.5.2-4-gabc123d", LatestTag: "2024.5.2", CommitsSinceTag: 4},
+			expected: "2024.5.3",
+		},
+		{
+			name:     "after tag in previous month",
+			info:     Info{GitBranch: "main", GitDescribe: "2024.4.7-1-gabc123d", LatestTag: "2024.4.7", CommitsSinceTag: 1},
+			expected: "2024.5.0",
+		},
+		{
+			name:     "feature branch",
+			info:     Info{GitBranch: "feature/x", LatestTag: "2024.5.2", CommitsSinceTag: 2},
+			expected: "2024.5.3-feature-x.2",
+		},
+		{
+			name:     "tag prefix",
+			info:     Info{GitBranch: "main", LatestTag: "release/2024.5.2", CommitsSinceTag: 1, tagPrefix: "release/"},
+			expected: "release/2024.5.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			info.DefaultBranch = "main"
+			info.GitBranchSlug = createBranchSlug(info.GitBranch)
+			info.commitTime = commitTime
+
+			result, err := CalVer(format).Version(&info)
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Version() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
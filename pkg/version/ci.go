@@ -0,0 +1,152 @@
+package version
+
+import "strings"
+
+// CI providers recognized by DetectCI
+const (
+	CIGitHubActions = "github-actions"
+	CIGitLab        = "gitlab"
+	CIJenkins       = "jenkins"
+	CICircleCI      = "circleci"
+	CIBuildkite     = "buildkite"
+	CIDrone         = "drone"
+	CITeamCity      = "teamcity"
+	CIAzure         = "azure-pipelines"
+)
+
+// CI is the build a version is computed in, as read from the environment of
+// the CI provider by DetectCI
+type CI struct {
+	// Provider is the CI provider, e.g. CIJenkins
+	Provider string `json:"provider" yaml:"provider"`
+	// BuildNumber is the provider's number of the build, if any
+	BuildNumber string `json:"buildNumber,omitempty" yaml:"buildNumber,omitempty"`
+	// Branch is the branch being built, the source branch for pull requests;
+	// empty for tag builds
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	// Tag is the tag being built, if any
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// PullRequest is the number of the pull or merge request being built
+	PullRequest string `json:"pullRequest,omitempty" yaml:"pullRequest,omitempty"`
+	// DefaultBranch is the default branch of the repository, if the provider
+	// exposes it
+	DefaultBranch string `json:"defaultBranch,omitempty" yaml:"defaultBranch,omitempty"`
+}
+
+// DetectCI recognizes the CI provider from the environment, read with getenv
+// (e.g. os.Getenv), and returns the build it describes, or nil outside of a
+// known provider. TeamCity does not expose the branch as an environment
+// variable; it is read from BRANCH_NAME, which the build configuration has to
+// define as env.BRANCH_NAME=%teamcity.build.branch%.
+func DetectCI(getenv func(string) string) *CI {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		ci := &CI{Provider: CIGitHubActions, BuildNumber: getenv("GITHUB_RUN_NUMBER")}
+		if getenv("GITHUB_REF_TYPE") == "tag" {
+			ci.Tag = getenv("GITHUB_REF_NAME")
+		} else if ci.Branch = getenv("GITHUB_HEAD_REF"); ci.Branch == "" {
+			ci.Branch = strings.TrimPrefix(getenv("GITHUB_REF"), "refs/heads/")
+		}
+		// Pull requests are checked out at refs/pull/<number>/merge
+		if pr, ok := strings.CutPrefix(getenv("GITHUB_REF"), "refs/pull/"); ok {
+			ci.PullRequest, _, _ = strings.Cut(pr, "/")
+		}
+		return ci
+	case getenv("GITLAB_CI") == "true":
+		ci := &CI{
+			Provider:      CIGitLab,
+			BuildNumber:   getenv("CI_PIPELINE_IID"),
+			Tag:           getenv("CI_COMMIT_TAG"),
+			PullRequest:   getenv("CI_MERGE_REQUEST_IID"),
+			DefaultBranch: getenv("CI_DEFAULT_BRANCH"),
+		}
+		if ci.Tag == "" {
+			ci.Branch = getenv("CI_COMMIT_REF_NAME")
+		}
+		return ci
+	case getenv("JENKINS_URL") != "":
+		ci := &CI{
+			Provider:    CIJenkins,
+			BuildNumber: getenv("BUILD_NUMBER"),
+			Tag:         getenv("TAG_NAME"),
+			PullRequest: getenv("CHANGE_ID"),
+		}
+		if ci.Tag == "" {
+			// Multibranch pipelines name pull requests PR-<number> and set
+			// CHANGE_BRANCH; the Git plugin sets GIT_BRANCH to origin/<branch>
+			ci.Branch = firstEnv(getenv, "CHANGE_BRANCH", "BRANCH_NAME")
+			if ci.Branch == "" {
+				ci.Branch = strings.TrimPrefix(getenv("GIT_BRANCH"), "origin/")
+			}
+		}
+		return ci
+	case getenv("CIRCLECI") == "true":
+		ci := &CI{
+			Provider:    CICircleCI,
+			BuildNumber: getenv("CIRCLE_BUILD_NUM"),
+			Tag:         getenv("CIRCLE_TAG"),
+			PullRequest: getenv("CIRCLE_PR_NUMBER"),
+		}
+		if ci.Tag == "" {
+			ci.Branch = getenv("CIRCLE_BRANCH")
+		}
+		// CIRCLE_PR_NUMBER is only set for forks, CIRCLE_PULL_REQUEST is the URL
+		if url := getenv("CIRCLE_PULL_REQUEST"); ci.PullRequest == "" && url != "" {
+			ci.PullRequest = url[strings.LastIndex(url, "/")+1:]
+		}
+		return ci
+	case getenv("BUILDKITE") == "true":
+		ci := &CI{
+			Provider:      CIBuildkite,
+			BuildNumber:   getenv("BUILDKITE_BUILD_NUMBER"),
+			Tag:           getenv("BUILDKITE_TAG"),
+			DefaultBranch: getenv("BUILDKITE_PIPELINE_DEFAULT_BRANCH"),
+		}
+		if ci.Tag == "" {
+			ci.Branch = getenv("BUILDKITE_BRANCH")
+		}
+		if pr := getenv("BUILDKITE_PULL_REQUEST"); pr != "false" {
+			ci.PullRequest = pr
+		}
+		return ci
+	case getenv("DRONE") == "true":
+		ci := &CI{
+			Provider:      CIDrone,
+			BuildNumber:   getenv("DRONE_BUILD_NUMBER"),
+			Tag:           getenv("DRONE_TAG"),
+			PullRequest:   getenv("DRONE_PULL_REQUEST"),
+			DefaultBranch: getenv("DRONE_REPO_BRANCH"),
+		}
+		if ci.Tag == "" {
+			// DRONE_BRANCH is the target branch of pull requests
+			ci.Branch = firstEnv(getenv, "DRONE_SOURCE_BRANCH", "DRONE_BRANCH")
+		}
+		return ci
+	case getenv("TEAMCITY_VERSION") != "":
+		return &CI{Provider: CITeamCity, BuildNumber: getenv("BUILD_NUMBER"), Branch: getenv("BRANCH_NAME")}
+	case strings.EqualFold(getenv("TF_BUILD"), "true"):
+		ci := &CI{
+			Provider:    CIAzure,
+			BuildNumber: getenv("BUILD_BUILDNUMBER"),
+			PullRequest: firstEnv(getenv, "SYSTEM_PULLREQUEST_PULLREQUESTNUMBER", "SYSTEM_PULLREQUEST_PULLREQUESTID"),
+		}
+		ref := firstEnv(getenv, "SYSTEM_PULLREQUEST_SOURCEBRANCH", "BUILD_SOURCEBRANCH")
+		if tag, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+			ci.Tag = tag
+		} else {
+			ci.Branch = strings.TrimPrefix(ref, "refs/heads/")
+		}
+		return ci
+	}
+	return nil
+}
+
+// firstEnv returns the value of the first of the variables that is set
+func firstEnv(getenv func(string) string, names ...string) string {
+	for _, name := range names {
+		if v := getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
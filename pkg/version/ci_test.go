@@ -0,0 +1,147 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected *CI
+	}{
+		{"none", map[string]string{"CI": "true"}, nil},
+		{"github actions branch", map[string]string{
+			"GITHUB_ACTIONS": "true", "GITHUB_RUN_NUMBER": "12", "GITHUB_REF": "refs/heads/feature/x", "GITHUB_REF_TYPE": "branch", "GITHUB_REF_NAME": "feature/x",
+		}, &CI{Provider: CIGitHubActions, BuildNumber: "12", Branch: "feature/x"}},
+		{"github actions pull request", map[string]string{
+			"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/pull/5/merge", "GITHUB_REF_TYPE": "branch", "GITHUB_HEAD_REF": "fix/y",
+		}, &CI{Provider: CIGitHubActions, Branch: "fix/y", PullRequest: "5"}},
+		{"github actions tag", map[string]string{
+			"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/tags/v1.0.0", "GITHUB_REF_TYPE": "tag", "GITHUB_REF_NAME": "v1.0.0",
+		}, &CI{Provider: CIGitHubActions, Tag: "v1.0.0"}},
+		{"gitlab merge request", map[string]string{
+			"GITLAB_CI": "true", "CI_PIPELINE_IID": "3", "CI_COMMIT_REF_NAME": "feature/x", "CI_MERGE_REQUEST_IID": "8", "CI_DEFAULT_BRANCH": "main",
+		}, &CI{Provider: CIGitLab, BuildNumber: "3", Branch: "feature/x", PullRequest: "8", DefaultBranch: "main"}},
+		{"gitlab tag", map[string]string{
+			"GITLAB_CI": "true", "CI_COMMIT_REF_NAME": "v1.0.0", "CI_COMMIT_TAG": "v1.0.0", "CI_DEFAULT_BRANCH": "main",
+		}, &CI{Provider: CIGitLab, Tag: "v1.0.0", DefaultBranch: "main"}},
+		{"jenkins multibranch pull request", map[string]string{
+			"JENKINS_URL": "https://ci/", "BUILD_NUMBER": "42", "BRANCH_NAME": "PR-7", "CHANGE_ID": "7", "CHANGE_BRANCH": "feature/x",
+		}, &CI{Provider: CIJenkins, BuildNumber: "42", Branch: "feature/x", PullRequest: "7"}},
+		{"jenkins git plugin", map[string]string{
+			"JENKINS_URL": "https://ci/", "BUILD_NUMBER": "1", "GIT_BRANCH": "origin/main",
+		}, &CI{Provider: CIJenkins, BuildNumber: "1", Branch: "main"}},
+		{"jenkins tag", map[string]string{
+			"JENKINS_URL": "https://ci/", "BRANCH_NAME": "v1.0.0", "TAG_NAME": "v1.0.0",
+		}, &CI{Provider: CIJenkins, Tag: "v1.0.0"}},
+		{"circleci pull request", map[string]string{
+			"CIRCLECI": "true", "CIRCLE_BUILD_NUM": "99", "CIRCLE_BRANCH": "feature/x", "CIRCLE_PULL_REQUEST": "https://github.com/org/repo/pull/11",
+		}, &CI{Provider: CICircleCI, BuildNumber: "99", Branch: "feature/x", PullRequest: "11"}},
+		{"buildkite", map[string]string{
+			"BUILDKITE": "true", "BUILDKITE_BUILD_NUMBER": "5", "BUILDKITE_BRANCH": "main", "BUILDKITE_PULL_REQUEST": "false", "BUILDKITE_PIPELINE_DEFAULT_BRANCH": "main",
+		}, &CI{Provider: CIBuildkite, BuildNumber: "5", Branch: "main", DefaultBranch: "main"}},
+		{"drone pull request", map[string]string{
+			"DRONE": "true", "DRONE_BUILD_NUMBER": "8", "DRONE_BRANCH": "main", "DRONE_SOURCE_BRANCH": "feature/x", "DRONE_PULL_REQUEST": "4", "DRONE_REPO_BRANCH": "main",
+		}, &CI{Provider: CIDrone, BuildNumber: "8", Branch: "feature/x", PullRequest: "4", DefaultBranch: "main"}},
+		{"teamcity", map[string]string{
+			"TEAMCITY_VERSION": "2024.03", "BUILD_NUMBER": "17", "BRANCH_NAME": "main",
+		}, &CI{Provider: CITeamCity, BuildNumber: "17", Branch: "main"}},
+		{"azure pipelines pull request", map[string]string{
+			"TF_BUILD": "True", "BUILD_BUILDNUMBER": "20250101.1", "BUILD_SOURCEBRANCH": "refs/pull/6/merge",
+			"SYSTEM_PULLREQUEST_SOURCEBRANCH": "refs/heads/feature/x", "SYSTEM_PULLREQUEST_PULLREQUESTNUMBER": "6",
+		}, &CI{Provider: CIAzure, BuildNumber: "20250101.1", Branch: "feature/x", PullRequest: "6"}},
+		{"azure pipelines tag", map[string]string{
+			"TF_BUILD": "True", "BUILD_SOURCEBRANCH": "refs/tags/v1.0.0",
+		}, &CI{Provider: CIAzure, Tag: "v1.0.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := DetectCI(func(name string) string { return tt.env[name] })
+			if (ci == nil) != (tt.expected == nil) || ci != nil && *ci != *tt.expected {
+				t.Errorf("DetectCI() = %+v, want %+v", ci, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoWithCI(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	second := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, second)); err != nil {
+		t.Fatalf("Failed to detach HEAD: %v", err)
+	}
+	short := second.String()[:7]
+
+	tests := []struct {
+		name          string
+		defaultBranch string
+		ci            *CI
+		branch        string
+		expected      string
+	}{
+		{"pull request", "", &CI{Provider: CIJenkins, BuildNumber: "42", Branch: "feature/x", PullRequest: "7"}, "feature/x", "pr-7-g" + short},
+		{"default branch from CI", "", &CI{Provider: CIGitLab, Branch: "trunk", DefaultBranch: "trunk"}, "trunk", "v1.0.0-1-g" + short},
+		{"default branch argument wins", "master", &CI{Provider: CIGitLab, Branch: "trunk", DefaultBranch: "trunk"}, "trunk", "trunk-g" + short},
+		{"tag build", "", &CI{Provider: CIBuildkite, Tag: "v1.1.0"}, "master", "v1.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, tt.defaultBranch, WithCI(tt.ci))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.GitBranch != tt.branch {
+				t.Errorf("GitBranch = %q, want %q", info.GitBranch, tt.branch)
+			}
+			if info.Version != tt.expected {
+				t.Errorf("Version = %q, want %q", info.Version, tt.expected)
+			}
+			if info.CI != tt.ci {
+				t.Errorf("CI = %+v, want %+v", info.CI, tt.ci)
+			}
+		})
+	}
+
+	// Pull request builds use the pull request template
+	pr := &CI{Provider: CIGitHubActions, Branch: "feature/x", PullRequest: "123"}
+	info, err := GetVersionInfo(tempDir, "master", WithCI(pr))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.PullRequestNumber != 123 || info.Version != "pr-123-g"+short {
+		t.Errorf("PullRequestNumber, Version = %d, %q, want 123, pr-123-g%s", info.PullRequestNumber, info.Version, short)
+	}
+	info, err = GetVersionInfo(tempDir, "master", WithCI(pr), WithPullRequestTemplate("{describe}-pr.{pr}"), WithBranchTemplates(BranchTemplate{Pattern: "*", Template: "{slug}"}))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Version != "v1.0.0-1-g"+short+"-pr.123" {
+		t.Errorf("Version = %q, want the pull request template before branch templates", info.Version)
+	}
+	info, err = GetVersionInfo(tempDir, "master", WithCI(pr), WithRef(second.String()))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.PullRequestNumber != 0 || info.Version != "v1.0.0-1-g"+short {
+		t.Errorf("PullRequestNumber, Version = %d, %q with WithRef, want 0, v1.0.0-1-g%s", info.PullRequestNumber, info.Version, short)
+	}
+
+	// Explicit options take precedence over the CI build
+	info, err = GetVersionInfo(tempDir, "master", WithCI(&CI{Provider: CIDrone, Branch: "feature/x"}), WithDetachedBranch("master"))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.GitBranch != "master" {
+		t.Errorf("GitBranch = %q, want master", info.GitBranch)
+	}
+}
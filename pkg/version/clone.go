@@ -0,0 +1,110 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// FromURL clones the repository at url into memory and computes its version
+// info, for services versioning repositories they have no checkout of. HTTP(S)
+// URLs authenticate with GITVERSION_TOKEN like WithFetchTags, SSH URLs with
+// the ssh-agent. With WithDeepen the clone starts shallow and fetches more
+// history only until a tag is reachable. WithRef selects a branch, tag or
+// commit of the remote; there is no working tree to check. Only the go-git
+// backend is supported, without WithCache.
+func FromURL(url string, defaultBranch string, opts ...Option) (*Info, error) {
+	return FromURLContext(context.Background(), url, defaultBranch, opts...)
+}
+
+// FromURLContext is like FromURL but aborts cloning and walking the history
+// once the context is done
+func FromURLContext(ctx context.Context, url string, defaultBranch string, opts ...Option) (*Info, error) {
+	if url == "" {
+		return nil, errors.New("no URL given")
+	}
+	o := newOptions(opts)
+	o.defaultBranch = defaultBranch
+	o.ctx = ctx
+	if _, ok := o.backend.(goGit); !ok {
+		return nil, fmt.Errorf("the %s backend cannot version a URL, use the go-git backend", o.backend.Name())
+	}
+	if o.cacheDir != "" {
+		return nil, errors.New("the cache needs a repository path and cannot be used with a URL")
+	}
+
+	repo, err := cloneInMemory(ctx, url, o)
+	if err != nil {
+		return nil, err
+	}
+	o.repo = repo
+	return getVersionInfo("", o)
+}
+
+// cloneInMemory clones the repository at url into memory storage without a
+// working tree. Like git clone, origin/HEAD names the default branch; unlike
+// it, every branch of origin gets a local branch, so that WithRef resolves
+// branch names as in the remote.
+func cloneInMemory(ctx context.Context, url string, o *options) (*git.Repository, error) {
+	cloneOpts := &git.CloneOptions{
+		URL:  url,
+		Tags: git.AllTags,
+	}
+	if username, token, ok := fetchCredentials(url); ok {
+		cloneOpts.Auth = &http.BasicAuth{Username: username, Password: token}
+	}
+	if o.deepen {
+		cloneOpts.Depth = deepenStart
+		cloneOpts.Tags = git.TagFollowing
+	}
+	o.log().Info("cloning into memory", "url", url, "depth", cloneOpts.Depth)
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, cloneOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD of %s: %w", url, err)
+	}
+	if head.Type() == plumbing.SymbolicReference && head.Target().IsBranch() {
+		originHead := plumbing.NewSymbolicReference(plumbing.NewRemoteHEADReferenceName(DefaultFetchRemote),
+			plumbing.NewRemoteReferenceName(DefaultFetchRemote, head.Target().Short()))
+		if err := repo.Storer.SetReference(originHead); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", originHead.Name(), err)
+		}
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	var branches []*plumbing.Reference
+	prefix := DefaultFetchRemote + "/"
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsRemote() && ref.Type() == plumbing.HashReference {
+			name := plumbing.NewBranchReferenceName(strings.TrimPrefix(ref.Name().Short(), prefix))
+			branches = append(branches, plumbing.NewHashReference(name, ref.Hash()))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	for _, branch := range branches {
+		if _, err := repo.Storer.Reference(branch.Name()); err == nil {
+			continue
+		}
+		if err := repo.Storer.SetReference(branch); err != nil {
+			return nil, fmt.Errorf("failed to create branch %s: %w", branch.Name().Short(), err)
+		}
+	}
+	return repo, nil
+}
@@ -0,0 +1,59 @@
+package version
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestFromURL(t *testing.T) {
+	originDir, origin := initTestRepo(t)
+	var tagged, head plumbing.Hash
+	for i := 1; i <= 4; i++ {
+		head = commitTestFile(t, origin, originDir, "test.txt", fmt.Sprint(i), fmt.Sprintf("Commit %d", i))
+		if i == 2 {
+			tagged = head
+			if _, err := origin.CreateTag("v1.0.0", tagged, nil); err != nil {
+				t.Fatalf("Failed to create tag: %v", err)
+			}
+		}
+	}
+	if err := origin.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature/x", tagged)); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	info, err := FromURL(originDir, "")
+	if err != nil {
+		t.Fatalf("FromURL failed: %v", err)
+	}
+	expected := "v1.0.0-2-g" + head.String()[:7]
+	if info.Version != expected || info.DefaultBranch != "master" || info.IsShallow {
+		t.Errorf("Version, DefaultBranch, IsShallow = %q, %q, %v, want %q, master, false", info.Version, info.DefaultBranch, info.IsShallow, expected)
+	}
+
+	// Branches of the remote resolve by name
+	info, err = FromURL(originDir, "", WithRef("feature/x"))
+	if err != nil {
+		t.Fatalf("FromURL failed: %v", err)
+	}
+	if info.GitBranch != "feature/x" || info.GitCommit != tagged.String() {
+		t.Errorf("GitBranch, GitCommit = %q, %q, want feature/x, %s", info.GitBranch, info.GitCommit, tagged)
+	}
+
+	// A shallow clone fetches more history until the tag is reachable
+	info, err = FromURL(originDir, "", WithDeepen(true))
+	if err != nil {
+		t.Fatalf("FromURL failed: %v", err)
+	}
+	if info.Version != expected {
+		t.Errorf("Version = %q, want %q", info.Version, expected)
+	}
+
+	if _, err := FromURL(originDir, "", WithBackend(GitCLI())); err == nil {
+		t.Error("FromURL() with git-cli backend expected error")
+	}
+	if _, err := FromURL(originDir, "", WithCache(t.TempDir())); err == nil {
+		t.Error("FromURL() with cache expected error")
+	}
+}
@@ -0,0 +1,29 @@
+package version
+
+import (
+	"github.com/go-git/go-git/v5"
+	cgformat "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// commitNodes returns the index the history is walked with and the function
+// that releases it. If git wrote a commit-graph (objects/info/commit-graph or
+// a commit-graph chain, e.g. by git gc or fetch.writeCommitGraph), parents,
+// commit dates and generation numbers are read from it without parsing commit
+// objects; commits newer than the commit-graph are read from their objects.
+// Shallow clones are walked by their objects, as their commit-graph may
+// refer to parents beyond the shallow boundary.
+func commitNodes(repo *git.Repository) (commitgraph.CommitNodeIndex, func()) {
+	objects := commitgraph.NewObjectCommitNodeIndex(repo.Storer)
+	fs, ok := repo.Storer.(*filesystem.Storage)
+	if !ok || len(shallowCommits(repo)) > 0 {
+		return objects, func() {}
+	}
+	index, err := cgformat.OpenChainOrFileIndex(fs.Filesystem())
+	if err != nil {
+		// No or an unreadable commit-graph
+		return objects, func() {}
+	}
+	return commitgraph.NewGraphCommitNodeIndex(index, repo.Storer), func() { index.Close() }
+}
@@ -0,0 +1,125 @@
+package version
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fxsml/gitversion/pkg/revwalk"
+)
+
+// writeCommitGraph writes the commit-graph of the repository with git
+func writeCommitGraph(t *testing.T, dir string) {
+	t.Helper()
+	if out, err := exec.Command("git", "-C", dir, "commit-graph", "write", "--reachable").CombinedOutput(); err != nil {
+		t.Fatalf("Failed to write commit-graph: %v: %s", err, out)
+	}
+}
+
+// commitAt commits with the given committer date and parents
+func commitAt(t *testing.T, repo *git.Repository, msg string, when time.Time, parents ...plumbing.Hash) plumbing.Hash {
+	t.Helper()
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	sig := &object.Signature{Name: "Test User", Email: "test@example.com", When: when}
+	hash, err := w.Commit(msg, &git.CommitOptions{Author: sig, Committer: sig, Parents: parents, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash
+}
+
+func TestCommitNodes(t *testing.T) {
+	if !hasGit() {
+		t.Skip("git is not installed")
+	}
+	tempDir, repo, hashes := createMergeHistory(t)
+	a, c, m := hashes[0], hashes[2], hashes[3]
+
+	nodes, release := commitNodes(repo)
+	node, err := nodes.Get(m)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	release()
+	if node.Generation() != math.MaxUint64 {
+		t.Errorf("Generation() = %d without commit-graph, want the object fallback", node.Generation())
+	}
+
+	writeCommitGraph(t, tempDir)
+	// A commit after the commit-graph was written is read from its object
+	head := commitTestFile(t, repo, tempDir, "test.txt", "d", "D")
+
+	nodes, release = commitNodes(repo)
+	defer release()
+	node, err = nodes.Get(m)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if node.Generation() != 3 {
+		t.Errorf("Generation() = %d, want 3 from the commit-graph", node.Generation())
+	}
+
+	tests := []struct {
+		name        string
+		since       plumbing.Hash
+		firstParent bool
+		expected    int
+	}{
+		{"whole history", plumbing.ZeroHash, false, 5},
+		{"since merged branch excludes its ancestors", c, false, 3},
+		{"since fork point", a, false, 4},
+		{"first parent since fork point", a, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := revwalk.Count(context.Background(), nodes, head, tt.since, tt.firstParent, nil)
+			if err != nil {
+				t.Fatalf("Count failed: %v", err)
+			}
+			if count != tt.expected {
+				t.Errorf("Count = %d, want %d", count, tt.expected)
+			}
+		})
+	}
+
+	info, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.LatestTag != "v2.0.0-feature" || info.CommitsSinceTag != 3 {
+		t.Errorf("describe = %s-%d, want v2.0.0-feature-3", info.LatestTag, info.CommitsSinceTag)
+	}
+}
+
+func TestCountCommitsClockSkew(t *testing.T) {
+	if !hasGit() {
+		t.Skip("git is not installed")
+	}
+	// B and its child C are dated before their parent A, as by a wrong clock
+	tempDir, repo := initTestRepo(t)
+	now := time.Now()
+	a := commitAt(t, repo, "A", now)
+	b := commitAt(t, repo, "B", now.Add(-48*time.Hour), a)
+	c := commitAt(t, repo, "C", now.Add(-24*time.Hour), b)
+	writeCommitGraph(t, tempDir)
+
+	nodes, release := commitNodes(repo)
+	defer release()
+	// A is an ancestor of C, so no commits are in C..A
+	count, err := revwalk.Count(context.Background(), nodes, a, c, false, nil)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Count since a descendant with skewed dates = %d, want 0", count)
+	}
+}
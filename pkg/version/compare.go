@@ -0,0 +1,83 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/revwalk"
+)
+
+// Comparison relates the version info at two refs
+type Comparison struct {
+	From *Info `json:"from" yaml:"from"`
+	To   *Info `json:"to" yaml:"to"`
+	// Order is -1, 0 or 1 as the latest tag of From has lower, equal or
+	// higher SemVer precedence than the latest tag of To; no tag is 0.0.0
+	Order int `json:"order" yaml:"order"`
+	// Ahead is the number of commits reachable from To but not from From,
+	// Behind the number of commits reachable from From but not from To
+	Ahead  int `json:"ahead" yaml:"ahead"`
+	Behind int `json:"behind" yaml:"behind"`
+	// BumpRequired reports that To has commits From does not have, but no
+	// higher tag than From, i.e. that there are unreleased changes
+	BumpRequired bool `json:"bumpRequired" yaml:"bumpRequired"`
+}
+
+// Compare computes the version info at the refs from and to, e.g. the latest
+// release tag and HEAD, and relates their latest tags and histories. Commits
+// outside the component path are not counted.
+func Compare(repoPath, from, to string, opts ...Option) (*Comparison, error) {
+	return CompareContext(context.Background(), repoPath, from, to, opts...)
+}
+
+// CompareContext is like Compare but aborts walking the history once the
+// context is done
+func CompareContext(ctx context.Context, repoPath, from, to string, opts ...Option) (*Comparison, error) {
+	if from == "" || to == "" {
+		return nil, errors.New("both refs are required")
+	}
+	o := newOptions(opts)
+	o.ctx = ctx
+
+	c := &Comparison{}
+	var err error
+	for _, side := range []struct {
+		ref  string
+		info **Info
+	}{{from, &c.From}, {to, &c.To}} {
+		so := *o
+		so.ref = side.ref
+		if *side.info, err = getVersionInfo(repoPath, &so); err != nil {
+			return nil, fmt.Errorf("failed to get version info at %s: %w", side.ref, err)
+		}
+	}
+
+	fromVersion, err := c.From.LatestSemVer()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", from, err)
+	}
+	toVersion, err := c.To.LatestSemVer()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", to, err)
+	}
+	c.Order = fromVersion.Compare(toVersion)
+
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	fromHash, toHash := plumbing.NewHash(c.From.GitCommit), plumbing.NewHash(c.To.GitCommit)
+	nodes, release := commitNodes(repo)
+	defer release()
+	if c.Ahead, err = revwalk.Count(ctx, nodes, toHash, fromHash, false, o.componentFilter()); err != nil {
+		return nil, fmt.Errorf("failed to count commits: %w", err)
+	}
+	if c.Behind, err = revwalk.Count(ctx, nodes, fromHash, toHash, false, o.componentFilter()); err != nil {
+		return nil, fmt.Errorf("failed to count commits: %w", err)
+	}
+	c.BumpRequired = c.Ahead > 0 && c.Order >= 0
+	return c, nil
+}
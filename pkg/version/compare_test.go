@@ -0,0 +1,60 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	third := commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+
+	tests := []struct {
+		name         string
+		from, to     string
+		order        int
+		ahead        int
+		behind       int
+		bumpRequired bool
+	}{
+		{"unreleased changes", "v1.0.0", "master", 0, 2, 0, true},
+		{"same commit", "v1.0.0", "v1.0.0", 0, 0, 0, false},
+		{"reversed", "master", "v1.0.0", 0, 0, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Compare(tempDir, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Compare failed: %v", err)
+			}
+			if c.Order != tt.order || c.Ahead != tt.ahead || c.Behind != tt.behind || c.BumpRequired != tt.bumpRequired {
+				t.Errorf("Compare(%s, %s) = order %d, ahead %d, behind %d, bump %v, want %d, %d, %d, %v",
+					tt.from, tt.to, c.Order, c.Ahead, c.Behind, c.BumpRequired, tt.order, tt.ahead, tt.behind, tt.bumpRequired)
+			}
+		})
+	}
+
+	// A new tag releases the changes
+	if _, err := repo.CreateTag("v1.1.0", third, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	c, err := Compare(tempDir, "v1.0.0", "master")
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if c.Order != -1 || c.Ahead != 2 || c.BumpRequired {
+		t.Errorf("Compare() = order %d, ahead %d, bump %v, want -1, 2, false", c.Order, c.Ahead, c.BumpRequired)
+	}
+	if c.From.LatestTag != "v1.0.0" || c.To.LatestTag != "v1.1.0" {
+		t.Errorf("latest tags = %s, %s, want v1.0.0, v1.1.0", c.From.LatestTag, c.To.LatestTag)
+	}
+
+	if _, err := Compare(tempDir, "v1.0.0", "missing"); err == nil {
+		t.Error("Compare should fail for an unknown ref")
+	}
+	if _, err := Compare(tempDir, "", "master"); err == nil {
+		t.Error("Compare should fail without a ref")
+	}
+}
@@ -0,0 +1,86 @@
+package version
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// cleanComponentPath normalizes a component path to a slash-separated path
+// relative to the repository root without leading or trailing slashes
+func cleanComponentPath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	p = path.Clean("/" + p)
+	return strings.TrimPrefix(p, "/")
+}
+
+// ComponentTagNamespace renders the tag namespace of a component from a tag
+// template such as "{component}/v{version}" or "{component}-v{version}": the
+// template up to {version}, which must end it, with {component} replaced by
+// the name and {path} by the path of the component. A "v" right before
+// {version} belongs to the version, as in tags without namespace.
+func ComponentTagNamespace(template, name, path string) (string, error) {
+	namespace, ok := strings.CutSuffix(template, "{version}")
+	if !ok {
+		return "", fmt.Errorf("invalid tag template %q: must end with {version}", template)
+	}
+	namespace = strings.TrimSuffix(namespace, "v")
+	if !strings.Contains(namespace, "{component}") && !strings.Contains(namespace, "{path}") {
+		return "", fmt.Errorf("invalid tag template %q: must contain {component} or {path}", template)
+	}
+	namespace = strings.NewReplacer("{component}", name, "{path}", path).Replace(namespace)
+	if strings.ContainsAny(namespace, "{}") {
+		return "", fmt.Errorf("invalid tag template %q: unknown placeholder, must be {component}, {path} or {version}", template)
+	}
+	return namespace, nil
+}
+
+// componentFilter returns the function commit counts are limited to the
+// component path with, or nil to count all commits
+func (o *options) componentFilter() func(*object.Commit) bool {
+	if o.componentPath == "" {
+		return nil
+	}
+	return func(commit *object.Commit) bool {
+		return touchesPath(commit, o.componentPath)
+	}
+}
+
+// touchesPath reports whether the commit changes anything below the given path.
+// Like git's history simplification, a merge commit only touches the path if
+// the path differs from every parent.
+func touchesPath(commit *object.Commit, p string) bool {
+	hash := pathHash(commit, p)
+	if commit.NumParents() == 0 {
+		return hash != plumbing.ZeroHash
+	}
+
+	touched := true
+	_ = commit.Parents().ForEach(func(parent *object.Commit) error {
+		if pathHash(parent, p) == hash {
+			touched = false
+		}
+		return nil
+	})
+	return touched
+}
+
+// pathHash returns the object hash of the tree entry at the given path in the
+// commit, or the zero hash if the path does not exist
+func pathHash(commit *object.Commit, p string) plumbing.Hash {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	if p == "" {
+		return tree.Hash
+	}
+	entry, err := tree.FindEntry(p)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return entry.Hash
+}
@@ -0,0 +1,185 @@
+package version
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+func TestCleanComponentPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{".", ""},
+		{"services/api", "services/api"},
+		{"./services/api/", "services/api"},
+		{"/services//api", "services/api"},
+		{"services\\api", "services/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := cleanComponentPath(tt.input); result != tt.expected {
+				t.Errorf("cleanComponentPath(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoWithComponentPath(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	commitTestFile(t, repo, tempDir, "services/api/main.go", "v1", "Add api")
+	tagged := commitTestFile(t, repo, tempDir, "services/web/main.go", "v1", "Add web")
+	if _, err := repo.CreateTag("services/api/v1.0.0", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if _, err := repo.CreateTag("services/web/v2.0.0", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "services/api/main.go", "v2", "Change api")
+	commitTestFile(t, repo, tempDir, "services/web/main.go", "v2", "Change web")
+	head := commitTestFile(t, repo, tempDir, "services/web/main.go", "v3", "Change web again")
+	short := head.String()[:7]
+
+	tests := []struct {
+		name      string
+		path      string
+		expected  string
+		latestTag string
+	}{
+		{
+			name:      "api counts only api commits",
+			path:      "services/api",
+			expected:  "v1.0.0-1-g" + short,
+			latestTag: "services/api/v1.0.0",
+		},
+		{
+			name:      "web counts only web commits",
+			path:      "./services/web/",
+			expected:  "v2.0.0-2-g" + short,
+			latestTag: "services/web/v2.0.0",
+		},
+		{
+			name: "component without tags",
+			path: "services/db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", WithComponentPath(tt.path))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			expected := tt.expected
+			if expected == "" {
+				expected = info.GitBranchSlug + "-g" + short
+			}
+			if info.Version != expected {
+				t.Errorf("Version = %q, want %q", info.Version, expected)
+			}
+			if info.LatestTag != tt.latestTag {
+				t.Errorf("LatestTag = %q, want %q", info.LatestTag, tt.latestTag)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoWithComponentPathUnchanged(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	tagged := commitTestFile(t, repo, tempDir, "services/api/main.go", "v1", "Add api")
+	if _, err := repo.CreateTag("services/api/v0.0.1", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	// No commit since the tag touches the component
+	commitTestFile(t, repo, tempDir, "services/web/main.go", "v1", "Add web")
+	commitTestFile(t, repo, tempDir, "README.md", "v1", "Add readme")
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithComponentPath("services/api"))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.Version != "v0.0.1" || info.GitDescribe != "services/api/v0.0.1" {
+				t.Errorf("Version = %q, GitDescribe = %q, want v0.0.1 and the bare tag", info.Version, info.GitDescribe)
+			}
+			if info.CommitsSinceTag != 0 {
+				t.Errorf("CommitsSinceTag = %d, want 0", info.CommitsSinceTag)
+			}
+			v, err := info.SemVer()
+			if err != nil {
+				t.Fatalf("SemVer failed: %v", err)
+			}
+			constraint, err := semver.ParseConstraint(">=0.0.1")
+			if err != nil {
+				t.Fatalf("ParseConstraint failed: %v", err)
+			}
+			if v.String() != "0.0.1" || !constraint.Check(v) {
+				t.Errorf("SemVer = %s, want 0.0.1 satisfying >=0.0.1", v)
+			}
+		})
+	}
+}
+
+func TestComponentTagNamespace(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{"{component}/v{version}", "api/", false},
+		{"{component}-v{version}", "api-", false},
+		{"{component}@{version}", "api@", false},
+		{"{path}/{version}", "services/api/", false},
+		{"{component}/v{version}.0", "", true},
+		{"v{version}", "", true},
+		{"{name}-{component}-{version}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			result, err := ComponentTagNamespace(tt.template, "api", "services/api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ComponentTagNamespace(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ComponentTagNamespace(%q) = %q, want %q", tt.template, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoWithComponentTagNamespace(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	tagged := commitTestFile(t, repo, tempDir, "services/api/main.go", "v1", "Add api")
+	for _, name := range []string{"api-v1.0.0", "services/api/v3.0.0"} {
+		if _, err := repo.CreateTag(name, tagged, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+	commitTestFile(t, repo, tempDir, "services/api/main.go", "v2", "Change api")
+
+	info, err := GetVersionInfo(tempDir, "", WithComponentPath("services/api"), WithComponentTagNamespace("api-"))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.LatestTag != "api-v1.0.0" || !strings.HasPrefix(info.Version, "v1.0.0-1-g") {
+		t.Errorf("LatestTag, Version = %q, %q, want api-v1.0.0, v1.0.0-1-g...", info.LatestTag, info.Version)
+	}
+	next, err := info.NextVersion(semver.Minor)
+	if err != nil {
+		t.Fatalf("NextVersion failed: %v", err)
+	}
+	if next != "api-v1.1.0" {
+		t.Errorf("NextVersion() = %q, want api-v1.1.0", next)
+	}
+}
@@ -0,0 +1,239 @@
+package version
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// Ref storages reported by DebugRefs
+const (
+	RefLoose  = "loose"
+	RefPacked = "packed"
+	// RefLoosePacked is a ref in both places; the loose ref takes precedence
+	RefLoosePacked = "loose+packed"
+)
+
+// RefStatus is a ref as the go-git backend sees it and how describe
+// classifies it; see DebugRefs
+type RefStatus struct {
+	// Name is the full ref name, e.g. refs/tags/v1.0.0
+	Name string
+	// Storage is where the ref is read from: RefLoose, RefPacked or
+	// RefLoosePacked; empty for HEAD and unknown storages
+	Storage string
+	// Target is the commit the ref points to, with annotated tags peeled and
+	// symbolic refs resolved; zero if it does not point to a commit
+	Target plumbing.Hash
+	// Status is the classification, e.g. "nearest tag" or "excluded"
+	Status string
+}
+
+// DebugRefs lists every ref of the repository at repoPath with its
+// classification, to find out why a tag is not used: tags outside the tag
+// prefix, excluded, not pointing to a commit, not reachable from HEAD or
+// outranked by another tag on the same commit. It walks the whole history of
+// HEAD, or the ref of WithRef, and is meant for troubleshooting only.
+func DebugRefs(repoPath string, defaultBranch string, opts ...Option) ([]RefStatus, error) {
+	o := newOptions(opts)
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if defaultBranch == "" {
+		defaultBranch = detectDefaultBranch(repo, o)
+	}
+	excludes, err := newTagExcludes(o.tagExcludes)
+	if err != nil {
+		return nil, err
+	}
+	prefix := o.matchPrefix()
+	tags, err := commitTags(repo, prefix, excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Describe HEAD and collect its history to classify the tags
+	var nearest string
+	reachable := map[plumbing.Hash]bool{}
+	var headTarget *plumbing.Reference
+	if _, unborn := unbornBranch(repo); !unborn || o.ref != "" {
+		head, err := resolveHead(repo, o.ref)
+		if err != nil {
+			return nil, err
+		}
+		headTarget = head
+		d, err := describe(repo, head.Hash(), o)
+		if err != nil {
+			return nil, err
+		}
+		nearest = d.Tag
+		if reachable, err = history(repo, head.Hash(), o.firstParent); err != nil {
+			return nil, err
+		}
+	}
+
+	var storage func(name plumbing.ReferenceName) string
+	if fs, ok := repo.Storer.(*filesystem.Storage); ok {
+		storage = refStorage(fs.Filesystem())
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+	var statuses []RefStatus
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		s := RefStatus{Name: ref.Name().String()}
+		if storage != nil && ref.Name() != plumbing.HEAD {
+			s.Storage = storage(ref.Name())
+		}
+		name := ref.Name()
+		if ref.Type() == plumbing.SymbolicReference {
+			s.Status = "symbolic ref to " + ref.Target().String()
+			if resolved, err := repo.Reference(name, true); err == nil {
+				s.Target = resolved.Hash()
+			}
+			if name == plumbing.HEAD && headTarget != nil && o.ref != "" {
+				s.Status += fmt.Sprintf(" (versioning %s instead)", o.ref)
+			}
+			statuses = append(statuses, s)
+			return nil
+		}
+
+		s.Target = ref.Hash()
+		switch {
+		case name.IsTag():
+			s.Target, s.Status = tagStatus(repo, ref, prefix, excludes, tags, nearest, reachable)
+		case name.IsBranch():
+			s.Status = "branch"
+			if name.Short() == defaultBranch {
+				s.Status = "default branch"
+			}
+			if headTarget != nil && headTarget.Name() == name {
+				s.Status += ", checked out"
+			}
+		case name.IsRemote():
+			s.Status = "remote-tracking branch, not used for versioning"
+		default:
+			s.Status = "ignored"
+		}
+		statuses = append(statuses, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// tagStatus returns the commit of the tag and its classification by describe
+func tagStatus(repo *git.Repository, ref *plumbing.Reference, prefix string, excludes *tagExcludes, tags map[plumbing.Hash]tagCandidate, nearest string, reachable map[plumbing.Hash]bool) (plumbing.Hash, string) {
+	name := ref.Name().Short()
+	target := ref.Hash()
+	kind := "lightweight"
+	if tag, err := repo.TagObject(ref.Hash()); err == nil {
+		kind = "annotated"
+		commit, err := tag.Commit()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Sprintf("annotated, ignored: points to a %s, not a commit", tag.TargetType)
+		}
+		target = commit.Hash
+	} else if _, err := repo.CommitObject(target); err != nil {
+		obj, err := repo.Object(plumbing.AnyObject, target)
+		if err != nil {
+			return plumbing.ZeroHash, "lightweight, ignored: target is missing"
+		}
+		return plumbing.ZeroHash, fmt.Sprintf("lightweight, ignored: points to a %s, not a commit", obj.Type())
+	}
+
+	switch {
+	case !strings.HasPrefix(name, prefix):
+		return target, fmt.Sprintf("%s, ignored: does not start with the tag prefix %q", kind, prefix)
+	case excludes.match(name, prefix):
+		return target, kind + ", ignored: excluded by a tag exclude pattern"
+	case tags[target].name != name:
+		return target, fmt.Sprintf("%s, ignored: %s on the same commit is preferred", kind, tags[target].name)
+	case name == nearest:
+		return target, kind + ", nearest tag"
+	case !reachable[target]:
+		return target, kind + ", candidate, not reachable from HEAD"
+	default:
+		return target, kind + ", candidate, farther from HEAD than the nearest tag"
+	}
+}
+
+// history returns the commits reachable from hash, following only first
+// parents if firstParent is set
+func history(repo *git.Repository, hash plumbing.Hash, firstParent bool) (map[plumbing.Hash]bool, error) {
+	nodes, release := commitNodes(repo)
+	defer release()
+	seen := map[plumbing.Hash]bool{}
+	queue := []plumbing.Hash{hash}
+	for len(queue) > 0 {
+		h := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if seen[h] {
+			continue
+		}
+		node, err := nodes.Get(h)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			// Missing parent, e.g. the boundary of a shallow clone
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen[h] = true
+		queue = append(queue, followedParents(node, firstParent)...)
+	}
+	return seen, nil
+}
+
+// followedParents returns the parents of the commit, or only its first parent if
+// firstParent is set
+func followedParents(node commitgraph.CommitNode, firstParent bool) []plumbing.Hash {
+	p := node.ParentHashes()
+	if firstParent && len(p) > 1 {
+		return p[:1]
+	}
+	return p
+}
+
+// refStorage returns the function reporting whether a ref is stored as a
+// loose file, in packed-refs or both
+func refStorage(fs billy.Filesystem) func(plumbing.ReferenceName) string {
+	packed := map[plumbing.ReferenceName]bool{}
+	if f, err := fs.Open("packed-refs"); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			// Lines are "<hash> <name>", "^<peeled hash>" or "# <traits>"
+			if _, name, ok := strings.Cut(scanner.Text(), " "); ok && !strings.HasPrefix(scanner.Text(), "#") {
+				packed[plumbing.ReferenceName(name)] = true
+			}
+		}
+		f.Close()
+	}
+	return func(name plumbing.ReferenceName) string {
+		fi, err := fs.Stat(name.String())
+		loose := err == nil && !fi.IsDir()
+		switch {
+		case loose && packed[name]:
+			return RefLoosePacked
+		case loose:
+			return RefLoose
+		case packed[name]:
+			return RefPacked
+		}
+		return ""
+	}
+}
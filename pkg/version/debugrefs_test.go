@@ -0,0 +1,100 @@
+package version
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestDebugRefs(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "a", "A")
+	for _, name := range []string{"v1.0.0", "old"} {
+		if _, err := repo.CreateTag(name, first, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+	if _, err := repo.CreateTag("v1.0.1", first, &git.CreateTagOptions{Tagger: testSignature(), Message: "v1.0.1"}); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "b", "B")
+	if _, err := repo.CreateTag("v2.0.0-rc1", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	// A tag on a branch that is not merged
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := w.Checkout(&git.CheckoutOptions{Branch: "refs/heads/feature", Create: true}); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	feature := commitTestFile(t, repo, tempDir, "test.txt", "c", "C")
+	if _, err := repo.CreateTag("v3.0.0", feature, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if err := w.Checkout(&git.CheckoutOptions{Branch: "refs/heads/master"}); err != nil {
+		t.Fatalf("Failed to check out master: %v", err)
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/master", head)); err != nil {
+		t.Fatalf("Failed to create remote ref: %v", err)
+	}
+	if hasGit() {
+		if out, err := exec.Command("git", "-C", tempDir, "pack-refs", "--all").CombinedOutput(); err != nil {
+			t.Fatalf("Failed to pack refs: %v: %s", err, out)
+		}
+		// Rewritten as a loose ref, which takes precedence
+		if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/tags/old", first)); err != nil {
+			t.Fatalf("Failed to write loose ref: %v", err)
+		}
+	}
+
+	refs, err := DebugRefs(tempDir, "", WithTagPrefix("v"), WithTagExcludes("*-rc*"))
+	if err != nil {
+		t.Fatalf("DebugRefs failed: %v", err)
+	}
+	got := map[string]RefStatus{}
+	for _, ref := range refs {
+		got[ref.Name] = ref
+	}
+
+	tests := []struct {
+		name   string
+		target plumbing.Hash
+		status string
+	}{
+		{"HEAD", head, "symbolic ref to refs/heads/master"},
+		{"refs/heads/feature", feature, "branch"},
+		{"refs/heads/master", head, "default branch, checked out"},
+		{"refs/remotes/origin/master", head, "remote-tracking branch, not used for versioning"},
+		{"refs/tags/old", first, `lightweight, ignored: does not start with the tag prefix "v"`},
+		{"refs/tags/v1.0.0", first, "lightweight, ignored: v1.0.1 on the same commit is preferred"},
+		{"refs/tags/v1.0.1", first, "annotated, nearest tag"},
+		{"refs/tags/v2.0.0-rc1", head, "lightweight, ignored: excluded by a tag exclude pattern"},
+		{"refs/tags/v3.0.0", feature, "lightweight, candidate, not reachable from HEAD"},
+	}
+	if len(refs) != len(tests) {
+		t.Errorf("DebugRefs returned %d refs, want %d: %v", len(refs), len(tests), refs)
+	}
+	for _, tt := range tests {
+		ref, ok := got[tt.name]
+		if !ok {
+			t.Errorf("%s missing", tt.name)
+			continue
+		}
+		if ref.Target != tt.target || ref.Status != tt.status {
+			t.Errorf("%s = %s %q, want %s %q", tt.name, ref.Target, ref.Status, tt.target, tt.status)
+		}
+	}
+	if hasGit() {
+		if s := got["refs/tags/v1.0.0"].Storage; s != RefPacked {
+			t.Errorf("Storage of packed tag = %q, want %q", s, RefPacked)
+		}
+		if s := got["refs/tags/old"].Storage; s != RefLoosePacked {
+			t.Errorf("Storage of repacked tag = %q, want %q", s, RefLoosePacked)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+
+	"github.com/fxsml/gitversion/pkg/revwalk"
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// DescribeOption configures Describe. It shares the options of GetVersionInfo,
+// of which WithTagPrefix, WithTagExcludes, WithComponentPath, WithFirstParent
+// and WithMaxDescribeDepth take effect.
+type DescribeOption = Option
+
+// Description is the result of Describe
+type Description struct {
+	// Tag is the nearest tag, empty if no tag is reachable
+	Tag string `json:"tag"`
+	// TagHash is the commit Tag points to
+	TagHash plumbing.Hash `json:"tagHash"`
+	// Distance is the number of commits since Tag, or since the root commit if
+	// no tag is reachable
+	Distance int `json:"distance"`
+	// Truncated reports that the search gave up after the commits of
+	// WithMaxDescribeDepth without finding a tag; Distance is zero then
+	Truncated bool `json:"truncated,omitempty"`
+	// Hash is the described commit
+	Hash plumbing.Hash `json:"hash"`
+}
+
+// String returns the description in git describe format, e.g. v1.0.0 or
+// v1.0.0-5-g1234567, or an empty string if no tag is reachable
+func (d Description) String() string {
+	return d.format(d.Hash.String()[:defaultAbbrev])
+}
+
+// format returns the description in git describe format with the given
+// abbreviated hash. A commit after the tag without commits counted since, e.g.
+// none touching the component path, is described by the bare tag.
+func (d Description) format(shortHash string) string {
+	if d.Tag == "" {
+		return ""
+	}
+	if d.TagHash == d.Hash || d.Distance == 0 {
+		return d.Tag
+	}
+	return fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, shortHash)
+}
+
+// Describe finds the nearest tag reachable from the given commit, similar to
+// 'git describe --tags --match <prefix>*'. If several tags are equally near,
+// annotated tags are preferred over lightweight ones, then the highest SemVer
+// precedence and finally the lexically smallest name.
+func Describe(repo *git.Repository, hash plumbing.Hash, opts ...DescribeOption) (Description, error) {
+	return describe(repo, hash, newOptions(opts))
+}
+
+// describe implements Describe for resolved options.
+// If a component path is set, only commits touching that path count towards the distance
+// If first-parent is set, only the first parent of merge commits is followed
+func describe(repo *git.Repository, hash plumbing.Hash, o *options) (Description, error) {
+	d := Description{Hash: hash}
+
+	excludes, err := newTagExcludes(o.tagExcludes)
+	if err != nil {
+		return d, err
+	}
+	stop := o.phase(PhaseTags)
+	tags, err := commitTags(repo, o.matchPrefix(), excludes)
+	stop()
+	if err != nil {
+		return d, err
+	}
+	o.log().Debug("tagged commits considered", "count", len(tags), "prefix", o.matchPrefix(), "excludes", o.tagExcludes)
+
+	defer o.phase(PhaseDescribe)()
+	nodes, release := commitNodes(repo)
+	defer release()
+	tag, tagHash, err := nearestTag(o.ctx, nodes, hash, tags, o.firstParent, o.maxDescribeDepth)
+	if errors.Is(err, errDescribeDepth) {
+		d.Truncated = true
+		return d, nil
+	}
+	if err != nil {
+		return d, fmt.Errorf("failed to walk history of %s: %w", hash, err)
+	}
+	d.Tag, d.TagHash = tag.name, tagHash
+	if tag.name != "" {
+		o.log().Debug("nearest tag chosen", "tag", tag.name, "annotated", tag.annotated, "semver", tag.isSemVer, "firstParent", o.firstParent)
+	}
+	if tagHash == hash {
+		return d, nil
+	}
+
+	// Count the commits since the tag, or all commits if there is none
+	d.Distance, err = revwalk.Count(o.ctx, nodes, hash, tagHash, o.firstParent, o.componentFilter())
+	// Missing parents end the walk like the root commit, e.g. in shallow clones
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return d, fmt.Errorf("failed to walk history of %s: %w", hash, err)
+	}
+	o.log().Debug("commits counted", "distance", d.Distance, "since", d.Tag, "firstParent", o.firstParent, "componentPath", o.componentPath)
+	return d, nil
+}
+
+// errDescribeDepth is returned by nearestTag if no tag is found within the
+// maximum describe depth
+var errDescribeDepth = errors.New("no tag within the maximum describe depth")
+
+// nearestTag searches the history of hash breadth-first and returns the
+// preferred tag among the tagged commits with the fewest parent hops, and the
+// commit it points to. The zero values are returned if no tag is reachable.
+// If maxDepth is positive, errDescribeDepth is returned once that many
+// commits were searched without a tag and more history is left.
+func nearestTag(ctx context.Context, nodes commitgraph.CommitNodeIndex, hash plumbing.Hash, tags map[plumbing.Hash]tagCandidate, firstParent bool, maxDepth int) (tagCandidate, plumbing.Hash, error) {
+	level := []plumbing.Hash{hash}
+	seen := map[plumbing.Hash]bool{hash: true}
+	searched := 0
+
+	for len(level) > 0 {
+		var best tagCandidate
+		var bestHash plumbing.Hash
+		for _, h := range level {
+			if tag, ok := tags[h]; ok && (best.name == "" || tag.preferred(best)) {
+				best, bestHash = tag, h
+			}
+		}
+		if best.name != "" {
+			return best, bestHash, nil
+		}
+
+		var next []plumbing.Hash
+		for _, h := range level {
+			if err := ctx.Err(); err != nil {
+				return tagCandidate{}, plumbing.ZeroHash, err
+			}
+			node, err := nodes.Get(h)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				// Missing parent, e.g. the boundary of a shallow clone
+				continue
+			}
+			if err != nil {
+				return tagCandidate{}, plumbing.ZeroHash, err
+			}
+			parents := node.ParentHashes()
+			if firstParent && len(parents) > 1 {
+				parents = parents[:1]
+			}
+			for _, p := range parents {
+				if !seen[p] {
+					seen[p] = true
+					next = append(next, p)
+				}
+			}
+		}
+		searched += len(level)
+		if maxDepth > 0 && searched >= maxDepth && len(next) > 0 {
+			return tagCandidate{}, plumbing.ZeroHash, errDescribeDepth
+		}
+		level = next
+	}
+	return tagCandidate{}, plumbing.ZeroHash, nil
+}
+
+// tagCandidate is a tag considered by describe
+type tagCandidate struct {
+	name      string
+	annotated bool
+	// version is the SemVer of the tag without prefix, if it is one
+	version  semver.Version
+	isSemVer bool
+}
+
+// newTagCandidate creates a candidate for the tag name, parsing it as SemVer
+// after removing prefix
+func newTagCandidate(name, prefix string, annotated bool) tagCandidate {
+	t := tagCandidate{name: name, annotated: annotated}
+	if v, err := semver.Parse(strings.TrimPrefix(name, prefix)); err == nil {
+		t.version, t.isSemVer = v, true
+	}
+	return t
+}
+
+// preferred reports whether t is preferred over o: annotated tags first, then
+// SemVer tags by descending precedence and finally by name
+func (t tagCandidate) preferred(o tagCandidate) bool {
+	if t.annotated != o.annotated {
+		return t.annotated
+	}
+	if t.isSemVer != o.isSemVer {
+		return t.isSemVer
+	}
+	if t.isSemVer {
+		if c := t.version.Compare(o.version); c != 0 {
+			return c > 0
+		}
+	}
+	return t.name < o.name
+}
+
+// commitTags maps commits to the preferred tag starting with prefix and not
+// excluded that points to them. Annotated tags point to tag objects and are
+// peeled to their target commit.
+func commitTags(repo *git.Repository, prefix string, excludes *tagExcludes) (map[plumbing.Hash]tagCandidate, error) {
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	tags := make(map[plumbing.Hash]tagCandidate)
+	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if !strings.HasPrefix(name, prefix) || excludes.match(name, prefix) {
+			return nil
+		}
+
+		commitHash := ref.Hash()
+		isAnnotated := false
+		if tagObj, err := repo.TagObject(ref.Hash()); err == nil {
+			commit, err := tagObj.Commit()
+			if err != nil {
+				// Tag does not point to a commit (e.g. a tree or blob)
+				return nil
+			}
+			commitHash = commit.Hash
+			isAnnotated = true
+		}
+
+		tag := newTagCandidate(name, prefix, isAnnotated)
+		if existing, exists := tags[commitHash]; exists && !tag.preferred(existing) {
+			return nil
+		}
+		tags[commitHash] = tag
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// CommitHeight returns the number of commits since the nearest tag reachable
+// from the given commit, or the total number of commits if there is none
+func CommitHeight(repo *git.Repository, hash plumbing.Hash, opts ...DescribeOption) (int, error) {
+	d, err := Describe(repo, hash, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return d.Distance, nil
+}
+
+// CountCommits returns the number of commits reachable from hash but not from
+// the revision since, e.g. a tag, or the number of all commits reachable from
+// hash if since is empty. As for the describe distance, only commits touching
+// the path of WithComponentPath count and WithFirstParent follows only the
+// first parent of merge commits.
+func CountCommits(repo *git.Repository, hash plumbing.Hash, since string, opts ...DescribeOption) (int, error) {
+	o := newOptions(opts)
+	var sinceHash plumbing.Hash
+	if since != "" {
+		h, err := repo.ResolveRevision(plumbing.Revision(since))
+		if err != nil {
+			return 0, fmt.Errorf("failed to resolve %s: %w", since, err)
+		}
+		sinceHash = *h
+	}
+	nodes, release := commitNodes(repo)
+	defer release()
+	return revwalk.Count(o.ctx, nodes, hash, sinceHash, o.firstParent, o.componentFilter())
+}
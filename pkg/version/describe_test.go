@@ -0,0 +1,205 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestDescribe(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+
+	d, err := Describe(repo, head)
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if d.Tag != "v1.0.0" || d.TagHash != first || d.Distance != 2 || d.Hash != head {
+		t.Errorf("Describe = %+v, want tag v1.0.0 at %s with distance 2", d, first)
+	}
+	if expected := "v1.0.0-2-g" + head.String()[:7]; d.String() != expected {
+		t.Errorf("String() = %q, want %q", d.String(), expected)
+	}
+
+	d, err = Describe(repo, first)
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if d.String() != "v1.0.0" || d.Distance != 0 {
+		t.Errorf("Describe at tag = %+v, want v1.0.0 with distance 0", d)
+	}
+
+	d, err = Describe(repo, head, WithTagPrefix("release/"))
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if d.Tag != "" || d.String() != "" || d.Distance != 3 {
+		t.Errorf("Describe without matching tags = %+v, want no tag with distance 3", d)
+	}
+}
+
+func TestDescribeMaxDepth(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v4", "Fourth commit")
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			// The tag is on the fourth commit searched
+			info, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithMaxDescribeDepth(4))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.LatestTag != "v1.0.0" || info.CommitsSinceTag != 3 || info.DescribeTruncated {
+				t.Errorf("describe = %s-%d truncated %v, want v1.0.0-3", info.LatestTag, info.CommitsSinceTag, info.DescribeTruncated)
+			}
+
+			info, err = GetVersionInfo(tempDir, "", WithBackend(backend), WithMaxDescribeDepth(3), WithStrategy(Height()))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if !info.DescribeTruncated || info.LatestTag != "" || info.CommitsSinceTag != 0 {
+				t.Errorf("describe = %q-%d truncated %v, want truncated without tag", info.LatestTag, info.CommitsSinceTag, info.DescribeTruncated)
+			}
+			if expected := info.GitBranchSlug + "-g" + head.String()[:7]; info.Version != expected {
+				t.Errorf("Version = %q, want %q regardless of the strategy", info.Version, expected)
+			}
+		})
+	}
+
+	// A history without tags shorter than the depth is searched completely
+	d, err := Describe(repo, head, WithTagPrefix("release/"), WithMaxDescribeDepth(10))
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if d.Truncated || d.Distance != 4 {
+		t.Errorf("Describe = %+v, want no tag with distance 4", d)
+	}
+}
+
+func TestDescribeTagPrecedence(t *testing.T) {
+	tempDir, repo, hashes := createMergeHistory(t)
+	b, m := hashes[1], hashes[3]
+
+	// B and C are equally near to M: the higher SemVer tag on C wins and only
+	// M and B count towards the distance, as A is an ancestor of C
+	if _, err := repo.CreateTag("v1.0.0", b, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	d, err := Describe(repo, m)
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if d.Tag != "v2.0.0-feature" || d.Distance != 2 {
+		t.Errorf("Describe = %+v, want v2.0.0-feature with distance 2", d)
+	}
+
+	// Several tags on the same commit
+	head := commitTestFile(t, repo, tempDir, "test.txt", "c", "D")
+	for _, name := range []string{"nightly", "v1.1.0", "v1.10.0", "v1.2.0"} {
+		if _, err := repo.CreateTag(name, head, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+	d, err = Describe(repo, head)
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if d.Tag != "v1.10.0" {
+		t.Errorf("Tag = %q, want highest SemVer tag v1.10.0", d.Tag)
+	}
+
+	opts := &git.CreateTagOptions{Tagger: testSignature(), Message: "Release"}
+	if _, err := repo.CreateTag("v0.9.0", head, opts); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	d, err = Describe(repo, head)
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if d.Tag != "v0.9.0" {
+		t.Errorf("Tag = %q, want annotated tag v0.9.0", d.Tag)
+	}
+}
+
+func TestCommitHeight(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	second := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+
+	height, err := CommitHeight(repo, head)
+	if err != nil {
+		t.Fatalf("CommitHeight failed: %v", err)
+	}
+	if height != 3 {
+		t.Errorf("CommitHeight without tags = %d, want 3", height)
+	}
+
+	if _, err := repo.CreateTag("v1.0.0", second, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	height, err = CommitHeight(repo, head)
+	if err != nil {
+		t.Fatalf("CommitHeight failed: %v", err)
+	}
+	if height != 1 {
+		t.Errorf("CommitHeight = %d, want 1", height)
+	}
+}
+
+func TestCountCommitsSinceRevision(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	commitTestFile(t, repo, tempDir, "libs/core/core.go", "v1", "Add core")
+	tagged := commitTestFile(t, repo, tempDir, "services/api/main.go", "v1", "Add api")
+	if _, err := repo.CreateTag("services/api/v1.0.0", tagged, &git.CreateTagOptions{Tagger: testSignature(), Message: "v1.0.0"}); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "services/api/main.go", "v2", "Change api")
+	head := commitTestFile(t, repo, tempDir, "libs/core/core.go", "v2", "Change core")
+
+	tests := []struct {
+		name     string
+		since    string
+		opts     []DescribeOption
+		expected int
+	}{
+		{"whole history", "", nil, 4},
+		{"since annotated tag", "services/api/v1.0.0", nil, 2},
+		{"path since tag", "services/api/v1.0.0", []DescribeOption{WithComponentPath("libs/core")}, 1},
+		{"path without changes since tag", "services/api/v1.0.0", []DescribeOption{WithComponentPath("libs/other")}, 0},
+		{"path in whole history", "", []DescribeOption{WithComponentPath("libs/core")}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := CountCommits(repo, head, tt.since, tt.opts...)
+			if err != nil {
+				t.Fatalf("CountCommits failed: %v", err)
+			}
+			if count != tt.expected {
+				t.Errorf("CountCommits = %d, want %d", count, tt.expected)
+			}
+		})
+	}
+
+	if _, err := CountCommits(repo, head, "missing"); err == nil {
+		t.Error("CountCommits since a missing revision expected error")
+	}
+}
@@ -0,0 +1,340 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// dirtyFilter selects the paths whose changes count in the dirty check
+type dirtyFilter struct {
+	// paths limits the check to these paths and the paths below them, if any
+	paths []string
+	// excludes are the paths ignored in the check
+	excludes []string
+}
+
+// dirtyFilter returns the filter of the dirty check. With a component path
+// only changes below it and below the shared paths count.
+func (o *options) dirtyFilter() dirtyFilter {
+	f := dirtyFilter{excludes: o.dirtyExcludes}
+	if o.componentPath != "" {
+		f.paths = append([]string{o.componentPath}, o.sharedPaths...)
+	}
+	return f
+}
+
+// skip reports whether changes to the file do not count
+func (f dirtyFilter) skip(name string) bool {
+	if isExcluded(name, f.excludes) {
+		return true
+	}
+	return len(f.paths) > 0 && !isExcluded(name, f.paths)
+}
+
+// skipDir reports whether changes to all files below the directory do not count
+func (f dirtyFilter) skipDir(name string) bool {
+	if isExcluded(name, f.excludes) {
+		return true
+	}
+	if len(f.paths) == 0 || isExcluded(name, f.paths) {
+		return false
+	}
+	for _, p := range f.paths {
+		// Globs may match base names anywhere
+		if strings.HasPrefix(p, name+"/") || strings.ContainsAny(p, "*?[") {
+			return false
+		}
+	}
+	return true
+}
+
+// isDirty checks for uncommitted changes and, if enabled, untracked files
+func isDirty(ctx context.Context, repo *git.Repository, o *options) (bool, error) {
+	filter := o.dirtyFilter()
+	dirty, err := hasUncommittedChanges(ctx, repo, filter)
+	if dirty {
+		o.log().Debug("staged or unstaged changes to tracked files")
+	}
+	if err != nil || dirty || !o.dirtyUntracked {
+		return dirty, err
+	}
+	dirty, err = hasUntrackedFiles(ctx, repo, filter)
+	if dirty {
+		o.log().Debug("untracked files")
+	}
+	return dirty, err
+}
+
+// hasUncommittedChanges checks if the repository has uncommitted changes
+// Only checks for staged and unstaged modifications, not untracked files
+// Unlike worktree.Status() it stops at the first modification found and
+// skips paths the filter skips
+// Errors reading the repository count as clean, only the context's error is returned
+func hasUncommittedChanges(ctx context.Context, repo *git.Repository, filter dirtyFilter) (bool, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, nil
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return false, nil
+	}
+
+	staged, err := hasStagedChanges(ctx, repo, idx, filter)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return false, ctxErr
+	}
+	if err != nil || staged {
+		return staged, nil
+	}
+
+	for _, entry := range idx.Entries {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if filter.skip(entry.Name) {
+			continue
+		}
+		if isModified(worktree.Filesystem, entry) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// hasStagedChanges compares the index with the HEAD tree
+func hasStagedChanges(ctx context.Context, repo *git.Repository, idx *index.Index, filter dirtyFilter) (bool, error) {
+	indexEntries := make(map[string]*index.Entry, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		if filter.skip(entry.Name) {
+			continue
+		}
+		// Unmerged entries (stage != 0) and intent-to-add entries are changes.
+		// Note that index.Merged is 1 in go-git while merged entries have stage 0.
+		if entry.Stage != 0 || entry.IntentToAdd {
+			return true, nil
+		}
+		indexEntries[entry.Name] = entry
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		// No commits yet: everything in the index is staged
+		return len(indexEntries) > 0, nil
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return false, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+
+	seen := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return false, err
+		}
+		if entry.Mode == filemode.Dir || filter.skip(name) {
+			continue
+		}
+
+		indexEntry, ok := indexEntries[name]
+		if !ok || indexEntry.Hash != entry.Hash || indexEntry.Mode != entry.Mode {
+			// Staged deletion or modification
+			return true, nil
+		}
+		seen++
+	}
+
+	// Entries in the index but not in HEAD are staged additions
+	return seen != len(indexEntries), nil
+}
+
+// hasUntrackedFiles reports whether the worktree contains files that are not
+// in the index and not ignored, stopping at the first one found. Ignored
+// directories are not descended into.
+// Errors reading the repository count as clean, only the context's error is returned
+func hasUntrackedFiles(ctx context.Context, repo *git.Repository, filter dirtyFilter) (bool, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, nil
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return false, nil
+	}
+
+	tracked := make(map[string]bool, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		tracked[entry.Name] = true
+	}
+
+	// Repository patterns take precedence over core.excludesFile
+	patterns, _ := gitignore.LoadGlobalPatterns(osfs.New("/"))
+	if repoPatterns, err := gitignore.ReadPatterns(worktree.Filesystem, nil); err == nil {
+		patterns = append(patterns, repoPatterns...)
+	}
+
+	u := untrackedWalk{
+		ctx:     ctx,
+		fs:      worktree.Filesystem,
+		tracked: tracked,
+		ignored: gitignore.NewMatcher(patterns),
+		filter:  filter,
+	}
+	return u.walk("")
+}
+
+// untrackedWalk searches the worktree for untracked files
+type untrackedWalk struct {
+	ctx     context.Context
+	fs      billy.Filesystem
+	tracked map[string]bool
+	ignored gitignore.Matcher
+	filter  dirtyFilter
+}
+
+// walk reports whether the directory contains an untracked file
+func (u *untrackedWalk) walk(dir string) (bool, error) {
+	if err := u.ctx.Err(); err != nil {
+		return false, err
+	}
+	entries, err := u.fs.ReadDir(dir)
+	if err != nil {
+		return false, nil
+	}
+
+	for _, fi := range entries {
+		name := path.Join(dir, fi.Name())
+		// Tracked submodules are index entries as well
+		if name == ".git" || u.tracked[name] {
+			continue
+		}
+		if fi.IsDir() && u.filter.skipDir(name) || !fi.IsDir() && u.filter.skip(name) {
+			continue
+		}
+		if u.ignored.Match(strings.Split(name, "/"), fi.IsDir()) {
+			continue
+		}
+		if !fi.IsDir() {
+			return true, nil
+		}
+		if untracked, err := u.walk(name); err != nil || untracked {
+			return untracked, err
+		}
+	}
+	return false, nil
+}
+
+// isModified compares an index entry with the file in the worktree
+func isModified(fs billy.Filesystem, entry *index.Entry) bool {
+	if entry.SkipWorktree || entry.Mode == filemode.Submodule {
+		return false
+	}
+
+	fi, err := fs.Lstat(entry.Name)
+	if err != nil {
+		// Deleted in the worktree
+		return true
+	}
+
+	mode, err := filemode.NewFromOSFileMode(fi.Mode())
+	if err != nil {
+		return true
+	}
+	if mode != entry.Mode {
+		// Type or executable bit changed
+		return true
+	}
+
+	if mode != filemode.Symlink {
+		if uint32(fi.Size()) != entry.Size {
+			return true
+		}
+		if fi.ModTime().Equal(entry.ModifiedAt) {
+			// Unchanged stat data: trust the index like git does
+			return false
+		}
+	}
+
+	// Stat data differs, compare the content hash
+	hash, err := worktreeBlobHash(fs, entry.Name, mode)
+	if err != nil {
+		return true
+	}
+	return hash != entry.Hash
+}
+
+// worktreeBlobHash computes the blob hash of a worktree file or symlink
+func worktreeBlobHash(fs billy.Filesystem, name string, mode filemode.FileMode) (plumbing.Hash, error) {
+	var content []byte
+	if mode == filemode.Symlink {
+		target, err := fs.Readlink(name)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		content = []byte(target)
+	} else {
+		f, err := fs.Open(name)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		defer f.Close()
+
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, f); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		content = buf.Bytes()
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, content), nil
+}
+
+// isExcluded reports whether the slash-separated path matches one of the
+// exclude patterns. A pattern matches a path equal to it, any path below it
+// (e.g. "vendor/" or "vendor") and, if it contains glob characters, a path or
+// base name it matches (e.g. "*.lock").
+func isExcluded(name string, excludes []string) bool {
+	for _, pattern := range excludes {
+		p := strings.TrimSuffix(pattern, "/")
+		if p == "" {
+			continue
+		}
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
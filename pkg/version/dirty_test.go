@@ -0,0 +1,320 @@
+package version
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHasUncommittedChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(t *testing.T, dir string)
+		excludes []string
+		expected bool
+	}{
+		{
+			name:     "clean",
+			modify:   func(t *testing.T, dir string) {},
+			expected: false,
+		},
+		{
+			name: "untracked file",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "new.txt"), "new")
+			},
+			expected: false,
+		},
+		{
+			name: "modified file",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "test.txt"), "modified")
+			},
+			expected: true,
+		},
+		{
+			name: "touched file with same content",
+			modify: func(t *testing.T, dir string) {
+				later := time.Now().Add(time.Hour)
+				if err := os.Chtimes(filepath.Join(dir, "test.txt"), later, later); err != nil {
+					t.Fatalf("Failed to touch file: %v", err)
+				}
+			},
+			expected: false,
+		},
+		{
+			name: "deleted file",
+			modify: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "test.txt")); err != nil {
+					t.Fatalf("Failed to remove file: %v", err)
+				}
+			},
+			expected: true,
+		},
+		{
+			name: "modified excluded directory",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "vendor", "lib.go"), "modified")
+			},
+			excludes: []string{"vendor/"},
+			expected: false,
+		},
+		{
+			name: "modified excluded glob",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "vendor", "lib.go"), "modified")
+			},
+			excludes: []string{"*.go"},
+			expected: false,
+		},
+		{
+			name: "modified outside excludes",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "vendor", "lib.go"), "modified")
+				writeFile(t, filepath.Join(dir, "test.txt"), "modified")
+			},
+			excludes: []string{"vendor"},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir, repo := initTestRepo(t)
+			commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+			commitTestFile(t, repo, tempDir, "vendor/lib.go", "lib", "Add vendor")
+
+			tt.modify(t, tempDir)
+
+			if result, _ := hasUncommittedChanges(context.Background(), repo, dirtyFilter{excludes: tt.excludes}); result != tt.expected {
+				t.Errorf("hasUncommittedChanges() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasUncommittedChangesStaged(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+
+	// Stage a new file without committing it
+	writeFile(t, filepath.Join(tempDir, "staged.txt"), "staged")
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add("staged.txt"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+
+	if dirty, _ := hasUncommittedChanges(context.Background(), repo, dirtyFilter{}); !dirty {
+		t.Error("hasUncommittedChanges() should report staged additions")
+	}
+	if dirty, _ := hasUncommittedChanges(context.Background(), repo, dirtyFilter{excludes: []string{"staged.txt"}}); dirty {
+		t.Error("hasUncommittedChanges() should ignore excluded staged additions")
+	}
+}
+
+func TestGetVersionInfoWithoutDirtyCheck(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+	writeFile(t, filepath.Join(tempDir, "test.txt"), "modified")
+
+	info, err := GetVersionInfo(tempDir, "", WithDirtyCheck(false))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.IsDirty {
+		t.Error("IsDirty should be false when the dirty check is disabled")
+	}
+}
+
+func TestHasUntrackedFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(t *testing.T, dir string)
+		excludes []string
+		expected bool
+	}{
+		{
+			name:     "clean",
+			modify:   func(t *testing.T, dir string) {},
+			expected: false,
+		},
+		{
+			name: "untracked file",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "new.txt"), "new")
+			},
+			expected: true,
+		},
+		{
+			name: "untracked file in subdirectory",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "vendor", "new", "lib.go"), "new")
+			},
+			expected: true,
+		},
+		{
+			name: "ignored file",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "vendor", "app.log"), "log")
+			},
+			expected: false,
+		},
+		{
+			name: "ignored directory",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "build", "out", "app"), "binary")
+			},
+			expected: false,
+		},
+		{
+			name: "info exclude",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, ".git", "info", "exclude"), "*.tmp\n")
+				writeFile(t, filepath.Join(dir, "scratch.tmp"), "tmp")
+			},
+			expected: false,
+		},
+		{
+			name: "excluded path",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "vendor", "new.go"), "new")
+			},
+			excludes: []string{"vendor/"},
+			expected: false,
+		},
+		{
+			name: "empty directory",
+			modify: func(t *testing.T, dir string) {
+				if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir, repo := initTestRepo(t)
+			commitTestFile(t, repo, tempDir, ".gitignore", "*.log\n/build/\n", "Initial commit")
+			commitTestFile(t, repo, tempDir, "vendor/lib.go", "lib", "Add vendor")
+
+			tt.modify(t, tempDir)
+
+			if result, _ := hasUntrackedFiles(context.Background(), repo, dirtyFilter{excludes: tt.excludes}); result != tt.expected {
+				t.Errorf("hasUntrackedFiles() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoWithDirtyUntracked(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+	writeFile(t, filepath.Join(tempDir, "new.txt"), "new")
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", WithBackend(backend))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.IsDirty {
+				t.Error("IsDirty should be false for untracked files by default")
+			}
+
+			info, err = GetVersionInfo(tempDir, "", WithBackend(backend), WithDirtyUntracked(true))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if !info.IsDirty {
+				t.Error("IsDirty should be true for untracked files with WithDirtyUntracked")
+			}
+
+			info, err = GetVersionInfo(tempDir, "", WithBackend(backend), WithDirtyUntracked(true), WithDirtyExcludes("new.txt"))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.IsDirty {
+				t.Error("IsDirty should be false for excluded untracked files")
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoDirtyComponentPath(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	files := map[string]string{"a/a.txt": "a", "b/b.txt": "b", "go.mod": "module x"}
+	for name, content := range files {
+		commitTestFile(t, repo, tempDir, name, content, "Add "+name)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			dirty := func(modify string, opts ...Option) bool {
+				t.Helper()
+				writeFile(t, filepath.Join(tempDir, modify), "modified")
+				defer writeFile(t, filepath.Join(tempDir, modify), files[modify])
+				info, err := GetVersionInfo(tempDir, "", append([]Option{WithBackend(backend), WithComponentPath("a")}, opts...)...)
+				if err != nil {
+					t.Fatalf("GetVersionInfo failed: %v", err)
+				}
+				return info.IsDirty
+			}
+			if !dirty("a/a.txt") {
+				t.Error("IsDirty should be true for changes to the component")
+			}
+			if dirty("b/b.txt") {
+				t.Error("IsDirty should be false for changes to another component")
+			}
+			if dirty("go.mod") {
+				t.Error("IsDirty should be false for changes outside the component")
+			}
+			if !dirty("go.mod", WithSharedPaths("go.mod")) {
+				t.Error("IsDirty should be true for changes to a shared path")
+			}
+		})
+	}
+
+	// Untracked files are scoped as well
+	writeFile(t, filepath.Join(tempDir, "b", "new.txt"), "new")
+	info, err := GetVersionInfo(tempDir, "", WithComponentPath("a"), WithDirtyUntracked(true))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.IsDirty {
+		t.Error("IsDirty should be false for untracked files of another component")
+	}
+	writeFile(t, filepath.Join(tempDir, "a", "new.txt"), "new")
+	info, err = GetVersionInfo(tempDir, "", WithComponentPath("a"), WithDirtyUntracked(true))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if !info.IsDirty {
+		t.Error("IsDirty should be true for untracked files of the component")
+	}
+}
+
+// writeFile writes content to path, creating parent directories
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
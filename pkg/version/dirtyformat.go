@@ -0,0 +1,100 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DirtyFormatTimestamp appends the current time, e.g. -20240102150405
+	DirtyFormatTimestamp = "-{timestamp}"
+	// DirtyFormatHash appends a short hash of the tracked files in the working
+	// tree, e.g. -dirty-3f2a9c1, so the same changes always yield the same
+	// version
+	DirtyFormatHash = "-dirty-{hash}"
+)
+
+// dirtyFormats are the named dirty formats accepted by ParseDirtyFormat
+var dirtyFormats = map[string]string{
+	"timestamp": DirtyFormatTimestamp,
+	"hash":      DirtyFormatHash,
+}
+
+// ParseDirtyFormat resolves a dirty format: "timestamp", "hash" or a template
+// with {placeholder} fields, e.g. "-dirty" or "+dirty.{sha}":
+//   - timestamp: the current UTC time as YYYYMMDDHHMMSS
+//   - hash: a 7 character hash of the tracked files in the working tree
+//   - sha: the abbreviated commit hash
+//
+// A suffix starting with + is added to the build metadata, any other suffix
+// is inserted before it. Besides the leading + the suffix may only contain
+// the characters of SemVer identifiers, [0-9A-Za-z.-].
+func ParseDirtyFormat(s string) (string, error) {
+	if format, ok := dirtyFormats[s]; ok {
+		return format, nil
+	}
+	if s == "" {
+		return "", fmt.Errorf("invalid dirty format: empty")
+	}
+	suffix, err := expandTemplate(s, func(name string) (string, error) {
+		return "", checkDirtyPlaceholder(name)
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid dirty format %q: %w", s, err)
+	}
+	if !dirtySuffixPattern.MatchString(suffix) {
+		return "", fmt.Errorf("invalid dirty format %q: only [0-9A-Za-z.-] and a leading + are allowed", s)
+	}
+	return s, nil
+}
+
+// dirtySuffixPattern matches the literal text of valid dirty formats
+var dirtySuffixPattern = regexp.MustCompile(`^\+?[0-9A-Za-z.-]*$`)
+
+// checkDirtyPlaceholder returns an error for unknown placeholders
+func checkDirtyPlaceholder(name string) error {
+	switch name {
+	case "timestamp", "hash", "sha":
+		return nil
+	}
+	return fmt.Errorf("unknown placeholder {%s}", name)
+}
+
+// needsDirtyHash reports whether the dirty format contains {hash}, which
+// requires hashing the working tree
+func (o *options) needsDirtyHash() bool {
+	needed := false
+	expandTemplate(o.dirtyFormat, func(name string) (string, error) {
+		needed = needed || name == "hash"
+		return "", nil
+	})
+	return needed
+}
+
+// applyDirtyFormat renders the dirty format and adds it to the version
+func (i *Info) applyDirtyFormat(version, format string, now time.Time) (string, error) {
+	suffix, err := expandTemplate(format, func(name string) (string, error) {
+		switch name {
+		case "timestamp":
+			return now.UTC().Format("20060102150405"), nil
+		case "hash":
+			return i.dirtyHash, nil
+		case "sha":
+			return i.GitCommitShort, nil
+		}
+		return "", checkDirtyPlaceholder(name)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if metadata, ok := strings.CutPrefix(suffix, "+"); ok {
+		if strings.Contains(version, "+") {
+			return version + "." + metadata, nil
+		}
+		return version + "+" + metadata, nil
+	}
+	return appendPrerelease(version, suffix), nil
+}
@@ -0,0 +1,62 @@
+package version
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyDirtyFormat(t *testing.T) {
+	info := Info{GitCommitShort: "abc123d", dirtyHash: "3f2a9c1"}
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		version  string
+		format   string
+		expected string
+	}{
+		{"v1.2.3", "timestamp", "v1.2.3-20240102150405"},
+		{"v1.2.3", "hash", "v1.2.3-dirty-3f2a9c1"},
+		{"v1.2.3", "-dirty", "v1.2.3-dirty"},
+		{"v1.2.3", "+dirty", "v1.2.3+dirty"},
+		{"v1.2.3+gabc123d", "-dirty", "v1.2.3-dirty+gabc123d"},
+		{"v1.2.3+gabc123d", "+dirty", "v1.2.3+gabc123d.dirty"},
+		{"v1.2.3", "-dirty.{sha}.{hash}", "v1.2.3-dirty.abc123d.3f2a9c1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			format, err := ParseDirtyFormat(tt.format)
+			if err != nil {
+				t.Fatalf("ParseDirtyFormat(%q) failed: %v", tt.format, err)
+			}
+			result, err := info.applyDirtyFormat(tt.version, format, now)
+			if err != nil {
+				t.Fatalf("applyDirtyFormat() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("applyDirtyFormat(%q, %q) = %q, want %q", tt.version, tt.format, result, tt.expected)
+			}
+		})
+	}
+
+	for _, format := range []string{"", "-{unknown}", "-{sha", "foo bar", "-{{sha}}", "-dirty{{", "+dirty+{sha}", "-dirty/{sha}"} {
+		if _, err := ParseDirtyFormat(format); err == nil {
+			t.Errorf("ParseDirtyFormat(%q) expected error", format)
+		}
+	}
+}
+
+func TestNeedsDirtyHash(t *testing.T) {
+	tests := map[string]bool{
+		DirtyFormatTimestamp: false,
+		DirtyFormatHash:      true,
+		"+dirty.{ hash }":    true,
+		"-{{hash}}":          false,
+	}
+	for format, expected := range tests {
+		o := newOptions([]Option{WithDirtyFormat(format)})
+		if got := o.needsDirtyHash(); got != expected {
+			t.Errorf("needsDirtyHash() for %q = %v, want %v", format, got, expected)
+		}
+	}
+}
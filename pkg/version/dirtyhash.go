@@ -0,0 +1,100 @@
+package version
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// dirtyHashLength is the length of the {hash} placeholder of dirty formats
+const dirtyHashLength = 7
+
+// dirtyEntry is a tracked file as it is in the working tree
+type dirtyEntry struct {
+	name string
+	mode filemode.FileMode
+	hash plumbing.Hash
+}
+
+// dirtyHash hashes the mode, blob hash and path of the entries in path order.
+// It only depends on the content of the working tree, not on stat data.
+func dirtyHash(entries []dirtyEntry) string {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	h := sha1.New()
+	for _, e := range entries {
+		fmt.Fprintf(h, "%s %s %s\n", e.mode, e.hash, e.name)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:dirtyHashLength]
+}
+
+// worktreeEntry returns the entry of a modified file in the working tree, or
+// false if it was deleted
+func worktreeEntry(fs billy.Filesystem, name string) (dirtyEntry, bool, error) {
+	fi, err := fs.Lstat(name)
+	if err != nil {
+		return dirtyEntry{}, false, nil
+	}
+	mode, err := filemode.NewFromOSFileMode(fi.Mode())
+	if err != nil {
+		return dirtyEntry{}, false, err
+	}
+	hash, err := worktreeBlobHash(fs, name, mode)
+	if err != nil {
+		return dirtyEntry{}, false, err
+	}
+	return dirtyEntry{name: name, mode: mode, hash: hash}, true, nil
+}
+
+// worktreeHash computes the dirty hash of the tracked files the filter does
+// not skip, with the content of modified files read from the worktree
+func worktreeHash(ctx context.Context, repo *git.Repository, filter dirtyFilter) (string, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+
+	entries := make([]dirtyEntry, 0, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if entry.Stage != 0 || filter.skip(entry.Name) {
+			continue
+		}
+		if !isModified(worktree.Filesystem, entry) {
+			entries = append(entries, dirtyEntry{name: entry.Name, mode: entry.Mode, hash: entry.Hash})
+			continue
+		}
+		e, ok, err := worktreeEntry(worktree.Filesystem, entry.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", entry.Name, err)
+		}
+		if ok {
+			entries = append(entries, e)
+		}
+	}
+	return dirtyHash(entries), nil
+}
+
+// worktreeState checks the working tree for uncommitted changes and, if it is
+// dirty and the dirty format contains {hash}, computes its dirty hash
+func worktreeState(ctx context.Context, repo *git.Repository, o *options) (bool, string, error) {
+	defer o.phase(PhaseStatus)()
+	dirty, err := isDirty(ctx, repo, o)
+	if err != nil || !dirty || !o.needsDirtyHash() {
+		return dirty, "", err
+	}
+	hash, err := worktreeHash(ctx, repo, o.dirtyFilter())
+	return dirty, hash, err
+}
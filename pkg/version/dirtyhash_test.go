@@ -0,0 +1,63 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirtyFormatHash(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "a.txt", "a", "Initial commit")
+	commitTestFile(t, repo, tempDir, "b.txt", "b", "Second commit")
+
+	getVersion := func(opts ...Option) string {
+		t.Helper()
+		info, err := GetVersionInfo(tempDir, "", append(opts, WithDirtyFormat(DirtyFormatHash))...)
+		if err != nil {
+			t.Fatalf("GetVersionInfo failed: %v", err)
+		}
+		return info.Version
+	}
+
+	clean := getVersion()
+	if strings.Contains(clean, "dirty") {
+		t.Errorf("Version = %q, clean tree should have no dirty suffix", clean)
+	}
+
+	writeFile(t, filepath.Join(tempDir, "a.txt"), "modified")
+	dirty := getVersion()
+	if !strings.HasPrefix(dirty, clean+"-dirty-") || len(dirty) != len(clean)+len("-dirty-")+dirtyHashLength {
+		t.Errorf("Version = %q, want %q with a dirty hash", dirty, clean+"-dirty-<hash>")
+	}
+	if again := getVersion(); again != dirty {
+		t.Errorf("Version = %q, want the same version %q for the same changes", again, dirty)
+	}
+
+	writeFile(t, filepath.Join(tempDir, "a.txt"), "modified again")
+	changed := getVersion()
+	if changed == dirty {
+		t.Errorf("Version = %q, want a different hash for different changes", changed)
+	}
+
+	// Restoring the first change restores its hash
+	writeFile(t, filepath.Join(tempDir, "a.txt"), "modified")
+	if restored := getVersion(); restored != dirty {
+		t.Errorf("Version = %q, want %q after restoring the changes", restored, dirty)
+	}
+
+	if err := os.Remove(filepath.Join(tempDir, "b.txt")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	deleted := getVersion()
+	if deleted == dirty {
+		t.Errorf("Version = %q, want a different hash after deleting a file", deleted)
+	}
+
+	if hasGit() {
+		if cli := getVersion(WithBackend(GitCLI())); cli != deleted {
+			t.Errorf("git-cli Version = %q, want %q", cli, deleted)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package version
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenRepositoryWindowsPaths(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+
+	subDir := filepath.Join(tempDir, "a", "b")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	// Both separators are accepted, as by git for Windows
+	for _, path := range []string{subDir, filepath.ToSlash(subDir), subDir + `\`} {
+		if _, err := OpenRepository(path); err != nil {
+			t.Errorf("OpenRepository(%q) failed: %v", path, err)
+		}
+	}
+
+	// Discovery stops at the drive root instead of looping
+	root := filepath.VolumeName(tempDir) + `\`
+	if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+		t.Skipf("%s is a repository", root)
+	}
+	_, err := OpenRepository(root)
+	if !errors.Is(err, ErrNoRepository) {
+		t.Fatalf("OpenRepository(%q) = %v, want ErrNoRepository", root, err)
+	}
+	if !strings.Contains(err.Error(), root) {
+		t.Errorf("error %q does not name the drive root", err)
+	}
+}
@@ -0,0 +1,99 @@
+package version
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+const (
+	// DefaultFetchRemote is the remote WithFetchTags fetches from by default
+	DefaultFetchRemote = "origin"
+
+	// defaultFetchUsername is the user name sent with GITVERSION_TOKEN if
+	// GITVERSION_USERNAME is not set; hosts accept any name with a token
+	defaultFetchUsername = "x-access-token"
+)
+
+// tagRefSpec fetches all tags, replacing moved ones
+const tagRefSpec = "+refs/tags/*:refs/tags/*"
+
+// fetchCredentials returns the user name and token for HTTP(S) remotes from
+// GITVERSION_USERNAME and GITVERSION_TOKEN, if a token is set
+func fetchCredentials(url string) (string, string, bool) {
+	token := os.Getenv("GITVERSION_TOKEN")
+	if token == "" || !(strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")) {
+		return "", "", false
+	}
+	username := os.Getenv("GITVERSION_USERNAME")
+	if username == "" {
+		username = defaultFetchUsername
+	}
+	return username, token, true
+}
+
+// fetchAuth returns the HTTP basic auth of fetchCredentials for the remote,
+// or nil to use the default auth of the transport, e.g. the ssh-agent
+func fetchAuth(remote *git.Remote) transport.AuthMethod {
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		if username, token, ok := fetchCredentials(urls[0]); ok {
+			return &http.BasicAuth{Username: username, Password: token}
+		}
+	}
+	return nil
+}
+
+// fetchTags fetches all tags from the remote. HTTP(S) remotes authenticate
+// with fetchCredentials, SSH remotes with the ssh-agent.
+func fetchTags(ctx context.Context, repo *git.Repository, remoteName string) error {
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return fmt.Errorf("failed to find remote %s: %w", remoteName, err)
+	}
+
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{tagRefSpec},
+		Tags:     git.NoTags,
+		Auth:     fetchAuth(remote),
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch tags from %s: %w", remoteName, err)
+	}
+	return nil
+}
+
+// fetchTags fetches all tags from the remote like fetchTags
+func (g gitCommand) fetchTags(remote string) error {
+	g, err := g.withFetchAuth(remote)
+	if err != nil {
+		return err
+	}
+	if err := g.run("fetch", "--quiet", "--no-tags", remote, tagRefSpec); err != nil {
+		return fmt.Errorf("failed to fetch tags from %s: %w", remote, err)
+	}
+	return nil
+}
+
+// withFetchAuth returns the command with the fetchCredentials of the remote,
+// passed to git as an HTTP header in the environment. SSH and other
+// credentials are left to git.
+func (g gitCommand) withFetchAuth(remote string) (gitCommand, error) {
+	url, err := g.output("remote", "get-url", remote)
+	if err != nil {
+		return g, fmt.Errorf("failed to find remote %s: %w", remote, err)
+	}
+	if username, token, ok := fetchCredentials(url); ok {
+		header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+token))
+		g.env = append(slices.Clip(g.env), "GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=http.extraHeader", "GIT_CONFIG_VALUE_0="+header)
+	}
+	return g, nil
+}
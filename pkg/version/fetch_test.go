@@ -0,0 +1,74 @@
+package version
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestGetVersionInfoWithFetchTags(t *testing.T) {
+	originDir, origin := initTestRepo(t)
+	head := commitTestFile(t, origin, originDir, "test.txt", "test", "Initial commit")
+	if _, err := origin.CreateTag("v1.0.0", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			cloneDir := filepath.Join(t.TempDir(), "clone")
+			if _, err := git.PlainClone(cloneDir, false, &git.CloneOptions{URL: originDir, Tags: git.NoTags}); err != nil {
+				t.Fatalf("Failed to clone: %v", err)
+			}
+
+			info, err := GetVersionInfo(cloneDir, "", WithBackend(backend))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.LatestTag != "" {
+				t.Fatalf("LatestTag = %q, clone should have no tags", info.LatestTag)
+			}
+
+			info, err = GetVersionInfo(cloneDir, "", WithBackend(backend), WithFetchTags(""))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.Version != "v1.0.0" {
+				t.Errorf("Version = %q, want %q", info.Version, "v1.0.0")
+			}
+
+			// Fetching again is a no-op
+			if _, err := GetVersionInfo(cloneDir, "", WithBackend(backend), WithFetchTags("")); err != nil {
+				t.Errorf("GetVersionInfo failed on second fetch: %v", err)
+			}
+			if _, err := GetVersionInfo(cloneDir, "", WithBackend(backend), WithFetchTags("upstream")); err == nil {
+				t.Error("GetVersionInfo should fail for an unknown remote")
+			}
+		})
+	}
+}
+
+func TestFetchCredentials(t *testing.T) {
+	t.Setenv("GITVERSION_TOKEN", "")
+	t.Setenv("GITVERSION_USERNAME", "")
+	if _, _, ok := fetchCredentials("https://example.com/repo.git"); ok {
+		t.Error("fetchCredentials() without token should report no credentials")
+	}
+
+	t.Setenv("GITVERSION_TOKEN", "secret")
+	if username, token, ok := fetchCredentials("https://example.com/repo.git"); !ok || username != defaultFetchUsername || token != "secret" {
+		t.Errorf("fetchCredentials() = %q, %q, %v", username, token, ok)
+	}
+	if _, _, ok := fetchCredentials("git@example.com:repo.git"); ok {
+		t.Error("fetchCredentials() should not apply to SSH remotes")
+	}
+
+	t.Setenv("GITVERSION_USERNAME", "ci")
+	if username, _, _ := fetchCredentials("http://example.com/repo.git"); username != "ci" {
+		t.Errorf("username = %q, want %q", username, "ci")
+	}
+}
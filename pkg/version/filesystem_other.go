@@ -0,0 +1,14 @@
+//go:build !unix
+
+package version
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// filesystemID identifies the filesystem of the path by its volume, e.g. C:
+// or \\server\share on Windows; mounted folders are not detected
+func filesystemID(path string) string {
+	return strings.ToUpper(filepath.VolumeName(path))
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package version
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// filesystemID identifies the filesystem of the path by its device number,
+// or returns an empty string if it cannot be determined
+func filesystemID(path string) string {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatUint(uint64(st.Dev), 10)
+}
@@ -0,0 +1,529 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// GitCLI returns a backend that shells out to the git executable (describe,
+// rev-list, status), for repositories go-git is slow on or does not support,
+// such as partial clones and sparse checkouts. Tag selection follows
+// git describe.
+func GitCLI() Backend {
+	return gitCLI{}
+}
+
+type gitCLI struct{}
+
+func (gitCLI) Name() string { return "git-cli" }
+
+func (gitCLI) inspect(repoPath string, o *options) (*repoState, error) {
+	g := o.gitCommand(repoPath)
+	stop := o.phase(PhaseOpen)
+	_, err := g.output("rev-parse", "--git-dir")
+	stop()
+	if err != nil && strings.Contains(err.Error(), "not a git repository") {
+		return nil, fmt.Errorf("failed to open repository: %w: %w", ErrNoRepository, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	if o.fetchRemote != "" {
+		if err := g.fetchTags(o.fetchRemote); err != nil {
+			return nil, err
+		}
+	}
+	if o.tagCheckRemote != "" {
+		diff, err := g.compareRemoteTags(o.tagCheckRemote, o)
+		if err != nil {
+			return nil, err
+		}
+		if err := diff.staleError(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Auto-detect default branch if not specified
+	s := &repoState{defaultBranch: o.defaultBranch, remoteURL: g.originURL()}
+	if s.defaultBranch == "" {
+		s.defaultBranch = g.defaultBranch(o)
+	}
+
+	// A new repository has no commits yet; HEAD points to an unborn branch
+	if o.ref == "" && g.run("rev-parse", "--verify", "--quiet", "HEAD") != nil {
+		if branch, err := g.output("symbolic-ref", "-q", "--short", "HEAD"); err == nil {
+			s.setUnborn(branch, g.abbrevLength(o.abbrev))
+			if o.dirtyCheck {
+				s.dirty, s.dirtyHash, err = g.worktreeState(o)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+				}
+			}
+			return s, nil
+		}
+	}
+
+	// Get HEAD commit and branch, or those of the configured ref
+	rev := "HEAD"
+	s.branch = "HEAD"
+	if o.ref != "" && o.ref != "HEAD" {
+		rev = o.ref
+		if g.run("show-ref", "--verify", "--quiet", "refs/heads/"+o.ref) == nil {
+			s.branch = o.ref
+		}
+	} else if branch, err := g.output("symbolic-ref", "-q", "--short", "HEAD"); err == nil {
+		s.branch = branch
+	}
+	commit, err := g.output("rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	s.commit = commit
+	s.shortCommit, err = g.abbreviate(commit, o.abbrev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get commit date, committer, author and subject, separated by NUL
+	show, err := g.output("show", "-s", "--format=%ct%x00%cn%x00%ae%x00%s", commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	parts := strings.SplitN(show, "\x00", 4)
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("failed to parse commit %q", show)
+	}
+	seconds, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commit date %q: %w", parts[0], err)
+	}
+	s.commitTime = time.Unix(seconds, 0)
+	s.committerName, s.authorEmail, s.subject = parts[1], parts[2], parts[3]
+
+	s.describe, err = g.describe(commit, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe HEAD: %w", err)
+	}
+
+	// A shallow clone may lack the latest tag; deepen the history if enabled
+	s.shallow, err = g.isShallow()
+	if err != nil {
+		return nil, err
+	}
+	for depth := deepenStart; o.deepen && s.shallow && s.describe.Tag == "" && depth <= deepenMax; depth *= 2 {
+		grew, err := g.deepen(o.deepenRemote(), depth)
+		if err != nil {
+			return nil, err
+		}
+		if !grew {
+			continue
+		}
+		s.describe, err = g.describe(commit, o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe HEAD: %w", err)
+		}
+		if s.shallow, err = g.isShallow(); err != nil {
+			return nil, err
+		}
+	}
+
+	// List the tags prerelease labels are numbered after and versions must
+	// be higher than
+	if o.listsTags() {
+		out, err := g.output("for-each-ref", "--format=%(refname:strip=2)", "refs/tags")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+		if out != "" {
+			s.tags = strings.Split(out, "\n")
+		}
+	}
+
+	// Resolve the local tag HEAD is treated as tagged with, if any
+	if tag := o.headTagName(); tag != "" {
+		if hash, err := g.output("rev-parse", "--verify", "--quiet", "refs/tags/"+tag+"^{commit}"); err == nil {
+			s.headTagCommit = hash
+		}
+	}
+
+	// Read the annotation if HEAD is at an annotated tag
+	if s.describe.Tag != "" && s.describe.TagHash == s.describe.Hash {
+		s.annotation, err = g.annotation(s.describe.Tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify the signatures of HEAD and the latest tag if requested
+	if o.signersFile != "" {
+		signers, err := loadSigners(o.signersFile)
+		if err != nil {
+			return nil, err
+		}
+		s.commitSigned, s.tagSigned, err = g.verifySignatures(signers, commit, s.describe.Tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Check for uncommitted changes, which only apply to the checked out HEAD
+	if o.dirtyCheck && o.ref == "" {
+		s.dirty, s.dirtyHash, err = g.worktreeState(o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+		}
+	}
+
+	if o.superproject {
+		s.superproject, err = g.output("rev-parse", "--show-superproject-working-tree")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find superproject: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (gitCLI) checkWorktree(repoPath string, o *options) (bool, string, error) {
+	return o.gitCommand(repoPath).worktreeState(o)
+}
+
+// gitCommand runs git in a directory, killing it once the context is done
+type gitCommand struct {
+	ctx context.Context
+	dir string
+	// env is added to the environment of git
+	env []string
+	// log traces the commands run, if set
+	log *slog.Logger
+}
+
+// gitCommand returns the command running git in dir, with the ceiling
+// directories and filesystem boundary of the options passed to its discovery
+func (o *options) gitCommand(dir string) gitCommand {
+	g := gitCommand{ctx: o.ctx, dir: dir, log: o.log()}
+	if len(o.ceilingDirs) > 0 {
+		ceilings := filepath.SplitList(os.Getenv("GIT_CEILING_DIRECTORIES"))
+		for _, dir := range o.ceilingDirs {
+			if abs, err := filepath.Abs(dir); err == nil {
+				ceilings = append(ceilings, abs)
+			}
+		}
+		g.env = append(g.env, "GIT_CEILING_DIRECTORIES="+strings.Join(ceilings, string(os.PathListSeparator)))
+	}
+	if o.oneFilesystem {
+		g.env = append(g.env, "GIT_DISCOVERY_ACROSS_FILESYSTEM=false")
+	}
+	return g
+}
+
+// run runs git with the given arguments, discarding its output
+func (g gitCommand) run(args ...string) error {
+	_, err := g.raw(args...)
+	return err
+}
+
+// output runs git with the given arguments and returns its trimmed output
+func (g gitCommand) output(args ...string) (string, error) {
+	out, err := g.raw(args...)
+	return strings.TrimSpace(out), err
+}
+
+// raw runs git with the given arguments and returns its output unmodified.
+// Errors contain the message git printed to stderr.
+func (g gitCommand) raw(args ...string) (string, error) {
+	cmd := exec.CommandContext(g.ctx, "git", append([]string{"-C", g.dir}, args...)...)
+	if g.log != nil {
+		g.log.Log(g.ctx, LevelTrace, "running git", "dir", g.dir, "args", args)
+	}
+	if len(g.env) > 0 {
+		cmd.Env = append(os.Environ(), g.env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctxErr := g.ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], ctxErr)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// abbrevLength resolves the abbreviation like repoAbbrev
+func (g gitCommand) abbrevLength(abbrev int) int {
+	// git config fails if the key is not set
+	coreAbbrev, _ := g.output("config", "--get", "core.abbrev")
+	return configAbbrev(abbrev, coreAbbrev)
+}
+
+// abbreviate shortens the commit hash like abbreviate, using core.abbrev
+// from git config if no abbreviation is set
+func (g gitCommand) abbreviate(commit string, abbrev int) (string, error) {
+	abbrev = g.abbrevLength(abbrev)
+	if abbrev != AbbrevAuto {
+		return commit[:abbrev], nil
+	}
+	return g.output("rev-parse", fmt.Sprintf("--short=%d", defaultAbbrev), commit)
+}
+
+// defaultBranch detects the default branch like detectDefaultBranch
+func (g gitCommand) defaultBranch(o *options) string {
+	if ref, err := g.output("symbolic-ref", "-q", "refs/remotes/origin/HEAD"); err == nil && ref != "" {
+		return strings.TrimPrefix(ref, "refs/remotes/origin/")
+	}
+	if o.remoteHEADTimeout > 0 {
+		branch, err := g.remoteHEAD(DefaultFetchRemote, o.remoteHEADTimeout)
+		if err == nil {
+			return branch
+		}
+		o.log().Info("default branch not queried from remote", "error", err)
+	}
+	for _, branch := range []string{"main", "master"} {
+		if g.run("show-ref", "--verify", "--quiet", "refs/heads/"+branch) == nil {
+			return branch
+		}
+	}
+	return "main"
+}
+
+// describe finds the nearest tag with git describe and counts the commits
+// since with git rev-list, limited to the component path if set
+func (g gitCommand) describe(commit string, o *options) (Description, error) {
+	d := Description{Hash: plumbing.NewHash(commit)}
+
+	args := []string{"describe", "--tags", "--abbrev=0", "--match", o.matchPrefix() + "*"}
+	if o.firstParent {
+		args = append(args, "--first-parent")
+	}
+	stop := o.phase(PhaseTags)
+	excluded, err := g.excludedTags(o)
+	stop()
+	if err != nil {
+		return d, err
+	}
+	defer o.phase(PhaseDescribe)()
+	if o.maxDescribeDepth > 0 {
+		found, err := g.tagWithin(commit, excluded, o)
+		if err != nil {
+			return d, err
+		}
+		if !found {
+			d.Truncated = true
+			return d, nil
+		}
+	}
+	for _, tag := range excluded {
+		args = append(args, "--exclude", tag)
+	}
+	// git describe fails if no tag is reachable
+	if tag, err := g.output(append(args, commit)...); err == nil {
+		tagCommit, err := g.output("rev-parse", "--verify", "refs/tags/"+tag+"^{commit}")
+		if err != nil {
+			return d, err
+		}
+		d.Tag, d.TagHash = tag, plumbing.NewHash(tagCommit)
+		if d.TagHash == d.Hash {
+			return d, nil
+		}
+	}
+
+	args = []string{"rev-list", "--count"}
+	if o.firstParent {
+		args = append(args, "--first-parent")
+	}
+	args = append(args, commit)
+	if d.Tag != "" {
+		args = append(args, "^"+d.TagHash.String())
+	}
+	if o.componentPath != "" {
+		args = append(args, "--", o.componentPath)
+	}
+	count, err := g.output(args...)
+	if err != nil {
+		return d, err
+	}
+	d.Distance, err = strconv.Atoi(count)
+	if err != nil {
+		return d, fmt.Errorf("failed to parse commit count %q: %w", count, err)
+	}
+	return d, nil
+}
+
+// worktreeState checks the working tree like worktreeState
+func (g gitCommand) worktreeState(o *options) (bool, string, error) {
+	defer o.phase(PhaseStatus)()
+	dirty, err := g.dirty(o.dirtyFilter(), o.dirtyUntracked)
+	if err != nil || !dirty || !o.needsDirtyHash() {
+		return dirty, "", err
+	}
+	hash, err := g.worktreeHash(o.dirtyFilter())
+	return dirty, hash, err
+}
+
+// worktreeHash computes the dirty hash like worktreeHash, listing the index
+// with git ls-files and the modified files with git diff
+func (g gitCommand) worktreeHash(filter dirtyFilter) (string, error) {
+	top, err := g.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	g.dir = top
+	out, err := g.raw("diff", "--name-only", "-z")
+	if err != nil {
+		return "", err
+	}
+	modified := make(map[string]bool)
+	for _, name := range strings.Split(out, "\x00") {
+		modified[name] = true
+	}
+	out, err = g.raw("ls-files", "--stage", "-z")
+	if err != nil {
+		return "", err
+	}
+
+	fs := osfs.New(top)
+	var entries []dirtyEntry
+	for _, line := range strings.Split(out, "\x00") {
+		// <mode> <hash> <stage>\t<path>
+		meta, name, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[2] != "0" || filter.skip(name) {
+			continue
+		}
+		mode, err := filemode.New(fields[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to parse mode of %s: %w", name, err)
+		}
+		if !modified[name] || mode == filemode.Submodule {
+			entries = append(entries, dirtyEntry{name: name, mode: mode, hash: plumbing.NewHash(fields[1])})
+			continue
+		}
+		e, ok, err := worktreeEntry(fs, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		if ok {
+			entries = append(entries, e)
+		}
+	}
+	return dirtyHash(entries), nil
+}
+
+// tagWithin reports whether a tag matching the prefix and not excluded points
+// to one of the latest commits of WithMaxDescribeDepth in git rev-list order,
+// or whether the history has no more commits than that
+func (g gitCommand) tagWithin(commit string, excluded []string, o *options) (bool, error) {
+	args := []string{"rev-list", "--max-count=" + strconv.Itoa(o.maxDescribeDepth+1)}
+	if o.firstParent {
+		args = append(args, "--first-parent")
+	}
+	out, err := g.output(append(args, commit)...)
+	if err != nil {
+		return false, err
+	}
+	commits := strings.Fields(out)
+	if len(commits) <= o.maxDescribeDepth {
+		return true, nil
+	}
+	within := make(map[string]bool, o.maxDescribeDepth)
+	for _, c := range commits[:o.maxDescribeDepth] {
+		within[c] = true
+	}
+
+	// Annotated tags are peeled to their commit by %(*objectname)
+	out, err = g.output("for-each-ref", "--format=%(refname) %(objectname) %(*objectname)", "refs/tags/")
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], "refs/tags/")
+		if !strings.HasPrefix(name, o.matchPrefix()) || slices.Contains(excluded, name) {
+			continue
+		}
+		if within[fields[len(fields)-1]] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// excludedTags lists the tags excluded by WithTagExcludes by name, since
+// git describe --exclude does not support the same patterns
+func (g gitCommand) excludedTags(o *options) ([]string, error) {
+	if len(o.tagExcludes) == 0 {
+		return nil, nil
+	}
+	excludes, err := newTagExcludes(o.tagExcludes)
+	if err != nil {
+		return nil, err
+	}
+	prefix := o.matchPrefix()
+	out, err := g.output("tag", "--list", prefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	var excluded []string
+	for _, tag := range strings.Fields(out) {
+		if excludes.match(tag, prefix) {
+			excluded = append(excluded, tag)
+		}
+	}
+	return excluded, nil
+}
+
+// dirty reports whether tracked files have staged or unstaged changes outside
+// the excluded paths, and if requested untracked files that are not ignored
+func (g gitCommand) dirty(filter dirtyFilter, untracked bool) (bool, error) {
+	// Run from the top level so that paths are relative to the repository root
+	top, err := g.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return false, err
+	}
+	untrackedFiles := "--untracked-files=no"
+	if untracked {
+		untrackedFiles = "--untracked-files=all"
+	}
+	out, err := gitCommand{ctx: g.ctx, dir: top, log: g.log}.raw("status", "--porcelain", "-z", untrackedFiles)
+	if err != nil {
+		return false, err
+	}
+
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		// Renames and copies are followed by the original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+		if !filter.skip(entry[3:]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
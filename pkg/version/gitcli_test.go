@@ -0,0 +1,104 @@
+package version
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestGitCLIBackendMatchesGoGit(t *testing.T) {
+	if !hasGit() {
+		t.Skip("git executable not found")
+	}
+
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "svc/a.txt", "a1", "Initial commit")
+	opts := &git.CreateTagOptions{Tagger: testSignature(), Message: "Release"}
+	if _, err := repo.CreateTag("v1.0.0", first, opts); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if _, err := repo.CreateTag("svc/v0.1.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "svc/a.txt", "a2", "Change component")
+	commitTestFile(t, repo, tempDir, "other.txt", "o1", "Change other")
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "component", opts: []Option{WithComponentPath("svc")}},
+		{name: "tag prefix without tags", opts: []Option{WithTagPrefix("release/")}},
+		{name: "ref", opts: []Option{WithRef("v1.0.0")}},
+		{name: "first parent", opts: []Option{WithFirstParent(true)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := GetVersionInfo(tempDir, "", tt.opts...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo with go-git failed: %v", err)
+			}
+			info, err := GetVersionInfo(tempDir, "", append(tt.opts, WithBackend(GitCLI()))...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo with git-cli failed: %v", err)
+			}
+
+			if !info.commitTime.Equal(expected.commitTime) {
+				t.Errorf("commitTime = %v, want %v", info.commitTime, expected.commitTime)
+			}
+			info.BuildTime, info.commitTime = expected.BuildTime, expected.commitTime
+			if !reflect.DeepEqual(info, expected) {
+				t.Errorf("git-cli = %#v, want %#v", *info, *expected)
+			}
+		})
+	}
+
+	t.Run("dirty", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(tempDir, "svc", "a.txt"), []byte("modified"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		info, err := GetVersionInfo(tempDir, "", WithBackend(GitCLI()))
+		if err != nil {
+			t.Fatalf("GetVersionInfo failed: %v", err)
+		}
+		if !info.IsDirty {
+			t.Error("IsDirty should be true for modified file")
+		}
+
+		info, err = GetVersionInfo(tempDir, "", WithBackend(GitCLI()), WithDirtyExcludes("svc/"))
+		if err != nil {
+			t.Fatalf("GetVersionInfo failed: %v", err)
+		}
+		if info.IsDirty {
+			t.Error("IsDirty should be false for excluded file")
+		}
+	})
+}
+
+func TestBackendByName(t *testing.T) {
+	for _, name := range BackendNames() {
+		b, err := BackendByName(name)
+		if err != nil {
+			t.Fatalf("BackendByName(%q) failed: %v", name, err)
+		}
+		if b.Name() != name {
+			t.Errorf("Name() = %q, want %q", b.Name(), name)
+		}
+	}
+	if _, err := BackendByName("svn"); err == nil {
+		t.Error("BackendByName(\"svn\") expected error")
+	}
+}
+
+// hasGit reports whether the git executable is available
+func hasGit() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
@@ -0,0 +1,202 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// GitConfig is the git configuration of a repository, merged from the
+// system, global and repository config files like git config
+type GitConfig struct {
+	// files are the config files in the order git reads them, later values
+	// overriding earlier ones
+	files []*format.Config
+}
+
+// ReadGitConfig reads the git configuration of the repository: /etc/gitconfig
+// unless GIT_CONFIG_NOSYSTEM is set, the global config file GIT_CONFIG_GLOBAL
+// or else $XDG_CONFIG_HOME/git/config and ~/.gitconfig, and the config of the
+// repository. Missing and unreadable files are skipped; include directives
+// are not followed.
+func ReadGitConfig(repo *git.Repository) *GitConfig {
+	c := &GitConfig{}
+	var paths []string
+	if v, _ := strconv.ParseBool(os.Getenv("GIT_CONFIG_NOSYSTEM")); !v {
+		paths = append(paths, "/etc/gitconfig")
+	}
+	if global, ok := os.LookupEnv("GIT_CONFIG_GLOBAL"); ok {
+		paths = append(paths, global)
+	} else {
+		xdg := os.Getenv("XDG_CONFIG_HOME")
+		home, _ := os.UserHomeDir()
+		if xdg == "" && home != "" {
+			xdg = filepath.Join(home, ".config")
+		}
+		if xdg != "" {
+			paths = append(paths, filepath.Join(xdg, "git", "config"))
+		}
+		if home != "" {
+			paths = append(paths, filepath.Join(home, ".gitconfig"))
+		}
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		cfg := format.New()
+		err = format.NewDecoder(f).Decode(cfg)
+		f.Close()
+		if err == nil {
+			c.files = append(c.files, cfg)
+		}
+	}
+	if repo != nil {
+		if cfg, err := repo.Storer.Config(); err == nil && cfg.Raw != nil {
+			c.files = append(c.files, cfg.Raw)
+		}
+	}
+	return c
+}
+
+// ReadGitConfigAt is like ReadGitConfig for the repository found from
+// repoPath like GetVersionInfo
+func ReadGitConfigAt(repoPath string, opts ...Option) (*GitConfig, error) {
+	repo, err := newOptions(opts).openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return ReadGitConfig(repo), nil
+}
+
+// Get returns the last value of the key, e.g. core.abbrev or
+// remote.origin.url, and whether it is set. A boolean key set without a
+// value, e.g. "[gitversion] fetch-tags", has an empty value.
+func (c *GitConfig) Get(key string) (string, bool) {
+	values := c.GetAll(key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// GetAll returns all values of a multi-valued key in the order they are set
+func (c *GitConfig) GetAll(key string) []string {
+	section, rest, ok := strings.Cut(key, ".")
+	if !ok {
+		return nil
+	}
+	subsection, name := "", rest
+	if i := strings.LastIndex(rest, "."); i >= 0 {
+		subsection, name = rest[:i], rest[i+1:]
+	}
+	var values []string
+	for _, cfg := range c.files {
+		if !cfg.HasSection(section) {
+			continue
+		}
+		s := cfg.Section(section)
+		switch {
+		case subsection == "":
+			values = append(values, s.OptionAll(name)...)
+		case s.HasSubsection(subsection):
+			values = append(values, s.Subsection(subsection).OptionAll(name)...)
+		}
+	}
+	return values
+}
+
+// Bool returns the value of the key as a git boolean: true, yes, on, 1 or no
+// value are true, false, no, off and 0 false. It reports whether the key is
+// set to a valid boolean.
+func (c *GitConfig) Bool(key string) (bool, bool) {
+	cfg, ok := c.Get(key)
+	if !ok {
+		return false, false
+	}
+	return ParseGitBool(cfg, true)
+}
+
+// ParseGitBool parses a git boolean like GitConfig.Bool, with empty the value
+// of a key set without one
+func ParseGitBool(s string, empty bool) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "":
+		return empty, true
+	case "true", "yes", "on", "1":
+		return true, true
+	case "false", "no", "off", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// sortTags sorts the tag names by the git tag.sort key: refname or
+// version:refname (v:refname), descending with a leading "-". Other keys sort
+// by refname.
+func sortTags(names []string, key string) {
+	descending := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+	less := func(a, b string) bool { return a < b }
+	if key == "version:refname" || key == "v:refname" {
+		less = func(a, b string) bool {
+			if c := compareVersionNames(a, b); c != 0 {
+				return c < 0
+			}
+			return a < b
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		if descending {
+			return less(names[j], names[i])
+		}
+		return less(names[i], names[j])
+	})
+}
+
+// compareVersionNames compares names like git's version sort: runs of digits
+// compare numerically, everything else byte-wise
+func compareVersionNames(a, b string) int {
+	for a != "" && b != "" {
+		ra, restA := nextVersionRun(a)
+		rb, restB := nextVersionRun(b)
+		if isDigits(ra) && isDigits(rb) {
+			na, nb := strings.TrimLeft(ra, "0"), strings.TrimLeft(rb, "0")
+			if len(na) != len(nb) {
+				return len(na) - len(nb)
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+		} else if c := strings.Compare(ra, rb); c != 0 {
+			return c
+		}
+		a, b = restA, restB
+	}
+	return len(a) - len(b)
+}
+
+// nextVersionRun splits off the leading run of digits or of other characters
+func nextVersionRun(s string) (string, string) {
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	digit := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// isDigits reports whether the non-empty string consists of ASCII digits
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,103 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setGlobalGitConfig makes the content the global git config of the test and
+// skips the system config
+func setGlobalGitConfig(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write git config: %v", err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", path)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+}
+
+func TestReadGitConfig(t *testing.T) {
+	setGlobalGitConfig(t, "[core]\n\tabbrev = 9\n[gitversion]\n\ttag-prefix = v\n\tdirty-exclude = a\n\tfetch-tags\n[remote \"origin\"]\n\turl = https://example.com/repo.git\n")
+	tempDir, repo := initTestRepo(t)
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg.Raw.Section("gitversion").SetOption("tag-prefix", "release/")
+	cfg.Raw.Section("gitversion").AddOption("dirty-exclude", "b")
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	c, err := ReadGitConfigAt(tempDir)
+	if err != nil {
+		t.Fatalf("ReadGitConfigAt failed: %v", err)
+	}
+	if v, ok := c.Get("gitversion.tag-prefix"); v != "release/" || !ok {
+		t.Errorf("Get(gitversion.tag-prefix) = %q, %v, want the repository value release/", v, ok)
+	}
+	if v, _ := c.Get("core.abbrev"); v != "9" {
+		t.Errorf("Get(core.abbrev) = %q, want the global value 9", v)
+	}
+	if v, _ := c.Get("remote.origin.url"); v != "https://example.com/repo.git" {
+		t.Errorf("Get(remote.origin.url) = %q, want https://example.com/repo.git", v)
+	}
+	if v := c.GetAll("gitversion.dirty-exclude"); !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("GetAll(gitversion.dirty-exclude) = %v, want [a b]", v)
+	}
+	if v, ok := c.Bool("gitversion.fetch-tags"); !v || !ok {
+		t.Errorf("Bool(gitversion.fetch-tags) = %v, %v, want true for a key without value", v, ok)
+	}
+	if _, ok := c.Get("gitversion.mode"); ok {
+		t.Error("Get(gitversion.mode) should report an unset key")
+	}
+
+	// core.abbrev of the global config applies
+	head := commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+	info, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.GitCommitShort != head.String()[:9] {
+		t.Errorf("GitCommitShort = %q, want %q", info.GitCommitShort, head.String()[:9])
+	}
+}
+
+func TestTagsSort(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+	for _, name := range []string{"v1.10.0", "v1.2.0", "v1.9.1"} {
+		if _, err := repo.CreateTag(name, head, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+
+	tests := []struct {
+		sort     string
+		expected []string
+	}{
+		{"", []string{"v1.10.0", "v1.2.0", "v1.9.1"}},
+		{"version:refname", []string{"v1.2.0", "v1.9.1", "v1.10.0"}},
+		{"-v:refname", []string{"v1.10.0", "v1.9.1", "v1.2.0"}},
+		{"-refname", []string{"v1.9.1", "v1.2.0", "v1.10.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			content := ""
+			if tt.sort != "" {
+				content = "[tag]\n\tsort = " + tt.sort + "\n"
+			}
+			setGlobalGitConfig(t, content)
+			tags, err := Tags(tempDir)
+			if err != nil {
+				t.Fatalf("Tags failed: %v", err)
+			}
+			if !reflect.DeepEqual(tags, tt.expected) {
+				t.Errorf("Tags() = %v, want %v", tags, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package version
+
+import (
+	"fmt"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// goPseudoHashLength is the length of the commit hash in Go pseudo-versions
+const goPseudoHashLength = 12
+
+// GoPseudoVersion returns the Go module version of the commit: the latest tag
+// if it points to the commit, else a pseudo-version derived from the latest
+// tag, the commit time and the 12 character commit hash:
+//   - v0.0.0-20240102150405-abcdef123456 without tags
+//   - v1.2.4-0.20240102150405-abcdef123456 after v1.2.3
+//   - v1.2.3-rc.1.0.20240102150405-abcdef123456 after v1.2.3-rc.1
+//
+// The component namespace and tag prefix are removed and build metadata is
+// dropped. A dirty working tree appends +dirty, like the go command does.
+func (i *Info) GoPseudoVersion() (string, error) {
+	if !i.HasCommits {
+		return "", fmt.Errorf("cannot compute a Go pseudo-version: %w", ErrNoCommits)
+	}
+	current, _, err := i.latestSemVer()
+	if err != nil {
+		return "", err
+	}
+	current.Build = ""
+
+	var v string
+	switch {
+	case i.LatestTag != "" && i.CommitsSinceTag == 0:
+		v = "v" + current.String()
+	case i.LatestTag == "":
+		v = fmt.Sprintf("v0.0.0-%s", i.goPseudoSuffix())
+	case current.Prerelease != "":
+		v = fmt.Sprintf("v%s.0.%s", current, i.goPseudoSuffix())
+	default:
+		v = fmt.Sprintf("v%s-0.%s", current.Bump(semver.Patch), i.goPseudoSuffix())
+	}
+	if i.IsDirty {
+		v += "+dirty"
+	}
+	return v, nil
+}
+
+// goPseudoSuffix returns the commit time and hash part of a pseudo-version
+func (i *Info) goPseudoSuffix() string {
+	return i.commitTime.UTC().Format("20060102150405") + "-" + i.GitCommit[:goPseudoHashLength]
+}
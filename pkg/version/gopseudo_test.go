@@ -0,0 +1,59 @@
+package version
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGoPseudoVersion(t *testing.T) {
+	base := Info{
+		GitCommit:  "abcdef1234567890abcdef1234567890abcdef12",
+		HasCommits: true,
+		commitTime: time.Date(2024, 1, 2, 16, 4, 5, 0, time.FixedZone("CET", 3600)),
+	}
+
+	tests := []struct {
+		name            string
+		latestTag       string
+		commitsSinceTag int
+		componentPrefix string
+		tagPrefix       string
+		dirty           bool
+		expected        string
+	}{
+		{name: "no tags", expected: "v0.0.0-20240102150405-abcdef123456"},
+		{name: "after release", latestTag: "v1.2.3", commitsSinceTag: 2, expected: "v1.2.4-0.20240102150405-abcdef123456"},
+		{name: "after prerelease", latestTag: "v1.2.3-rc.1", commitsSinceTag: 2, expected: "v1.2.3-rc.1.0.20240102150405-abcdef123456"},
+		{name: "build metadata", latestTag: "v1.2.3+build.5", commitsSinceTag: 1, expected: "v1.2.4-0.20240102150405-abcdef123456"},
+		{name: "at tag", latestTag: "v1.2.3", expected: "v1.2.3"},
+		{name: "tag without v", latestTag: "1.2.3", commitsSinceTag: 1, expected: "v1.2.4-0.20240102150405-abcdef123456"},
+		{name: "component", latestTag: "svc/v1.2.3", commitsSinceTag: 1, componentPrefix: "svc/", expected: "v1.2.4-0.20240102150405-abcdef123456"},
+		{name: "tag prefix", latestTag: "release/1.2.3", tagPrefix: "release/", expected: "v1.2.3"},
+		{name: "dirty", latestTag: "v1.2.3", commitsSinceTag: 1, dirty: true, expected: "v1.2.4-0.20240102150405-abcdef123456+dirty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := base
+			info.LatestTag = tt.latestTag
+			info.CommitsSinceTag = tt.commitsSinceTag
+			info.componentPrefix = tt.componentPrefix
+			info.tagPrefix = tt.tagPrefix
+			info.IsDirty = tt.dirty
+
+			result, err := info.GoPseudoVersion()
+			if err != nil {
+				t.Fatalf("GoPseudoVersion() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("GoPseudoVersion() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	for _, info := range []Info{{LatestTag: "v1.2.3"}, {LatestTag: "latest", HasCommits: true}} {
+		if _, err := info.GoPseudoVersion(); err == nil {
+			t.Errorf("GoPseudoVersion() for %+v expected error", info)
+		}
+	}
+}
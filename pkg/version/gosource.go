@@ -0,0 +1,35 @@
+package version
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+)
+
+// GoSource returns a formatted Go source file for the named package that
+// declares the Version, Commit, Branch and BuildTime constants, as an
+// alternative to LDFlags for projects that prefer generated code
+func (i *Info) GoSource(pkgName string) ([]byte, error) {
+	if !token.IsIdentifier(pkgName) || pkgName == "_" {
+		return nil, fmt.Errorf("invalid package name %q", pkgName)
+	}
+	consts := []struct {
+		name  string
+		value string
+	}{
+		{"Version", i.Version},
+		{"Commit", i.GitCommit},
+		{"Branch", i.GitBranch},
+		{"BuildTime", i.BuildTime},
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gitversion generate; DO NOT EDIT.\n\npackage %s\n\nconst (\n", pkgName)
+	for _, c := range consts {
+		fmt.Fprintf(&b, "%s = %s\n", c.name, strconv.Quote(c.value))
+	}
+	b.WriteString(")\n")
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,37 @@
+package version
+
+import "testing"
+
+func TestInfoGoSource(t *testing.T) {
+	info := &Info{
+		Version:   "v1.0.0-5-gabc123d",
+		GitCommit: "abc123def456",
+		GitBranch: `feature/"quoted"`,
+		BuildTime: "2025-01-01T00:00:00Z",
+	}
+
+	expected := `// Code generated by gitversion generate; DO NOT EDIT.
+
+package buildinfo
+
+const (
+	Version   = "v1.0.0-5-gabc123d"
+	Commit    = "abc123def456"
+	Branch    = "feature/\"quoted\""
+	BuildTime = "2025-01-01T00:00:00Z"
+)
+`
+	src, err := info.GoSource("buildinfo")
+	if err != nil {
+		t.Fatalf("GoSource failed: %v", err)
+	}
+	if string(src) != expected {
+		t.Errorf("GoSource() = %q, want %q", src, expected)
+	}
+
+	for _, name := range []string{"", "_", "build-info", "func"} {
+		if _, err := info.GoSource(name); err == nil {
+			t.Errorf("GoSource(%q) should fail", name)
+		}
+	}
+}
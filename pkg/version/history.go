@@ -0,0 +1,126 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fxsml/gitversion/pkg/revwalk"
+)
+
+// Release is a release tag of History
+type Release struct {
+	// Tag is the tag name, e.g. v1.2.0
+	Tag string `json:"tag" yaml:"tag"`
+	// Commit is the commit the tag points to
+	Commit string `json:"commit" yaml:"commit"`
+	// Date is the commit date of Commit in RFC3339 format
+	Date string `json:"date" yaml:"date"`
+	// Commits is the number of commits reachable from the tag but not from
+	// the previous release, or from the root for the first one
+	Commits int `json:"commits" yaml:"commits"`
+	// Authors are the authors of these commits, most commits first
+	Authors []Author `json:"authors" yaml:"authors"`
+}
+
+// Author is an author of the commits of a release
+type Author struct {
+	Name    string `json:"name" yaml:"name"`
+	Email   string `json:"email" yaml:"email"`
+	Commits int    `json:"commits" yaml:"commits"`
+}
+
+// History returns the SemVer tags matching the tag prefix and excludes in
+// ascending SemVer order, each with the commits since the previous one and
+// their authors. If a commit has several tags, the one preferred by Describe
+// is listed. Commits outside the component path are not counted.
+func History(repoPath string, opts ...Option) ([]Release, error) {
+	return HistoryContext(context.Background(), repoPath, opts...)
+}
+
+// HistoryContext is like History but aborts walking the history once the
+// context is done
+func HistoryContext(ctx context.Context, repoPath string, opts ...Option) ([]Release, error) {
+	o := newOptions(opts)
+	o.ctx = ctx
+
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	excludes, err := newTagExcludes(o.tagExcludes)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := commitTags(repo, o.matchPrefix(), excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	type tagged struct {
+		tag  tagCandidate
+		hash plumbing.Hash
+	}
+	var releases []tagged
+	for hash, tag := range tags {
+		if tag.isSemVer {
+			releases = append(releases, tagged{tag, hash})
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if c := releases[i].tag.version.Compare(releases[j].tag.version); c != 0 {
+			return c < 0
+		}
+		return releases[i].tag.name < releases[j].tag.name
+	})
+
+	nodes, release := commitNodes(repo)
+	defer release()
+	filter := o.componentFilter()
+	history := make([]Release, 0, len(releases))
+	var previous plumbing.Hash
+	for _, t := range releases {
+		commit, err := repo.CommitObject(t.hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit of %s: %w", t.tag.name, err)
+		}
+		r := Release{
+			Tag:    t.tag.name,
+			Commit: t.hash.String(),
+			Date:   commit.Committer.When.Format(time.RFC3339),
+		}
+
+		authors := make(map[string]*Author)
+		var order []*Author
+		r.Commits, err = revwalk.Count(ctx, nodes, t.hash, previous, false, func(c *object.Commit) bool {
+			if filter != nil && !filter(c) {
+				return false
+			}
+			a, ok := authors[c.Author.Email]
+			if !ok {
+				a = &Author{Name: c.Author.Name, Email: c.Author.Email}
+				authors[c.Author.Email] = a
+				order = append(order, a)
+			}
+			a.Commits++
+			return true
+		})
+		// Missing parents end the walk like the root commit, e.g. in shallow clones
+		if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, fmt.Errorf("failed to count commits of %s: %w", t.tag.name, err)
+		}
+		sort.SliceStable(order, func(i, j int) bool { return order[i].Commits > order[j].Commits })
+		for _, a := range order {
+			r.Authors = append(r.Authors, *a)
+		}
+
+		history = append(history, r)
+		previous = t.hash
+	}
+	return history, nil
+}
@@ -0,0 +1,98 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestHistory(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "a.txt", "a", "First")
+	first := commitTestFile(t, repo, tempDir, "b.txt", "b", "Second")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	// A commit by another author
+	if err := os.Mkdir(filepath.Join(tempDir, "lib"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "lib", "c.txt"), []byte("c"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add("lib/c.txt"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	other := &object.Signature{Name: "Other User", Email: "other@example.com", When: time.Now()}
+	if _, err := w.Commit("Third", &git.CommitOptions{Author: other}); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	second := commitTestFile(t, repo, tempDir, "d.txt", "d", "Fourth")
+	if _, err := repo.CreateTag("v1.1.0", second, &git.CreateTagOptions{Tagger: testSignature(), Message: "v1.1.0"}); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	rc := commitTestFile(t, repo, tempDir, "e.txt", "e", "Fifth")
+	for _, name := range []string{"v2.0.0-rc.1", "latest"} {
+		if _, err := repo.CreateTag(name, rc, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+
+	history, err := History(tempDir)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("History() = %+v, want 3 releases", history)
+	}
+	for i, want := range []struct {
+		tag     string
+		commits int
+		authors []Author
+	}{
+		{"v1.0.0", 2, []Author{{"Test User", "test@example.com", 2}}},
+		{"v1.1.0", 2, []Author{{"Test User", "test@example.com", 1}, {"Other User", "other@example.com", 1}}},
+		{"v2.0.0-rc.1", 1, []Author{{"Test User", "test@example.com", 1}}},
+	} {
+		r := history[i]
+		if r.Tag != want.tag || r.Commits != want.commits || r.Date == "" {
+			t.Errorf("History()[%d] = %+v, want %s with %d commits", i, r, want.tag, want.commits)
+		}
+		if len(r.Authors) != len(want.authors) {
+			t.Errorf("History()[%d].Authors = %+v, want %+v", i, r.Authors, want.authors)
+			continue
+		}
+		for j := range want.authors {
+			if r.Authors[j] != want.authors[j] {
+				t.Errorf("History()[%d].Authors = %+v, want %+v", i, r.Authors, want.authors)
+			}
+		}
+	}
+	if history[1].Commit != second.String() {
+		t.Errorf("Commit of annotated tag = %s, want %s", history[1].Commit, second)
+	}
+
+	// Component tags count only the commits touching the component
+	for name, hash := range map[string]plumbing.Hash{"lib/v0.1.0": first, "lib/v0.2.0": second} {
+		if _, err := repo.CreateTag(name, hash, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+	history, err = History(tempDir, WithComponentPath("lib"))
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 2 || history[0].Commits != 0 || history[1].Commits != 1 || history[1].Authors[0].Name != "Other User" {
+		t.Errorf("History() with component path = %+v", history)
+	}
+}
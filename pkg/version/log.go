@@ -0,0 +1,62 @@
+package version
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LevelTrace is the level of the most detailed records of WithLogger: each
+// directory searched for the repository and each git command run
+const LevelTrace = slog.LevelDebug - 4
+
+// discardLogger drops all records; it is the logger without WithLogger
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler is a slog.Handler that is never enabled
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// log returns the logger of WithLogger, or one discarding all records
+func (o *options) log() *slog.Logger {
+	if o.logger == nil {
+		return discardLogger
+	}
+	return o.logger
+}
+
+// logState logs the repository state the version is computed from
+func (o *options) logState(s *repoState) {
+	log := o.log()
+	source := "detected"
+	if o.defaultBranch != "" {
+		source = "given"
+	}
+	log.Info("default branch", "branch", s.defaultBranch, "source", source)
+	if s.unborn {
+		log.Info("no commits yet", "branch", s.branch)
+	} else {
+		log.Info("HEAD", "commit", s.commit, "branch", s.branch, "ref", o.ref)
+	}
+
+	switch {
+	case s.describe.Truncated:
+		log.Info("no tag within the maximum describe depth", "maxDepth", o.maxDescribeDepth)
+	case s.describe.Tag == "":
+		log.Info("no tag reachable", "prefix", o.matchPrefix(), "excludes", o.tagExcludes, "firstParent", o.firstParent, "shallow", s.shallow)
+	default:
+		log.Info("latest tag", "tag", s.describe.Tag, "commit", s.describe.TagHash.String(), "distance", s.describe.Distance)
+	}
+
+	switch {
+	case !o.dirtyCheck:
+		log.Info("dirty check skipped")
+	case o.ref != "":
+		log.Info("dirty check skipped for ref", "ref", o.ref)
+	default:
+		log.Info("dirty check", "dirty", s.dirty, "untracked", o.dirtyUntracked, "excludes", o.dirtyExcludes, "paths", o.dirtyFilter().paths)
+	}
+}
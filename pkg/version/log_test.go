@@ -0,0 +1,39 @@
+package version
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestGetVersionInfoWithLogger(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := GetVersionInfo(tempDir, "master", WithLogger(logger)); err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`msg="found repository"`,
+		`msg="default branch" branch=master source=given`,
+		`msg="tagged commits considered" count=1`,
+		`msg="latest tag" tag=v1.0.0`,
+		`distance=1`,
+		`msg="dirty check" dirty=false`,
+		`msg="versioned by strategy" strategy=githubflow`,
+		`msg="computed version" version=v1.0.0-1-g`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Mainline returns the mainline strategy for repositories that are not
+// tagged: the version is major.minor.<height> with height the number of
+// commits, e.g. v0.1.42, so that it increases with every commit of the
+// default branch. Once a tag is reachable, the patch version continues from
+// it, e.g. v1.0.3 three commits after v1.0.0. Tagged commits use the tag,
+// other branches get a prerelease named after the branch, e.g.
+// v0.1.42-feature-x. Unborn branches and commits after a latest tag that is
+// no SemVer, e.g. build-42, get <branch-slug>-g<hash> like GitHubFlow.
+func Mainline(major, minor uint64) Strategy {
+	return mainline{major: major, minor: minor}
+}
+
+type mainline struct {
+	major, minor uint64
+}
+
+func (mainline) Name() string { return "mainline" }
+
+func (s mainline) Version(info *Info) (string, error) {
+	switch {
+	case !info.HasCommits:
+		return info.slugVersion(), nil
+	case info.LatestTag != "" && info.CommitsSinceTag == 0:
+		return info.describeVersion(), nil
+	}
+
+	current, vPrefix, err := info.latestSemVer()
+	if err != nil {
+		return info.slugVersion(), nil
+	}
+	current.Prerelease, current.Build = "", ""
+	if info.LatestTag == "" {
+		current.Major, current.Minor = s.major, s.minor
+	}
+	current.Patch += uint64(info.CommitsSinceTag)
+	if !info.IsDefaultBranch() {
+		current.Prerelease = info.slugLabel()
+	}
+	return info.formatSemVer(current, vPrefix), nil
+}
+
+// ParseMainlineBase parses the major and minor version of Mainline in the
+// form MAJOR.MINOR, e.g. 0.1
+func ParseMainlineBase(s string) (major, minor uint64, err error) {
+	majorStr, minorStr, ok := strings.Cut(s, ".")
+	if ok {
+		if major, err = strconv.ParseUint(majorStr, 10, 64); err == nil {
+			minor, err = strconv.ParseUint(minorStr, 10, 64)
+		}
+	}
+	if !ok || err != nil {
+		return 0, 0, fmt.Errorf("invalid mainline base %q: must be MAJOR.MINOR, e.g. 0.1", s)
+	}
+	return major, minor, nil
+}
@@ -0,0 +1,91 @@
+package version
+
+import "testing"
+
+func TestMainlineStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     Info
+		expected string
+	}{
+		{
+			name:     "no tags",
+			info:     Info{GitBranch: "main", CommitsSinceTag: 42},
+			expected: "v0.1.42",
+		},
+		{
+			name:     "feature branch",
+			info:     Info{GitBranch: "feature/x", CommitsSinceTag: 43},
+			expected: "v0.1.43-feature-x",
+		},
+		{
+			name:     "at tag",
+			info:     Info{GitBranch: "main", GitDescribe: "v1.0.0", LatestTag: "v1.0.0"},
+			expected: "v1.0.0",
+		},
+		{
+			name:     "after tag",
+			info:     Info{GitBranch: "main", LatestTag: "v1.0.0-rc.1", CommitsSinceTag: 3},
+			expected: "v1.0.3",
+		},
+		{
+			name:     "tag prefix stripped",
+			info:     Info{GitBranch: "main", CommitsSinceTag: 7, tagPrefix: "release/", stripTagPrefix: true},
+			expected: "0.1.7",
+		},
+		{
+			name:     "after non-SemVer tag",
+			info:     Info{GitBranch: "main", GitCommitShort: "abc123d", LatestTag: "build-42", CommitsSinceTag: 2},
+			expected: "main-gabc123d",
+		},
+		{
+			name:     "empty slug",
+			info:     Info{GitBranch: "äöü", CommitsSinceTag: 3},
+			expected: "v0.1.3-branch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			info.DefaultBranch = "main"
+			info.GitBranchSlug = createBranchSlug(info.GitBranch)
+			info.HasCommits = true
+
+			result, err := Mainline(0, 1).Version(&info)
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Version() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMainlineStrategyUnborn(t *testing.T) {
+	tempDir, _ := initTestRepo(t)
+
+	info, err := GetVersionInfo(tempDir, "", WithStrategy(Mainline(0, 1)))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.HasCommits {
+		t.Fatal("HasCommits = true in a new repository")
+	}
+	if expected := info.GitBranchSlug + "-g" + info.GitCommitShort; info.Version != expected {
+		t.Errorf("Version = %q, want %q", info.Version, expected)
+	}
+}
+
+func TestParseMainlineBase(t *testing.T) {
+	major, minor, err := ParseMainlineBase("2.5")
+	if err != nil || major != 2 || minor != 5 {
+		t.Errorf("ParseMainlineBase(2.5) = %d, %d, %v", major, minor, err)
+	}
+	for _, s := range []string{"", "1", "1.2.3", "v1.2", "1.x"} {
+		if _, _, err := ParseMainlineBase(s); err == nil {
+			t.Errorf("ParseMainlineBase(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package version
+
+import "encoding/json"
+
+// infoFields is the serialized form of Info; its field order is the order of
+// the keys in JSON and YAML output
+type infoFields struct {
+	Version              string      `json:"version" yaml:"version"`
+	GitCommit            string      `json:"gitCommit" yaml:"gitCommit"`
+	GitCommitShort       string      `json:"gitCommitShort" yaml:"gitCommitShort"`
+	GitBranch            string      `json:"gitBranch" yaml:"gitBranch"`
+	GitBranchSlug        string      `json:"gitBranchSlug" yaml:"gitBranchSlug"`
+	GitDescribe          string      `json:"gitDescribe" yaml:"gitDescribe"`
+	LatestTag            string      `json:"latestTag,omitempty" yaml:"latestTag,omitempty"`
+	BuildTime            string      `json:"buildTime" yaml:"buildTime"`
+	IsDirty              bool        `json:"isDirty" yaml:"isDirty"`
+	DefaultBranch        string      `json:"defaultBranch" yaml:"defaultBranch"`
+	CommitsSinceTag      int         `json:"commitsSinceTag" yaml:"commitsSinceTag"`
+	HasCommits           bool        `json:"hasCommits" yaml:"hasCommits"`
+	IsShallow            bool        `json:"isShallow" yaml:"isShallow"`
+	DescribeTruncated    bool        `json:"describeTruncated,omitempty" yaml:"describeTruncated,omitempty"`
+	Major                uint64      `json:"major" yaml:"major"`
+	Minor                uint64      `json:"minor" yaml:"minor"`
+	Patch                uint64      `json:"patch" yaml:"patch"`
+	Prerelease           string      `json:"prerelease,omitempty" yaml:"prerelease,omitempty"`
+	BuildMetadata        string      `json:"buildMetadata,omitempty" yaml:"buildMetadata,omitempty"`
+	CommitTime           string      `json:"commitTime,omitempty" yaml:"commitTime,omitempty"`
+	CommitterName        string      `json:"committerName,omitempty" yaml:"committerName,omitempty"`
+	AuthorEmail          string      `json:"authorEmail,omitempty" yaml:"authorEmail,omitempty"`
+	CommitMessageSubject string      `json:"commitMessageSubject,omitempty" yaml:"commitMessageSubject,omitempty"`
+	RemoteURL            string      `json:"remoteURL,omitempty" yaml:"remoteURL,omitempty"`
+	RepoOwner            string      `json:"repoOwner,omitempty" yaml:"repoOwner,omitempty"`
+	RepoName             string      `json:"repoName,omitempty" yaml:"repoName,omitempty"`
+	TagMessage           string      `json:"tagMessage,omitempty" yaml:"tagMessage,omitempty"`
+	Tagger               string      `json:"tagger,omitempty" yaml:"tagger,omitempty"`
+	TagTime              string      `json:"tagTime,omitempty" yaml:"tagTime,omitempty"`
+	CommitSigned         bool        `json:"commitSigned,omitempty" yaml:"commitSigned,omitempty"`
+	TagSigned            bool        `json:"tagSigned,omitempty" yaml:"tagSigned,omitempty"`
+	PullRequestNumber    int         `json:"pullRequestNumber,omitempty" yaml:"pullRequestNumber,omitempty"`
+	CI                   *CI         `json:"ci,omitempty" yaml:"ci,omitempty"`
+	Superproject         *Info       `json:"superproject,omitempty" yaml:"superproject,omitempty"`
+	Submodules           []Submodule `json:"submodules,omitempty" yaml:"submodules,omitempty"`
+}
+
+// fields returns the exported fields of the info without its methods, so
+// that marshaling them does not recurse into MarshalJSON and MarshalYAML
+func (i Info) fields() infoFields {
+	return infoFields{
+		Version:              i.Version,
+		GitCommit:            i.GitCommit,
+		GitCommitShort:       i.GitCommitShort,
+		GitBranch:            i.GitBranch,
+		GitBranchSlug:        i.GitBranchSlug,
+		GitDescribe:          i.GitDescribe,
+		LatestTag:            i.LatestTag,
+		BuildTime:            i.BuildTime,
+		IsDirty:              i.IsDirty,
+		DefaultBranch:        i.DefaultBranch,
+		CommitsSinceTag:      i.CommitsSinceTag,
+		HasCommits:           i.HasCommits,
+		IsShallow:            i.IsShallow,
+		DescribeTruncated:    i.DescribeTruncated,
+		Major:                i.Major,
+		Minor:                i.Minor,
+		Patch:                i.Patch,
+		Prerelease:           i.Prerelease,
+		BuildMetadata:        i.BuildMetadata,
+		CommitTime:           i.CommitTime,
+		CommitterName:        i.CommitterName,
+		AuthorEmail:          i.AuthorEmail,
+		CommitMessageSubject: i.CommitMessageSubject,
+		RemoteURL:            i.RemoteURL,
+		RepoOwner:            i.RepoOwner,
+		RepoName:             i.RepoName,
+		TagMessage:           i.TagMessage,
+		Tagger:               i.Tagger,
+		TagTime:              i.TagTime,
+		CommitSigned:         i.CommitSigned,
+		TagSigned:            i.TagSigned,
+		PullRequestNumber:    i.PullRequestNumber,
+		CI:                   i.CI,
+		Superproject:         i.Superproject,
+		Submodules:           i.Submodules,
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (i Info) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.fields())
+}
+
+// MarshalYAML implements yaml.Marshaler of gopkg.in/yaml.v3
+func (i Info) MarshalYAML() (any, error) {
+	return i.fields(), nil
+}
@@ -0,0 +1,91 @@
+package version
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInfoMarshal(t *testing.T) {
+	info := Info{
+		Version:         "main-gabc123d",
+		GitCommit:       "abc123def456",
+		GitCommitShort:  "abc123d",
+		GitBranch:       "main",
+		GitBranchSlug:   "main",
+		BuildTime:       "2025-01-01T00:00:00Z",
+		DefaultBranch:   "main",
+		CommitsSinceTag: 3,
+		HasCommits:      true,
+		tagPrefix:       "v",
+	}
+
+	expectedJSON := `{"version":"main-gabc123d","gitCommit":"abc123def456","gitCommitShort":"abc123d",` +
+		`"gitBranch":"main","gitBranchSlug":"main","gitDescribe":"","buildTime":"2025-01-01T00:00:00Z",` +
+		`"isDirty":false,"defaultBranch":"main","commitsSinceTag":3,"hasCommits":true,"isShallow":false,` +
+		`"major":0,"minor":0,"patch":0}`
+	for name, v := range map[string]any{"value": info, "pointer": &info} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal(%s) failed: %v", name, err)
+		}
+		if string(data) != expectedJSON {
+			t.Errorf("json.Marshal(%s) = %s, want %s", name, data, expectedJSON)
+		}
+	}
+
+	info.LatestTag = "v1.0.0-rc.1"
+	info.Major, info.Prerelease = 1, "rc.1"
+	info.Superproject = &Info{Version: "v2.0.0", LatestTag: "v2.0.0"}
+	data, err := yaml.Marshal(&info)
+	if err != nil {
+		t.Fatalf("yaml.Marshal failed: %v", err)
+	}
+	expectedYAML := `version: main-gabc123d
+gitCommit: abc123def456
+gitCommitShort: abc123d
+gitBranch: main
+gitBranchSlug: main
+gitDescribe: ""
+latestTag: v1.0.0-rc.1
+buildTime: "2025-01-01T00:00:00Z"
+isDirty: false
+defaultBranch: main
+commitsSinceTag: 3
+hasCommits: true
+isShallow: false
+major: 1
+minor: 0
+patch: 0
+prerelease: rc.1
+superproject:
+    version: v2.0.0
+    gitCommit: ""
+    gitCommitShort: ""
+    gitBranch: ""
+    gitBranchSlug: ""
+    gitDescribe: ""
+    latestTag: v2.0.0
+    buildTime: ""
+    isDirty: false
+    defaultBranch: ""
+    commitsSinceTag: 0
+    hasCommits: false
+    isShallow: false
+    major: 0
+    minor: 0
+    patch: 0
+`
+	if string(data) != expectedYAML {
+		t.Errorf("yaml.Marshal = %s, want %s", data, expectedYAML)
+	}
+
+	var decoded Info
+	if err := json.Unmarshal([]byte(expectedJSON), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if decoded.Version != info.Version || decoded.CommitsSinceTag != info.CommitsSinceTag {
+		t.Errorf("json.Unmarshal = %+v, want round trip of %+v", decoded, info)
+	}
+}
@@ -0,0 +1,84 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// DefaultMetadataFormat is the build metadata template used by WithMetadata
+const DefaultMetadataFormat = "g{{.GitCommitShort}}.{{.GitBranchSlug}}"
+
+// invalidMetadataChars matches characters not allowed in SemVer identifiers
+var invalidMetadataChars = regexp.MustCompile(`[^0-9A-Za-z.-]+`)
+
+// applyMetadata turns the version into a SemVer 2.0 compliant string with the
+// rendered build metadata: the "-g<hash>" suffix is replaced by "+<metadata>".
+// Commits after a SemVer tag become prereleases sorting above it, those after
+// a release of the next patch numbered by the commits since, e.g.
+// v1.0.1-3+gabc123d.main three commits after v1.0.0, those after a prerelease
+// of it with the number appended, e.g. v1.0.0-rc.1.3. Branch slug versions
+// become prereleases of 0.0.0, e.g. v0.0.0-feature-x+gabc123d.feature-x.
+// Other versions, e.g. git describe of a tag that is no SemVer, are returned
+// unchanged.
+func (i *Info) applyMetadata(version string, format string) (string, error) {
+	metadata, err := i.Format(format)
+	if err != nil {
+		return "", err
+	}
+	metadata = invalidMetadataChars.ReplaceAllString(metadata, "-")
+	metadata = strings.Trim(metadata, ".")
+
+	trimmed := strings.TrimSuffix(version, "-g"+i.GitCommitShort)
+	if v, ok := i.describedSemVer(trimmed); ok {
+		version = v
+	} else if _, err := semver.Parse(strings.TrimPrefix(trimmed, i.tagPrefix)); err == nil {
+		version = trimmed
+	} else if trimmed != version && trimmed == i.GitBranchSlug {
+		base := i.formatSemVer(semver.Version{}, i.tagPrefix == "")
+		version = base + "-" + trimmed
+	} else {
+		return version, nil
+	}
+
+	if metadata == "" {
+		return version, nil
+	}
+	if strings.Contains(version, "+") {
+		return version + "." + metadata, nil
+	}
+	return version + "+" + metadata, nil
+}
+
+// describedSemVer returns the prerelease sorting above the SemVer latest tag
+// for git describe output without the hash, e.g. v1.0.1-3 for v1.0.0-3, and
+// false for other versions
+func (i *Info) describedSemVer(version string) (string, bool) {
+	if i.LatestTag == "" || i.CommitsSinceTag == 0 || version+"-g"+i.GitCommitShort != i.describeVersion() {
+		return "", false
+	}
+	current, vPrefix, err := i.latestSemVer()
+	if err != nil {
+		return "", false
+	}
+	next := current
+	if next.Prerelease == "" {
+		next = current.Bump(semver.Patch)
+		next.Prerelease = strconv.Itoa(i.CommitsSinceTag)
+	} else {
+		next.Prerelease = fmt.Sprintf("%s.%d", current.Prerelease, i.CommitsSinceTag)
+	}
+	next.Build = ""
+	return i.formatSemVer(next, vPrefix), true
+}
+
+// appendPrerelease appends a suffix to the version before any build metadata
+func appendPrerelease(version string, suffix string) string {
+	if idx := strings.Index(version, "+"); idx >= 0 {
+		return version[:idx] + suffix + version[idx:]
+	}
+	return version + suffix
+}
@@ -0,0 +1,147 @@
+package version
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+func TestGetVersionInfoWithMetadata(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	short := head.String()[:7]
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "default format",
+			opts:     []Option{WithMetadata("")},
+			expected: "v1.0.1-1+g" + short + ".master",
+		},
+		{
+			name:     "custom format",
+			opts:     []Option{WithMetadata("sha.{{.GitCommitShort}}")},
+			expected: "v1.0.1-1+sha." + short,
+		},
+		{
+			name:     "invalid characters replaced",
+			opts:     []Option{WithMetadata("{{.GitBranch}}_build")},
+			expected: "v1.0.1-1+master-build",
+		},
+		{
+			name:     "branch without tags gets 0.0.0 base",
+			opts:     []Option{WithMetadata(""), WithTagPrefix("release/")},
+			expected: "release/0.0.0-master+g" + short + ".master",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", tt.opts...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.Version != tt.expected {
+				t.Errorf("Version = %q, want %q", info.Version, tt.expected)
+			}
+		})
+	}
+
+	if _, err := GetVersionInfo(tempDir, "", WithMetadata("{{.Unknown}}")); err == nil {
+		t.Error("GetVersionInfo should fail for an invalid metadata template")
+	}
+}
+
+func TestApplyMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     Info
+		expected string
+	}{
+		{
+			name:     "at tag",
+			info:     Info{Version: "v1.0.0", GitDescribe: "v1.0.0", LatestTag: "v1.0.0"},
+			expected: "v1.0.0+gabc123d",
+		},
+		{
+			name:     "after release tag",
+			info:     Info{Version: "v1.0.0-3-gabc123d", GitDescribe: "v1.0.0-3-gabc123d", LatestTag: "v1.0.0", CommitsSinceTag: 3},
+			expected: "v1.0.1-3+gabc123d",
+		},
+		{
+			name:     "after prerelease tag",
+			info:     Info{Version: "v1.0.0-rc.1-3-gabc123d", GitDescribe: "v1.0.0-rc.1-3-gabc123d", LatestTag: "v1.0.0-rc.1", CommitsSinceTag: 3},
+			expected: "v1.0.0-rc.1.3+gabc123d",
+		},
+		{
+			name:     "after tag with stripped prefix",
+			info:     Info{Version: "1.0.0-3-gabc123d", GitDescribe: "release/1.0.0-3-gabc123d", LatestTag: "release/1.0.0", CommitsSinceTag: 3, tagPrefix: "release/", stripTagPrefix: true},
+			expected: "1.0.1-3+gabc123d",
+		},
+		{
+			name:     "branch slug",
+			info:     Info{Version: "feature-x-gabc123d", GitBranchSlug: "feature-x"},
+			expected: "v0.0.0-feature-x+gabc123d",
+		},
+		{
+			name:     "after non-SemVer tag",
+			info:     Info{Version: "build-42-1-gabc123d", GitDescribe: "build-42-1-gabc123d", LatestTag: "build-42", CommitsSinceTag: 1},
+			expected: "build-42-1-gabc123d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			info.GitCommitShort = "abc123d"
+			result, err := info.applyMetadata(info.Version, "g{{.GitCommitShort}}")
+			if err != nil {
+				t.Fatalf("applyMetadata failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("applyMetadata(%q) = %q, want %q", info.Version, result, tt.expected)
+			}
+
+			// Rewritten versions sort above the tag they derive from
+			if info.CommitsSinceTag == 0 || result == info.Version {
+				return
+			}
+			v, err := semver.Parse(strings.TrimPrefix(strings.TrimPrefix(result, info.tagPrefix), "v"))
+			if err != nil {
+				t.Fatalf("applyMetadata(%q) = %q is no SemVer: %v", info.Version, result, err)
+			}
+			tag, err := info.LatestSemVer()
+			if err != nil {
+				t.Fatalf("LatestSemVer failed: %v", err)
+			}
+			if v.Compare(tag) <= 0 {
+				t.Errorf("applyMetadata(%q) = %q does not sort above %s", info.Version, result, info.LatestTag)
+			}
+		})
+	}
+}
+
+func TestAppendPrerelease(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"v1.0.0-1-gabc123d", "v1.0.0-1-gabc123d-20240101120000"},
+		{"v1.0.0-1+gabc123d.main", "v1.0.0-1-20240101120000+gabc123d.main"},
+	}
+
+	for _, tt := range tests {
+		if got := appendPrerelease(tt.version, "-20240101120000"); got != tt.expected {
+			t.Errorf("appendPrerelease(%q) = %q, want %q", tt.version, got, tt.expected)
+		}
+	}
+}
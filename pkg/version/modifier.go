@@ -0,0 +1,88 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Modifier rewrites the computed version before it is output, e.g. to apply
+// the versioning policies of an organization without forking
+type Modifier interface {
+	// Modify returns the new version for the info, whose Version field holds
+	// the version computed so far
+	Modify(ctx context.Context, info *Info) (string, error)
+}
+
+// ModifierFunc adapts a function to the Modifier interface
+type ModifierFunc func(ctx context.Context, info *Info) (string, error)
+
+// Modify implements Modifier
+func (f ModifierFunc) Modify(ctx context.Context, info *Info) (string, error) {
+	return f(ctx, info)
+}
+
+// ExecModifier runs an external command as a Modifier. The command receives
+// the version info as JSON on stdin and as GITVERSION_* environment variables
+// and prints the new version to stdout; empty output keeps the version, so
+// hooks can also only validate it. A non-zero exit status fails.
+type ExecModifier struct {
+	// Command is the program and its arguments; it is not run by a shell
+	Command []string
+	// Dir is the working directory of the command, the current one if empty
+	Dir string
+}
+
+// Modify implements Modifier
+func (m ExecModifier) Modify(ctx context.Context, info *Info) (string, error) {
+	if len(m.Command) == 0 {
+		return "", errors.New("empty hook command")
+	}
+	input, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, m.Command[0], m.Command[1:]...)
+	cmd.Dir = m.Dir
+	cmd.Env = os.Environ()
+	for _, f := range info.Fields() {
+		cmd.Env = append(cmd.Env, "GITVERSION_"+strings.ToUpper(f.Name)+"="+f.Value)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("hook %s: %s", m.Command[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("hook %s: %w", m.Command[0], err)
+	}
+
+	if v := strings.TrimSpace(string(out)); v != "" {
+		return v, nil
+	}
+	return info.Version, nil
+}
+
+// applyModifiers rewrites the version with the modifiers in order
+func (i *Info) applyModifiers(ctx context.Context, modifiers []Modifier) error {
+	for _, m := range modifiers {
+		v, err := m.Modify(ctx, i)
+		if err != nil {
+			return err
+		}
+		if strings.ContainsAny(v, "\n\r") {
+			return fmt.Errorf("invalid version %q: contains a line break", v)
+		}
+		i.Version = v
+	}
+	return nil
+}
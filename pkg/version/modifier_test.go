@@ -0,0 +1,76 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestGetVersionInfoWithModifiers(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	upper := ModifierFunc(func(_ context.Context, info *Info) (string, error) {
+		return strings.ToUpper(info.Version), nil
+	})
+	suffix := ModifierFunc(func(_ context.Context, info *Info) (string, error) {
+		return info.Version + "-" + info.GitBranch, nil
+	})
+	info, err := GetVersionInfo(tempDir, "", WithModifiers(upper, suffix))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Version != "V1.0.0-master" {
+		t.Errorf("Version = %q, want %q", info.Version, "V1.0.0-master")
+	}
+
+	failing := ModifierFunc(func(context.Context, *Info) (string, error) {
+		return "", errors.New("policy violated")
+	})
+	if _, err := GetVersionInfo(tempDir, "", WithModifiers(failing)); err == nil || !strings.Contains(err.Error(), "policy violated") {
+		t.Errorf("GetVersionInfo error = %v, want the modifier error", err)
+	}
+}
+
+func TestExecModifier(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	info := &Info{Version: "v1.0.0", GitBranch: "main"}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+	}{
+		{"environment", `echo "$GITVERSION_VERSION-$GITVERSION_BRANCH"`, "v1.0.0-main", ""},
+		{"stdin", `sed 's/.*"version":"\([^"]*\)".*/\1+build/'`, "v1.0.0+build", ""},
+		{"empty output keeps version", `true`, "v1.0.0", ""},
+		{"failure", `echo "not allowed" >&2; exit 1`, "", "not allowed"},
+		{"multiple lines", `printf 'a\nb\n'`, "", "line break"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := *info
+			err := i.applyModifiers(context.Background(), []Modifier{ExecModifier{Command: []string{"sh", "-c", tt.script}}})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("applyModifiers error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyModifiers failed: %v", err)
+			}
+			if i.Version != tt.want {
+				t.Errorf("Version = %q, want %q", i.Version, tt.want)
+			}
+		})
+	}
+}
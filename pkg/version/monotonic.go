@@ -0,0 +1,90 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// ErrNotMonotonic is returned by WithMonotonic and CheckMonotonic if a
+// version is not higher than every release tag
+var ErrNotMonotonic = errors.New("version is not higher than every release tag")
+
+// WithMonotonic fails with ErrNotMonotonic if the computed version is not
+// higher than every release tag other than the latest tag it is derived
+// from, e.g. v1.3.1-rc.2 on a stale release branch after v1.4.0 was tagged.
+// Release tags are the SemVer tags without prerelease matching the tag prefix
+// and not excluded; versions that are not SemVer, e.g. feature-x-gabc123d,
+// are not checked.
+func WithMonotonic(enabled bool) Option {
+	return func(o *options) {
+		o.monotonic = enabled
+	}
+}
+
+// CheckMonotonic checks that the version, e.g. one about to be tagged, is a
+// semantic version higher than every release tag of the repository, and
+// returns an ErrNotMonotonic error naming the highest tag otherwise. The
+// version may carry the component namespace and tag prefix.
+func CheckMonotonic(repoPath, version string, opts ...Option) error {
+	o := newOptions(opts)
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return err
+	}
+	local, err := localTags(repo)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(local))
+	for name := range local {
+		names = append(names, name)
+	}
+	v, err := semver.Parse(o.trimTagPrefix(version))
+	if err != nil {
+		return fmt.Errorf("invalid version %s: %w", version, err)
+	}
+	return o.checkMonotonic(v, names, "")
+}
+
+// checkMonotonicInfo checks the computed version of WithMonotonic
+func (o *options) checkMonotonicInfo(info *Info) error {
+	v, err := semver.Parse(o.trimTagPrefix(info.Version))
+	if err != nil {
+		// Only semantic versions are released
+		return nil
+	}
+	return o.checkMonotonic(v, info.tags, info.LatestTag)
+}
+
+// checkMonotonic checks that v is higher than the release tags among the
+// tags, except the ignored one
+func (o *options) checkMonotonic(v semver.Version, tags []string, ignore string) error {
+	tags, err := o.versionTags(tags)
+	if err != nil {
+		return err
+	}
+	var highest string
+	var highestVersion semver.Version
+	for _, name := range tags {
+		t, err := semver.Parse(strings.TrimPrefix(name, o.matchPrefix()))
+		if name == ignore || err != nil || t.Prerelease != "" || t.Compare(v) < 0 {
+			continue
+		}
+		if highest == "" || t.Compare(highestVersion) > 0 {
+			highest, highestVersion = name, t
+		}
+	}
+	if highest != "" {
+		return fmt.Errorf("%w: %s is not higher than %s", ErrNotMonotonic, v, highest)
+	}
+	return nil
+}
+
+// trimTagPrefix removes the component namespace and tag prefix from the
+// version
+func (o *options) trimTagPrefix(version string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(version, o.componentTagPrefix()), o.tagPrefix)
+}
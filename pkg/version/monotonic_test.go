@@ -0,0 +1,63 @@
+package version
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestMonotonic(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "1", "First")
+	second := commitTestFile(t, repo, tempDir, "test.txt", "2", "Second")
+	commitTestFile(t, repo, tempDir, "test.txt", "3", "Third")
+	for tag, hash := range map[string]plumbing.Hash{"v1.3.0": first, "v1.4.0": second, "v2.0.0-rc.1": second} {
+		if _, err := repo.CreateTag(tag, hash, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+	for _, branch := range []string{"release/1.3", "feature/x"} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), first)); err != nil {
+			t.Fatalf("Failed to create branch: %v", err)
+		}
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	tests := []struct {
+		name  string
+		ref   string
+		fails bool
+	}{
+		{"after latest tag", "", false},
+		{"stale release branch", "release/1.3", true},
+		{"not semver", "feature/x", false},
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.Name()+"/"+tt.name, func(t *testing.T) {
+				_, err := GetVersionInfo(tempDir, "master", WithBackend(backend), WithRef(tt.ref), WithMonotonic(true),
+					WithReleaseBranches(), WithTagPrefix("v"))
+				if tt.fails && !errors.Is(err, ErrNotMonotonic) {
+					t.Errorf("GetVersionInfo() error = %v, want ErrNotMonotonic", err)
+				}
+				if !tt.fails && err != nil {
+					t.Errorf("GetVersionInfo failed: %v", err)
+				}
+			})
+		}
+	}
+
+	for version, fails := range map[string]bool{"v1.4.1": false, "v1.5.0": false, "v1.4.0": true, "v1.3.1": true, "v1.4.0-rc.1": true} {
+		err := CheckMonotonic(tempDir, version, WithTagPrefix("v"))
+		if fails != errors.Is(err, ErrNotMonotonic) || (!fails && err != nil) {
+			t.Errorf("CheckMonotonic(%s) = %v, want failure %v", version, err, fails)
+		}
+	}
+	if err := CheckMonotonic(tempDir, "main-gabc123d"); err == nil || errors.Is(err, ErrNotMonotonic) {
+		t.Errorf("CheckMonotonic() = %v for an invalid version, want a parse error", err)
+	}
+}
@@ -0,0 +1,153 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// NextVersion parses the latest tag as SemVer and returns the next version for
+// the given bump. A leading "v" of the tag is kept, as are the component
+// namespace and tag prefix unless the prefix is stripped. Without tags the
+// bump is applied to 0.0.0, prefixed with the tag prefix or "v" if there is none.
+func (i *Info) NextVersion(bump semver.Bump) (string, error) {
+	current, vPrefix, err := i.latestSemVer()
+	if err != nil {
+		return "", err
+	}
+
+	next := i.formatSemVer(current.Bump(bump), vPrefix)
+	if !i.stripTagPrefix {
+		next = i.componentPrefix + next
+	}
+	return next, nil
+}
+
+// ReleaseAs returns the given version, e.g. 2.0.0 of a Release-As commit
+// trailer, formatted like NextVersion would return it. It must have higher
+// precedence than the latest tag.
+func (i *Info) ReleaseAs(version string) (string, error) {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return "", fmt.Errorf("invalid release version: %w", err)
+	}
+	current, vPrefix, err := i.latestSemVer()
+	if err != nil {
+		return "", err
+	}
+	if i.LatestTag != "" && v.Compare(current) <= 0 {
+		return "", fmt.Errorf("release version %s is not higher than the latest tag %s", version, i.LatestTag)
+	}
+
+	next := i.formatSemVer(v, vPrefix)
+	if !i.stripTagPrefix {
+		next = i.componentPrefix + next
+	}
+	return next, nil
+}
+
+// NextPrerelease returns the next prerelease with the given label (e.g. "rc")
+// of the version NextVersion would return, numbered after the existing tags of
+// that prerelease: v1.4.0-rc.1, then v1.4.0-rc.2 and so on. Prereleases of the
+// latest version itself are not allowed, so semver.None bumps the patch
+// version unless the latest tag is a prerelease.
+func (i *Info) NextPrerelease(repo *git.Repository, bump semver.Bump, label string) (string, error) {
+	current, vPrefix, err := i.latestSemVer()
+	if err != nil {
+		return "", err
+	}
+	if bump == semver.None && current.Prerelease == "" {
+		bump = semver.Patch
+	}
+	next := current.Bump(bump)
+
+	tags, err := localTags(repo)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	number := prereleaseNumber(names, i.componentPrefix+i.tagPrefix, next, label)
+
+	next.Prerelease = fmt.Sprintf("%s.%d", label, number+1)
+	s := i.formatSemVer(next, vPrefix)
+	if !i.stripTagPrefix {
+		s = i.componentPrefix + s
+	}
+	return s, nil
+}
+
+// TagName returns the name of the tag for a version returned by NextVersion,
+// ReleaseAs or NextPrerelease: the version with component namespace and tag
+// prefix, even if they are stripped from versions
+func (i *Info) TagName(version string) string {
+	if i.stripTagPrefix {
+		return i.componentPrefix + i.tagPrefix + version
+	}
+	return version
+}
+
+// LatestSemVer parses the latest tag without component namespace and tag
+// prefix as SemVer, 0.0.0 if there is no tag
+func (i *Info) LatestSemVer() (semver.Version, error) {
+	v, _, err := i.latestSemVer()
+	return v, err
+}
+
+// SemVer returns the version as SemVer, e.g. to check it against a range: the
+// version itself if it is one, else the latest tag it is derived from, as for
+// git describe (v1.4.3-2-gabc123d), branch slug and dirty versions, whose
+// suffixes would otherwise make them prereleases of the latest tag. Like git
+// describe, versions without commits since the latest tag are not derived.
+func (i *Info) SemVer() (semver.Version, error) {
+	derived := i.IsDirty || i.CommitsSinceTag > 0 && strings.Contains(i.Version, "-g"+i.GitCommitShort)
+	if v, err := semver.Parse(strings.TrimPrefix(strings.TrimPrefix(i.Version, i.componentPrefix), i.tagPrefix)); err == nil && !derived {
+		return v, nil
+	}
+	return i.LatestSemVer()
+}
+
+// describeSuffixPattern matches the suffix git describe adds to the tag of
+// a commit after it
+var describeSuffixPattern = regexp.MustCompile(`-[0-9]+-g[0-9a-f]{4,40}$`)
+
+// TrimDescribe returns the tag of git describe output, e.g. v1.4.3 of
+// v1.4.3-2-gabc123d; other versions are returned unchanged
+func TrimDescribe(version string) string {
+	return describeSuffixPattern.ReplaceAllString(version, "")
+}
+
+// latestSemVer parses the latest tag without component namespace and tag
+// prefix as SemVer and reports whether it has a leading "v". Without tags
+// 0.0.0 is returned, with a "v" unless a tag prefix is configured.
+func (i *Info) latestSemVer() (semver.Version, bool, error) {
+	if i.LatestTag == "" {
+		return semver.Version{}, i.tagPrefix == "", nil
+	}
+
+	tag := strings.TrimPrefix(i.LatestTag, i.componentPrefix+i.tagPrefix)
+	v, err := semver.Parse(tag)
+	if err != nil {
+		return semver.Version{}, false, fmt.Errorf("latest tag is not a semantic version: %w", err)
+	}
+	return v, strings.HasPrefix(tag, "v"), nil
+}
+
+// formatSemVer formats a computed version like the emitted version: with a
+// leading "v" if requested and the tag prefix unless it is stripped
+func (i *Info) formatSemVer(v semver.Version, vPrefix bool) string {
+	s := v.String()
+	if vPrefix {
+		s = "v" + s
+	}
+	if !i.stripTagPrefix {
+		s = i.tagPrefix + s
+	}
+	return s
+}
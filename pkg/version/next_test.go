@@ -0,0 +1,336 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+func TestInfoNextVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		latestTag string
+		component string
+		tagPrefix string
+		strip     bool
+		bump      semver.Bump
+		expected  string
+		wantErr   bool
+	}{
+		{
+			name:      "patch with v prefix",
+			latestTag: "v1.2.3",
+			bump:      semver.Patch,
+			expected:  "v1.2.4",
+		},
+		{
+			name:      "minor without prefix",
+			latestTag: "1.2.3",
+			bump:      semver.Minor,
+			expected:  "1.3.0",
+		},
+		{
+			name:      "major",
+			latestTag: "v1.2.3",
+			bump:      semver.Major,
+			expected:  "v2.0.0",
+		},
+		{
+			name:      "release prerelease",
+			latestTag: "v2.0.0-rc.1",
+			bump:      semver.Major,
+			expected:  "v2.0.0",
+		},
+		{
+			name:     "no tags",
+			bump:     semver.Minor,
+			expected: "v0.1.0",
+		},
+		{
+			name:      "custom prefix kept",
+			latestTag: "release/1.2.3",
+			tagPrefix: "release/",
+			bump:      semver.Minor,
+			expected:  "release/1.3.0",
+		},
+		{
+			name:      "custom prefix stripped",
+			latestTag: "release/1.2.3",
+			tagPrefix: "release/",
+			strip:     true,
+			bump:      semver.Patch,
+			expected:  "1.2.4",
+		},
+		{
+			name:      "v prefix without tags",
+			tagPrefix: "v",
+			bump:      semver.Patch,
+			expected:  "v0.0.1",
+		},
+		{
+			name:      "component without tags",
+			component: "svc/",
+			bump:      semver.Patch,
+			expected:  "svc/v0.0.1",
+		},
+		{
+			name:      "component with tag prefix",
+			latestTag: "svc/release-1.2.3",
+			component: "svc/",
+			tagPrefix: "release-",
+			bump:      semver.Minor,
+			expected:  "svc/release-1.3.0",
+		},
+		{
+			name:      "non-semver tag",
+			latestTag: "release-2024",
+			bump:      semver.Patch,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &Info{
+				LatestTag:       tt.latestTag,
+				componentPrefix: tt.component,
+				tagPrefix:       tt.tagPrefix,
+				stripTagPrefix:  tt.strip,
+			}
+			result, err := info.NextVersion(tt.bump)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NextVersion(%s) expected error, got %q", tt.bump, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NextVersion(%s) failed: %v", tt.bump, err)
+			}
+			if result != tt.expected {
+				t.Errorf("NextVersion(%s) = %q, want %q", tt.bump, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInfoReleaseAs(t *testing.T) {
+	tests := []struct {
+		name      string
+		latestTag string
+		component string
+		version   string
+		expected  string
+		wantErr   bool
+	}{
+		{"higher version", "v1.2.3", "", "2.0.0", "v2.0.0", false},
+		{"leading v", "1.2.3", "", "v2.0.0", "2.0.0", false},
+		{"no tags", "", "", "1.0.0", "v1.0.0", false},
+		{"component", "svc/v1.2.3", "svc/", "1.5.0", "svc/v1.5.0", false},
+		{"not higher", "v1.2.3", "", "1.2.3", "", true},
+		{"invalid", "v1.2.3", "", "two", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &Info{LatestTag: tt.latestTag, componentPrefix: tt.component}
+			result, err := info.ReleaseAs(tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ReleaseAs(%s) expected error, got %q", tt.version, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReleaseAs(%s) failed: %v", tt.version, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ReleaseAs(%s) = %q, want %q", tt.version, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInfoNextPrerelease(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	stable := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.3.0", stable, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	info, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	next, err := info.NextPrerelease(repo, semver.Minor, "rc")
+	if err != nil {
+		t.Fatalf("NextPrerelease failed: %v", err)
+	}
+	if next != "v1.4.0-rc.1" {
+		t.Errorf("NextPrerelease after stable = %q, want %q", next, "v1.4.0-rc.1")
+	}
+
+	for i, tag := range []string{"v1.4.0-rc.1", "v1.4.0-rc.2"} {
+		hash := commitTestFile(t, repo, tempDir, "test.txt", tag, "Commit "+tag)
+		if _, err := repo.CreateTag(tag, hash, nil); err != nil {
+			t.Fatalf("Failed to create tag %d: %v", i, err)
+		}
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "head", "Head commit")
+	info, err = GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+
+	tests := []struct {
+		bump     semver.Bump
+		label    string
+		expected string
+	}{
+		{semver.None, "rc", "v1.4.0-rc.3"},
+		{semver.Patch, "rc", "v1.4.0-rc.3"},
+		{semver.Patch, "beta", "v1.4.0-beta.1"},
+		{semver.Major, "rc", "v2.0.0-rc.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bump.String()+"/"+tt.label, func(t *testing.T) {
+			next, err := info.NextPrerelease(repo, tt.bump, tt.label)
+			if err != nil {
+				t.Fatalf("NextPrerelease failed: %v", err)
+			}
+			if next != tt.expected {
+				t.Errorf("NextPrerelease = %q, want %q", next, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInfoTagName(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "svc/test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("svc/release/1.0.0", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	opts := []Option{WithComponentPath("svc"), WithTagPrefix("release/"), WithStripTagPrefix(true)}
+
+	// Tag the next version and version the tagged commit like the tag command
+	for _, want := range []string{"1.0.1", "1.0.2"} {
+		head = commitTestFile(t, repo, tempDir, "svc/test.txt", want, "Commit "+want)
+		info, err := GetVersionInfo(tempDir, "", opts...)
+		if err != nil {
+			t.Fatalf("GetVersionInfo failed: %v", err)
+		}
+		next, err := info.NextVersion(semver.Patch)
+		if err != nil {
+			t.Fatalf("NextVersion failed: %v", err)
+		}
+		if next != want {
+			t.Fatalf("NextVersion = %q, want %q", next, want)
+		}
+		tag := info.TagName(next)
+		if tag != "svc/release/"+want {
+			t.Errorf("TagName(%q) = %q, want %q", next, tag, "svc/release/"+want)
+		}
+		if _, err := repo.CreateTag(tag, head, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+
+		info, err = GetVersionInfo(tempDir, "", opts...)
+		if err != nil {
+			t.Fatalf("GetVersionInfo failed: %v", err)
+		}
+		if info.LatestTag != tag || info.Version != want {
+			t.Errorf("after tagging: LatestTag = %q, Version = %q, want %q, %q", info.LatestTag, info.Version, tag, want)
+		}
+	}
+
+	info := Info{LatestTag: "v1.0.0"}
+	if tag := info.TagName("v1.0.1"); tag != "v1.0.1" {
+		t.Errorf("TagName without stripping = %q, want v1.0.1", tag)
+	}
+}
+
+func TestInfoLatestSemVer(t *testing.T) {
+	info := Info{LatestTag: "svc/release/v1.2.3-rc.1", componentPrefix: "svc/", tagPrefix: "release/"}
+	v, err := info.LatestSemVer()
+	if err != nil {
+		t.Fatalf("LatestSemVer() failed: %v", err)
+	}
+	if expected := (semver.Version{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1"}); v != expected {
+		t.Errorf("LatestSemVer() = %+v, want %+v", v, expected)
+	}
+
+	if v, err := (&Info{}).LatestSemVer(); err != nil || v != (semver.Version{}) {
+		t.Errorf("LatestSemVer() without tag = %+v, %v, want 0.0.0", v, err)
+	}
+	if _, err := (&Info{LatestTag: "latest"}).LatestSemVer(); err == nil {
+		t.Error("LatestSemVer() expected error for non-SemVer tag")
+	}
+}
+
+func TestInfoSemVer(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     Info
+		expected string
+	}{
+		{"tagged", Info{Version: "v1.2.3", LatestTag: "v1.2.3"}, "1.2.3"},
+		{"describe", Info{Version: "v1.2.3-2-gabc123d", LatestTag: "v1.2.3", CommitsSinceTag: 2, GitCommitShort: "abc123d"}, "1.2.3"},
+		{"slug", Info{Version: "feature-x-gabc123d", LatestTag: "v1.2.3", CommitsSinceTag: 2, GitCommitShort: "abc123d"}, "1.2.3"},
+		{"dirty", Info{Version: "v1.2.3-dirty", LatestTag: "v1.2.3", IsDirty: true}, "1.2.3"},
+		{"prerelease", Info{Version: "v1.3.0-beta.2", LatestTag: "v1.2.3", CommitsSinceTag: 2, GitCommitShort: "abc123d"}, "1.3.0-beta.2"},
+		{"tag prefix", Info{Version: "svc/release/1.2.3", LatestTag: "svc/release/1.2.3", componentPrefix: "svc/", tagPrefix: "release/"}, "1.2.3"},
+		{"no tag", Info{Version: "main-gabc123d", CommitsSinceTag: 2, GitCommitShort: "abc123d"}, "0.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.info.SemVer()
+			if err != nil {
+				t.Fatalf("SemVer() failed: %v", err)
+			}
+			if v.String() != tt.expected {
+				t.Errorf("SemVer() = %s, want %s", v, tt.expected)
+			}
+		})
+	}
+
+	// Commits after a tag on the default branch satisfy ranges of the tag
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+	info, err := GetVersionInfo(tempDir, "master")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	v, err := info.SemVer()
+	if err != nil {
+		t.Fatalf("SemVer() failed: %v", err)
+	}
+	constraint, err := semver.ParseConstraint(">=1.0 <2")
+	if err != nil {
+		t.Fatalf("ParseConstraint failed: %v", err)
+	}
+	if !constraint.Check(v) {
+		t.Errorf("SemVer() = %s of %s does not satisfy %s", v, info.Version, constraint)
+	}
+}
+
+func TestTrimDescribe(t *testing.T) {
+	tests := map[string]string{
+		"v1.4.3-2-gabc123d":   "v1.4.3",
+		"1.4.3-rc.1-12-gabc1": "1.4.3-rc.1",
+		"v1.4.3":              "v1.4.3",
+		"v1.4.3-rc.1":         "v1.4.3-rc.1",
+		"feature-x-gabc123d":  "feature-x-gabc123d",
+	}
+	for version, expected := range tests {
+		if result := TrimDescribe(version); result != expected {
+			t.Errorf("TrimDescribe(%q) = %q, want %q", version, result, expected)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package version
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// Option configures how version information is computed
+type Option func(*options)
+
+type options struct {
+	tagPrefix          string
+	tagExcludes        []string
+	stripTagPrefix     bool
+	componentPath      string
+	componentNamespace string
+	strategy           Strategy
+	dirtyCheck         bool
+	dirtyExcludes      []string
+	sharedPaths        []string
+	dirtyUntracked     bool
+	dirtyFormat        string
+	fetchRemote        string
+	deepen             bool
+	tagCheckRemote     string
+	remoteHEADTimeout  time.Duration
+	signersFile        string
+	cacheDir           string
+	profile            *Profile
+	firstParent        bool
+	maxDescribeDepth   int
+	metadataFormat     string
+	ref                string
+	backend            Backend
+	superproject       bool
+	recurseSubmodules  bool
+	// pinned versions a detached HEAD as on the default branch, for submodules
+	pinned           bool
+	abbrev           int
+	releaseBranches  []ReleaseBranch
+	prereleaseLabels []PrereleaseLabel
+	monotonic        bool
+	branchTemplates  []BranchTemplate
+	modifiers        []Modifier
+	detachedBranch   string
+	headTag          string
+	ci               *CI
+	prTemplate       string
+	buildInfo        func() (*debug.BuildInfo, bool)
+	ceilingDirs      []string
+	oneFilesystem    bool
+	logger           *slog.Logger
+	// defaultBranch is the default branch passed to GetVersionInfo, if any
+	defaultBranch string
+	// repo is the repository passed to FromRepository, if any
+	repo *git.Repository
+	// ctx is the context passed to GetVersionInfoContext
+	ctx context.Context
+}
+
+// newOptions applies the given options on top of the defaults
+func newOptions(opts []Option) *options {
+	o := &options{
+		strategy:    GitHubFlow(),
+		dirtyCheck:  true,
+		dirtyFormat: DirtyFormatTimestamp,
+		backend:     GoGit(),
+		prTemplate:  DefaultPullRequestTemplate,
+		ctx:         context.Background(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTagPrefix only considers tags starting with the given prefix
+// (e.g. "v" or "release/") for describe and latest tag
+func WithTagPrefix(prefix string) Option {
+	return func(o *options) {
+		o.tagPrefix = prefix
+	}
+}
+
+// WithTagExcludes ignores tags matching the given patterns when searching for
+// the nearest tag, e.g. "*-rc*" or "nightly-*", so that prerelease and
+// automation tags do not determine the version. Globs match the full tag name
+// or the name without the component namespace and tag prefix; patterns
+// enclosed in slashes are regular expressions, e.g. "/-(alpha|beta)/".
+func WithTagExcludes(patterns ...string) Option {
+	return func(o *options) {
+		o.tagExcludes = append(o.tagExcludes, patterns...)
+	}
+}
+
+// WithStripTagPrefix removes the tag prefix from the emitted version
+func WithStripTagPrefix(strip bool) Option {
+	return func(o *options) {
+		o.stripTagPrefix = strip
+	}
+}
+
+// WithComponentPath scopes versioning to a subdirectory of the repository
+// (e.g. "services/api"): only commits touching that path count towards the
+// describe distance and only tags named "<path>/<tag-prefix>..." are considered
+func WithComponentPath(path string) Option {
+	return func(o *options) {
+		o.componentPath = cleanComponentPath(path)
+	}
+}
+
+// WithComponentTagNamespace sets the namespace the tags of the component
+// start with instead of "<path>/", e.g. "api-" for tags like api-v1.2.0, as
+// rendered from a tag template by ComponentTagNamespace. It only applies with
+// WithComponentPath.
+func WithComponentTagNamespace(namespace string) Option {
+	return func(o *options) {
+		o.componentNamespace = namespace
+	}
+}
+
+// componentTagPrefix returns the tag namespace of the component, if any
+func (o *options) componentTagPrefix() string {
+	switch {
+	case o.componentPath == "":
+		return ""
+	case o.componentNamespace != "":
+		return o.componentNamespace
+	}
+	return o.componentPath + "/"
+}
+
+// matchPrefix returns the full prefix tags must start with
+func (o *options) matchPrefix() string {
+	return o.componentTagPrefix() + o.tagPrefix
+}
+
+// WithStrategy selects the strategy the version is computed with (default: GitHubFlow)
+func WithStrategy(strategy Strategy) Option {
+	return func(o *options) {
+		if strategy != nil {
+			o.strategy = strategy
+		}
+	}
+}
+
+// WithDirtyCheck enables or disables the check for uncommitted changes (default: enabled).
+// Disabling it saves the worktree scan on large repositories; the tree is then reported clean.
+func WithDirtyCheck(enabled bool) Option {
+	return func(o *options) {
+		o.dirtyCheck = enabled
+	}
+}
+
+// WithDirtyExcludes skips paths matching the given patterns in the dirty check,
+// e.g. "vendor/", "node_modules/" or "*.lock"
+func WithDirtyExcludes(patterns ...string) Option {
+	return func(o *options) {
+		o.dirtyExcludes = append(o.dirtyExcludes, patterns...)
+	}
+}
+
+// WithSharedPaths counts changes to the given paths or globs, e.g. "go.mod"
+// or "libs/common/", in the dirty check of every component. Without a
+// component path the whole working tree is checked anyway.
+func WithSharedPaths(paths ...string) Option {
+	return func(o *options) {
+		o.sharedPaths = append(o.sharedPaths, paths...)
+	}
+}
+
+// WithDirtyUntracked also reports the working tree as dirty if it contains
+// untracked files that are not ignored by .gitignore, .git/info/exclude or
+// core.excludesFile (default: only changes to tracked files count)
+func WithDirtyUntracked(enabled bool) Option {
+	return func(o *options) {
+		o.dirtyUntracked = enabled
+	}
+}
+
+// WithDirtyFormat sets the suffix appended to the version of a dirty working
+// tree, e.g. DirtyFormatHash or "+dirty" (default: DirtyFormatTimestamp). See
+// ParseDirtyFormat for the placeholders.
+func WithDirtyFormat(format string) Option {
+	return func(o *options) {
+		if format != "" {
+			o.dirtyFormat = format
+		}
+	}
+}
+
+// WithFetchTags fetches all tags from the remote (default: DefaultFetchRemote)
+// before the version is computed, for CI clones without tags. HTTP(S) remotes
+// authenticate with the GITVERSION_TOKEN and optional GITVERSION_USERNAME
+// environment variables, SSH remotes with the ssh-agent.
+func WithFetchTags(remote string) Option {
+	return func(o *options) {
+		if remote == "" {
+			remote = DefaultFetchRemote
+		}
+		o.fetchRemote = remote
+	}
+}
+
+// WithDeepen fetches more history of a shallow clone, doubling the depth,
+// until a tag is reachable or the history is complete. It fetches from the
+// remote of WithFetchTags, with the same authentication.
+func WithDeepen(enabled bool) Option {
+	return func(o *options) {
+		o.deepen = enabled
+	}
+}
+
+// deepenRemote returns the remote WithDeepen fetches from
+func (o *options) deepenRemote() string {
+	if o.fetchRemote != "" {
+		return o.fetchRemote
+	}
+	return DefaultFetchRemote
+}
+
+// WithVerifySignatures verifies the signatures of HEAD and the latest tag
+// against the keys in the file, an armored OpenPGP public keyring or an SSH
+// allowed_signers file, and reports the result in Info.CommitSigned and
+// Info.TagSigned
+func WithVerifySignatures(signersFile string) Option {
+	return func(o *options) {
+		o.signersFile = signersFile
+	}
+}
+
+// WithCache caches the repository state in the directory, keyed by HEAD, the
+// refs, the index and the options, so repeated calls on an unchanged
+// repository skip the history walk. The working tree is checked on every call.
+// The cache is not used with WithFetchTags and WithDeepen.
+func WithCache(dir string) Option {
+	return func(o *options) {
+		o.cacheDir = dir
+	}
+}
+
+// WithProfile records the time spent in each phase of GetVersionInfo in the
+// profile, e.g. to find out why versioning a large repository is slow
+func WithProfile(p *Profile) Option {
+	return func(o *options) {
+		o.profile = p
+	}
+}
+
+// WithFirstParent only follows the first parent of merge commits when searching
+// for tags and computing the distance, like git describe --first-parent, so
+// tags on merged feature branches do not leak into mainline versions
+func WithFirstParent(firstParent bool) Option {
+	return func(o *options) {
+		o.firstParent = firstParent
+	}
+}
+
+// WithMaxDescribeDepth stops searching for the nearest tag after the given
+// number of commits, bounding the time spent on huge histories without tags.
+// If no tag is found within them, the version falls back to
+// <branch-slug>-g<hash> and Info.DescribeTruncated is set. Zero searches the
+// whole history.
+func WithMaxDescribeDepth(n int) Option {
+	return func(o *options) {
+		o.maxDescribeDepth = n
+	}
+}
+
+// WithRef computes the version for the given branch, tag or commit instead of
+// HEAD. Tags and commits are versioned as on the default branch, e.g. v1.2.3
+// for the tag v1.2.3. The working tree is not checked for uncommitted changes.
+func WithRef(ref string) Option {
+	return func(o *options) {
+		o.ref = ref
+	}
+}
+
+// WithDetachedBranch reports HEAD as being on the given branch if it is
+// detached, as in CI jobs that check out the commit of a branch; with WithRef
+// it has no effect
+func WithDetachedBranch(branch string) Option {
+	return func(o *options) {
+		o.detachedBranch = branch
+	}
+}
+
+// WithHeadTag treats HEAD as tagged with the given tag if no tag matching the
+// tag prefix points to it, as in CI jobs for a tag whose clone lacks the tag.
+// Tags not matching the tag prefix or excluded are ignored; with WithRef it
+// has no effect. If a local tag of that name points to another commit,
+// GetVersionInfo fails.
+func WithHeadTag(tag string) Option {
+	return func(o *options) {
+		o.headTag = tag
+	}
+}
+
+// WithCI sets Info.CI to the build, e.g. from DetectCI. Its default branch is
+// used if GetVersionInfo is not given one, its branch and tag as with
+// WithDetachedBranch and WithHeadTag unless these are set. Builds of a tag
+// without a branch are versioned as on the default branch.
+func WithCI(ci *CI) Option {
+	return func(o *options) {
+		o.ci = ci
+	}
+}
+
+// DefaultPullRequestTemplate is the version template of pull request builds,
+// e.g. pr-123-gabc123d
+const DefaultPullRequestTemplate = "pr-{pr}-g{sha}"
+
+// WithPullRequestTemplate renders the version of pull request builds, as
+// reported by WithCI, from the template instead of DefaultPullRequestTemplate.
+// It takes the placeholders of branch templates and {pr}, the pull request
+// number, and precedes branch templates. An empty template restores the
+// default.
+func WithPullRequestTemplate(tmpl string) Option {
+	return func(o *options) {
+		if tmpl == "" {
+			tmpl = DefaultPullRequestTemplate
+		}
+		o.prTemplate = tmpl
+	}
+}
+
+// WithBackend selects how the repository is read (default: GoGit)
+func WithBackend(backend Backend) Option {
+	return func(o *options) {
+		if backend != nil {
+			o.backend = backend
+		}
+	}
+}
+
+// WithSuperproject adds the version info of the superproject to Info.Superproject
+// if the repository is checked out as a submodule
+func WithSuperproject(enabled bool) Option {
+	return func(o *options) {
+		o.superproject = enabled
+	}
+}
+
+// WithRecurseSubmodules adds the version info of the checked out submodules,
+// and recursively of theirs, at the commits recorded by the repository to
+// Info.Submodules
+func WithRecurseSubmodules(enabled bool) Option {
+	return func(o *options) {
+		o.recurseSubmodules = enabled
+	}
+}
+
+// WithAbbrev sets the length of abbreviated commit hashes, or AbbrevAuto for
+// the shortest unique prefix of at least 7 characters. By default core.abbrev
+// from git config is used, else 7 characters.
+func WithAbbrev(length int) Option {
+	return func(o *options) {
+		o.abbrev = length
+	}
+}
+
+// WithReleaseBranches derives the version of branches matching one of the
+// rules from the branch name, e.g. release/1.4 becomes v1.4.0-rc.N where N is
+// the number of commits since the latest tag. The rules take precedence over
+// the strategy. Without rules DefaultReleaseBranches is used.
+func WithReleaseBranches(rules ...ReleaseBranch) Option {
+	return func(o *options) {
+		if len(rules) == 0 {
+			rules = DefaultReleaseBranches
+		}
+		o.releaseBranches = rules
+	}
+}
+
+// WithPrereleaseLabels versions branches matching one of the rules as
+// prereleases of the rule's label, e.g. develop becomes v1.5.0-beta.12 after
+// the tag v1.5.0-beta.11. The rules take precedence over the strategy but not
+// over release branch rules.
+func WithPrereleaseLabels(rules ...PrereleaseLabel) Option {
+	return func(o *options) {
+		o.prereleaseLabels = append(o.prereleaseLabels, rules...)
+	}
+}
+
+// listsTags reports whether WithMonotonic or a prerelease label rule numbered
+// after the existing tags needs the tags, which the backends then list
+func (o *options) listsTags() bool {
+	if o.monotonic {
+		return true
+	}
+	for _, rule := range o.prereleaseLabels {
+		if rule.Numbering != NumberingHeight {
+			return true
+		}
+	}
+	return false
+}
+
+// WithBranchTemplates renders the version of branches matching one of the
+// templates' patterns from the first matching template. Templates take
+// precedence over release branch rules and the strategy.
+func WithBranchTemplates(templates ...BranchTemplate) Option {
+	return func(o *options) {
+		o.branchTemplates = append(o.branchTemplates, templates...)
+	}
+}
+
+// WithModifiers rewrites the computed version with the modifiers in order,
+// after build metadata and the dirty suffix were added
+func WithModifiers(modifiers ...Modifier) Option {
+	return func(o *options) {
+		o.modifiers = append(o.modifiers, modifiers...)
+	}
+}
+
+// WithMetadata emits strictly SemVer 2.0 compliant versions with build
+// metadata rendered from the given Go template instead of the "-g<hash>"
+// suffix. An empty format uses DefaultMetadataFormat.
+func WithMetadata(format string) Option {
+	return func(o *options) {
+		if format == "" {
+			format = DefaultMetadataFormat
+		}
+		o.metadataFormat = format
+	}
+}
+
+// WithBuildInfoFallback reports the VCS settings the go command stamped into
+// the running binary (vcs.revision, vcs.time and vcs.modified) and its module
+// version if no repository contains the path, so binaries built outside of a
+// checkout still report a version; see debug.ReadBuildInfo
+func WithBuildInfoFallback(enabled bool) Option {
+	return func(o *options) {
+		o.buildInfo = nil
+		if enabled {
+			o.buildInfo = debug.ReadBuildInfo
+		}
+	}
+}
+
+// WithCeilingDirectories stops the search for the repository before walking
+// up into one of the directories, in addition to GIT_CEILING_DIRECTORIES, e.g.
+// the home directory so that a dotfiles repository there is never used
+func WithCeilingDirectories(dirs ...string) Option {
+	return func(o *options) {
+		o.ceilingDirs = append(o.ceilingDirs, dirs...)
+	}
+}
+
+// WithOneFilesystem stops the search for the repository at filesystem
+// boundaries such as mount points, like git unless
+// GIT_DISCOVERY_ACROSS_FILESYSTEM is set
+func WithOneFilesystem(enabled bool) Option {
+	return func(o *options) {
+		o.oneFilesystem = enabled
+	}
+}
+
+// WithLogger traces how the version is computed to the logger: the
+// repository found, the default branch, the latest tag, the dirty check and
+// the rule the version was derived by at info level, the tags considered and
+// the kind of changes found at debug level, and each directory searched and
+// git command run at LevelTrace
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
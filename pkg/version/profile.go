@@ -0,0 +1,83 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phases of GetVersionInfo timed by WithProfile
+const (
+	// PhaseOpen opens the repository
+	PhaseOpen = "open"
+	// PhaseTags enumerates the tags describe considers
+	PhaseTags = "tags"
+	// PhaseDescribe walks the history to the nearest tag and counts the
+	// commits since
+	PhaseDescribe = "describe"
+	// PhaseStatus checks the working tree for uncommitted changes
+	PhaseStatus = "status"
+)
+
+// PhaseTiming is the time spent in a phase
+type PhaseTiming struct {
+	Name     string
+	Duration time.Duration
+	// Calls is the number of times the phase ran, e.g. once per repository
+	// with a superproject
+	Calls int
+}
+
+// Profile records the time spent in each phase of GetVersionInfo; see
+// WithProfile. It is safe for concurrent use.
+type Profile struct {
+	mu     sync.Mutex
+	phases []PhaseTiming
+}
+
+// Phases returns the timings in the order the phases first ran
+func (p *Profile) Phases() []PhaseTiming {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PhaseTiming(nil), p.phases...)
+}
+
+// String returns the timings as aligned lines, e.g. "describe  12.5ms"
+func (p *Profile) String() string {
+	var b strings.Builder
+	for _, phase := range p.Phases() {
+		fmt.Fprintf(&b, "%-10s%v", phase.Name, phase.Duration)
+		if phase.Calls > 1 {
+			fmt.Fprintf(&b, " (%d calls)", phase.Calls)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// add adds the duration to the phase
+func (p *Profile) add(name string, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.phases {
+		if p.phases[i].Name == name {
+			p.phases[i].Duration += d
+			p.phases[i].Calls++
+			return
+		}
+	}
+	p.phases = append(p.phases, PhaseTiming{Name: name, Duration: d, Calls: 1})
+}
+
+// phase starts timing the phase and returns the function that stops it. It
+// does nothing without WithProfile.
+func (o *options) phase(name string) func() {
+	if o.profile == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		o.profile.add(name, time.Since(start))
+	}
+}
@@ -0,0 +1,52 @@
+package version
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetVersionInfoProfile(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			p := &Profile{}
+			if _, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithProfile(p)); err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			var names []string
+			for _, phase := range p.Phases() {
+				names = append(names, phase.Name)
+				if phase.Calls != 1 {
+					t.Errorf("phase %s ran %d times, want 1", phase.Name, phase.Calls)
+				}
+			}
+			want := []string{PhaseOpen, PhaseTags, PhaseDescribe, PhaseStatus}
+			if strings.Join(names, ",") != strings.Join(want, ",") {
+				t.Errorf("phases = %v, want %v", names, want)
+			}
+			if s := p.String(); !strings.Contains(s, "describe  ") {
+				t.Errorf("String() = %q, want a line per phase", s)
+			}
+
+			// Phases of further calls are added up
+			if _, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithProfile(p), WithDirtyCheck(false)); err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			for _, phase := range p.Phases() {
+				if phase.Name != PhaseStatus && phase.Calls != 2 {
+					t.Errorf("phase %s ran %d times, want 2", phase.Name, phase.Calls)
+				}
+			}
+		})
+	}
+}
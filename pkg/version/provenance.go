@@ -0,0 +1,78 @@
+package version
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// slsaMaterial is a resource of SLSA provenance: a configSource or material
+type slsaMaterial struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Provenance returns the source of the build in the SLSA v0.2 provenance
+// shape: invocation.configSource and materials, to be embedded into build
+// attestations. The URI is the origin remote with the built ref, e.g.
+// git+https://github.com/org/app@refs/heads/main; the ref is the CI tag,
+// the latest tag if it points to HEAD or else the branch, and omitted for
+// detached HEADs.
+func (i *Info) Provenance() ([]byte, error) {
+	if !i.HasCommits {
+		return nil, fmt.Errorf("cannot compute provenance: %w", ErrNoCommits)
+	}
+	if i.RemoteURL == "" {
+		return nil, errors.New("cannot compute provenance without an origin remote")
+	}
+
+	uri := vcsURL(i.RemoteURL)
+	if isLocalPath(uri) {
+		uri = fileURL(uri)
+	}
+	if !strings.HasPrefix(uri, "git+") {
+		uri = "git+" + uri
+	}
+	if ref := i.builtRef(); ref != "" {
+		uri += "@" + ref
+	}
+	source := slsaMaterial{URI: uri, Digest: map[string]string{"sha1": i.GitCommit}}
+
+	var p struct {
+		Invocation struct {
+			ConfigSource slsaMaterial `json:"configSource"`
+		} `json:"invocation"`
+		Materials []slsaMaterial `json:"materials"`
+	}
+	p.Invocation.ConfigSource = source
+	p.Materials = []slsaMaterial{source}
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// builtRef returns the full name of the ref being built, or an empty string
+// for detached HEADs
+func (i *Info) builtRef() string {
+	switch {
+	case i.CI != nil && i.CI.Tag != "":
+		return "refs/tags/" + i.CI.Tag
+	case i.LatestTag != "" && i.CommitsSinceTag == 0:
+		return "refs/tags/" + i.LatestTag
+	case i.GitBranch != "" && i.GitBranch != "HEAD":
+		return "refs/heads/" + i.GitBranch
+	}
+	return ""
+}
+
+// fileURL returns the file URL of a local path, e.g. file:///srv/app,
+// file:///C:/repos/app or file://server/share/app for UNC paths
+func fileURL(path string) string {
+	path = strings.ReplaceAll(path, `\`, "/")
+	switch {
+	case strings.HasPrefix(path, "//"):
+		return "file:" + path
+	case strings.HasPrefix(path, "/"):
+		return "file://" + path
+	}
+	return "file:///" + path
+}
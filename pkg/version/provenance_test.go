@@ -0,0 +1,82 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInfoProvenance(t *testing.T) {
+	info := &Info{
+		GitCommit:  "abc123def456",
+		GitBranch:  "main",
+		HasCommits: true,
+		RemoteURL:  "https://github.com/org/app.git",
+	}
+
+	expected := `{
+  "invocation": {
+    "configSource": {
+      "uri": "git+https://github.com/org/app.git@refs/heads/main",
+      "digest": {
+        "sha1": "abc123def456"
+      }
+    }
+  },
+  "materials": [
+    {
+      "uri": "git+https://github.com/org/app.git@refs/heads/main",
+      "digest": {
+        "sha1": "abc123def456"
+      }
+    }
+  ]
+}`
+	out, err := info.Provenance()
+	if err != nil {
+		t.Fatalf("Provenance failed: %v", err)
+	}
+	if string(out) != expected {
+		t.Errorf("Provenance() = %s, want %s", out, expected)
+	}
+
+	if _, err := (&Info{GitBranch: "main", HasCommits: true}).Provenance(); err == nil {
+		t.Error("Provenance should fail without a remote")
+	}
+	if _, err := (&Info{RemoteURL: "https://github.com/org/app.git"}).Provenance(); !errors.Is(err, ErrNoCommits) {
+		t.Errorf("Provenance without commits = %v, want ErrNoCommits", err)
+	}
+}
+
+func TestInfoBuiltRef(t *testing.T) {
+	tests := []struct {
+		name string
+		info Info
+		want string
+	}{
+		{"branch", Info{GitBranch: "feature/x", LatestTag: "v1.0.0", CommitsSinceTag: 2}, "refs/heads/feature/x"},
+		{"tag at HEAD", Info{GitBranch: "main", LatestTag: "v1.0.0"}, "refs/tags/v1.0.0"},
+		{"CI tag", Info{GitBranch: "HEAD", CI: &CI{Provider: CIGitLab, Tag: "v2.0.0"}}, "refs/tags/v2.0.0"},
+		{"detached", Info{GitBranch: "HEAD"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.builtRef(); got != tt.want {
+				t.Errorf("builtRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	tests := map[string]string{
+		"/srv/git/app.git":   "file:///srv/git/app.git",
+		`C:\repos\app`:       "file:///C:/repos/app",
+		"C:/repos/app":       "file:///C:/repos/app",
+		`\\server\share\app`: "file://server/share/app",
+	}
+	for path, want := range tests {
+		if got := fileURL(path); got != want {
+			t.Errorf("fileURL(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
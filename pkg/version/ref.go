@@ -0,0 +1,116 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// unbornBranch returns the branch HEAD points to if it has no commits yet,
+// as in a freshly initialized repository
+func unbornBranch(repo *git.Repository) (string, bool) {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil || head.Type() != plumbing.SymbolicReference {
+		return "", false
+	}
+	if _, err := repo.Storer.Reference(head.Target()); !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", false
+	}
+	return head.Target().Short(), true
+}
+
+// describeRefPattern matches the output of git describe with the abbreviated
+// hash of the commit
+var describeRefPattern = regexp.MustCompile(`-g([0-9a-f]{4,40})$`)
+
+// resolveHead returns the reference to compute the version for: HEAD, or the
+// given ref if set. A ref naming a local branch resolves to that branch, HEAD
+// to HEAD, any other revision (tag, hash, ...) to a detached HEAD at its
+// commit. Like git, the output of git describe, e.g. v1.2.3-5-gabc1234, names
+// the commit of its abbreviated hash.
+func resolveHead(repo *git.Repository, ref string) (*plumbing.Reference, error) {
+	if ref == "" || ref == "HEAD" {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		return head, nil
+	}
+
+	if branch, err := repo.Reference(plumbing.NewBranchReferenceName(ref), true); err == nil {
+		return branch, nil
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if m := describeRefPattern.FindStringSubmatch(ref); err != nil && m != nil {
+		hash, err = repo.ResolveRevision(plumbing.Revision(m[1]))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref %q: %w", ref, err)
+	}
+	return plumbing.NewHashReference(plumbing.HEAD, *hash), nil
+}
+
+// headTagName returns the tag HEAD is treated as tagged with by WithHeadTag,
+// or else WithCI, unless WithRef is set
+func (o *options) headTagName() string {
+	switch {
+	case o.ref != "":
+		return ""
+	case o.headTag != "":
+		return o.headTag
+	case o.ci != nil:
+		return o.ci.Tag
+	}
+	return ""
+}
+
+// applyCIRefs fills in the branch of a detached HEAD and the tag of an
+// untagged HEAD from WithDetachedBranch and WithHeadTag, or else WithCI. A
+// local tag of that name pointing to another commit is an error, as the
+// checkout is not the tagged commit.
+func (o *options) applyCIRefs(s *repoState) error {
+	// Pinned submodule commits and the tags and commits of WithRef are
+	// versioned as on the default branch, like tag builds
+	if (o.pinned || o.ref != "") && s.branch == "HEAD" {
+		s.branch = s.defaultBranch
+	}
+	if o.ref != "" || s.unborn {
+		return nil
+	}
+	branch, tag := o.detachedBranch, o.headTagName()
+	if o.ci != nil {
+		if branch == "" {
+			branch = o.ci.Branch
+		}
+		// Tag builds are versioned as on the default branch
+		if branch == "" && o.ci.Tag != "" {
+			branch = s.defaultBranch
+		}
+	}
+	if s.branch == "HEAD" && branch != "" {
+		s.branch = branch
+	}
+
+	head := plumbing.NewHash(s.commit)
+	if tag == "" || s.describe.Tag != "" && s.describe.TagHash == head {
+		return nil
+	}
+	excludes, err := newTagExcludes(o.tagExcludes)
+	if err != nil {
+		return err
+	}
+	prefix := o.matchPrefix()
+	if !strings.HasPrefix(tag, prefix) || excludes.match(tag, prefix) {
+		return nil
+	}
+	if s.headTagCommit != "" && s.headTagCommit != s.commit {
+		return fmt.Errorf("tag %s points to %s, not to HEAD %s", tag, s.headTagCommit, s.commit)
+	}
+	s.describe = Description{Tag: tag, TagHash: head, Hash: head}
+	s.annotation, s.tagSigned = tagAnnotation{}, false
+	return nil
+}
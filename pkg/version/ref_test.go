@@ -0,0 +1,169 @@
+package version
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestGetVersionInfoWithRef(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	second := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature/x", first)); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+
+	tests := []struct {
+		ref      string
+		branch   string
+		commit   plumbing.Hash
+		expected string
+	}{
+		// Tags and commits are versioned as on the default branch
+		{"v1.0.0", "master", first, "v1.0.0"},
+		{"feature/x", "feature/x", first, "feature-x-g" + first.String()[:7]},
+		{second.String(), "master", second, "v1.0.0-1-g" + second.String()[:7]},
+		{"master~1", "master", second, "v1.0.0-1-g" + second.String()[:7]},
+		{"v1.0.0-1-g" + second.String()[:7], "master", second, "v1.0.0-1-g" + second.String()[:7]},
+		{"HEAD", "master", head, "v1.0.0-2-g" + head.String()[:7]},
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.Name()+"/"+tt.ref, func(t *testing.T) {
+				info, err := GetVersionInfo(tempDir, "", WithRef(tt.ref), WithBackend(backend))
+				if err != nil {
+					t.Fatalf("GetVersionInfo failed: %v", err)
+				}
+				if info.GitBranch != tt.branch {
+					t.Errorf("GitBranch = %q, want %q", info.GitBranch, tt.branch)
+				}
+				if info.GitCommit != tt.commit.String() {
+					t.Errorf("GitCommit = %q, want %q", info.GitCommit, tt.commit)
+				}
+				if info.Version != tt.expected {
+					t.Errorf("Version = %q, want %q", info.Version, tt.expected)
+				}
+			})
+		}
+	}
+
+	// A detached HEAD stays detached with WithRef("HEAD")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, head)); err != nil {
+		t.Fatalf("Failed to detach HEAD: %v", err)
+	}
+	info, err := GetVersionInfo(tempDir, "", WithRef("HEAD"))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Version != "v1.0.0-2-g"+head.String()[:7] {
+		t.Errorf("Version = %q with a detached HEAD, want the version of the default branch", info.Version)
+	}
+
+	if _, err := GetVersionInfo(tempDir, "", WithRef("unknown")); err == nil {
+		t.Error("GetVersionInfo should fail for an unknown ref")
+	}
+}
+
+func TestGetVersionInfoDetachedBranchAndHeadTag(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	second := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	// Detached as in a CI checkout of a commit
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, second)); err != nil {
+		t.Fatalf("Failed to detach HEAD: %v", err)
+	}
+	short := second.String()[:7]
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		branch   string
+		tag      string
+		expected string
+	}{
+		{"detached", nil, "HEAD", "v1.0.0", "HEAD-g" + short},
+		{"default branch", []Option{WithDetachedBranch("master")}, "master", "v1.0.0", "v1.0.0-1-g" + short},
+		{"feature branch", []Option{WithDetachedBranch("feature/x")}, "feature/x", "v1.0.0", "feature-x-g" + short},
+		{"tag pipeline", []Option{WithDetachedBranch("master"), WithHeadTag("v1.1.0")}, "master", "v1.1.0", "v1.1.0"},
+		{"tag outside prefix", []Option{WithDetachedBranch("master"), WithHeadTag("nightly"), WithTagPrefix("v")}, "master", "v1.0.0", "v1.0.0-1-g" + short},
+		{"excluded tag", []Option{WithDetachedBranch("master"), WithHeadTag("v1.1.0-rc1"), WithTagExcludes("*-rc*")}, "master", "v1.0.0", "v1.0.0-1-g" + short},
+		{"ref", []Option{WithDetachedBranch("feature/x"), WithHeadTag("v1.1.0"), WithRef(first.String())}, "master", "v1.0.0", "v1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "master", tt.opts...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.GitBranch != tt.branch {
+				t.Errorf("GitBranch = %q, want %q", info.GitBranch, tt.branch)
+			}
+			if info.LatestTag != tt.tag {
+				t.Errorf("LatestTag = %q, want %q", info.LatestTag, tt.tag)
+			}
+			if info.Version != tt.expected {
+				t.Errorf("Version = %q, want %q", info.Version, tt.expected)
+			}
+		})
+	}
+
+	// A tag already pointing to HEAD takes precedence
+	if _, err := repo.CreateTag("v2.0.0", second, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	info, err := GetVersionInfo(tempDir, "master", WithDetachedBranch("master"), WithHeadTag("v1.1.0"))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Version != "v2.0.0" {
+		t.Errorf("Version = %q, want v2.0.0", info.Version)
+	}
+}
+
+func TestGetVersionInfoHeadTagMismatch(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", first, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+		Message: "Release v1.0.0",
+	}); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+
+	for _, backend := range []Backend{GoGit(), GitCLI()} {
+		t.Run(backend.Name(), func(t *testing.T) {
+			// The local tag points to the first commit, not to HEAD
+			if _, err := GetVersionInfo(tempDir, "master", WithBackend(backend), WithHeadTag("v1.0.0")); err == nil {
+				t.Error("GetVersionInfo should fail for a head tag pointing to another commit")
+			}
+			ci := &CI{Provider: CIGitLab, Tag: "v1.0.0"}
+			if _, err := GetVersionInfo(tempDir, "master", WithBackend(backend), WithCI(ci)); err == nil {
+				t.Error("GetVersionInfo should fail for a CI tag pointing to another commit")
+			}
+			if _, err := GetVersionInfo(tempDir, "master", WithBackend(backend), WithHeadTag("v1.0.0"), WithRef(first.String())); err != nil {
+				t.Errorf("GetVersionInfo with WithRef failed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package version
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// identityRemote is the remote the repository identity is read from
+const identityRemote = "origin"
+
+// originURL returns the first URL of the identity remote, or an empty string
+// if it is not configured
+func originURL(repo *git.Repository) string {
+	remote, err := repo.Remote(identityRemote)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// originURL returns the URL of the identity remote like originURL
+func (g gitCommand) originURL() string {
+	u, err := g.output("config", "--get", "remote."+identityRemote+".url")
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+// parseRemoteURL splits a remote URL into the URL without credentials and
+// the owner and name of the repository, e.g. git@github.com:org/repo.git and
+// https://token@github.com/org/repo yield org and repo. The owner contains
+// all but the last path segment, e.g. group/subgroup on GitLab. Owner and
+// name are empty for local paths and URLs with fewer than two segments.
+func parseRemoteURL(remoteURL string) (cleaned, owner, name string) {
+	cleaned = strings.TrimSpace(remoteURL)
+
+	var path string
+	switch {
+	case strings.Contains(cleaned, "://"):
+		u, err := url.Parse(cleaned)
+		if err != nil {
+			return cleaned, "", ""
+		}
+		// Drop tokens of HTTP(S) URLs; the user name of ssh URLs is kept
+		if u.User != nil && (u.Scheme == "https" || u.Scheme == "http") {
+			u.User = nil
+			cleaned = u.String()
+		}
+		if u.Host == "" {
+			return cleaned, "", ""
+		}
+		path = u.Path
+	case strings.Contains(cleaned, ":") && !strings.HasPrefix(cleaned, "/"):
+		// scp-like syntax: [user@]host:org/repo
+		_, path, _ = strings.Cut(cleaned, ":")
+	default:
+		return cleaned, "", ""
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return cleaned, "", ""
+	}
+	return cleaned, path[:i], path[i+1:]
+}
@@ -0,0 +1,74 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// DefaultRemoteHEADTimeout is the time WithRemoteHEADQuery waits for the
+// remote by default
+const DefaultRemoteHEADTimeout = 5 * time.Second
+
+// WithRemoteHEADQuery asks origin for its HEAD, like git ls-remote --symref,
+// if the default branch is not given and origin/HEAD is not set locally, as
+// after git init and git fetch. Without an answer within the timeout (default:
+// DefaultRemoteHEADTimeout) the default branch is guessed as main or master.
+// The remote authenticates like WithFetchTags.
+func WithRemoteHEADQuery(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout <= 0 {
+			timeout = DefaultRemoteHEADTimeout
+		}
+		o.remoteHEADTimeout = timeout
+	}
+}
+
+// remoteHEAD returns the branch HEAD of the remote points to
+func remoteHEAD(ctx context.Context, repo *git.Repository, remoteName string, timeout time.Duration) (string, error) {
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find remote %s: %w", remoteName, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: fetchAuth(remote), PeelingOption: git.IgnorePeeled})
+	if err != nil {
+		return "", fmt.Errorf("failed to list refs of %s: %w", remoteName, err)
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", errors.New("HEAD of " + remoteName + " is not a branch or not advertised")
+}
+
+// remoteHEAD returns the branch HEAD of the remote points to like remoteHEAD
+func (g gitCommand) remoteHEAD(remote string, timeout time.Duration) (string, error) {
+	g, err := g.withFetchAuth(remote)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(g.ctx, timeout)
+	defer cancel()
+	g.ctx = ctx
+	out, err := g.output("ls-remote", "--symref", remote, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to list refs of %s: %w", remote, err)
+	}
+	// ref: refs/heads/main	HEAD
+	for _, line := range strings.Split(out, "\n") {
+		if target, ok := strings.CutPrefix(line, "ref: refs/heads/"); ok {
+			if branch, name, ok := strings.Cut(target, "\t"); ok && name == "HEAD" {
+				return branch, nil
+			}
+		}
+	}
+	return "", errors.New("HEAD of " + remote + " is not a branch or not advertised")
+}
@@ -0,0 +1,72 @@
+package version
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestGetVersionInfoRemoteHEADQuery(t *testing.T) {
+	originDir, origin := initTestRepo(t)
+	head := commitTestFile(t, origin, originDir, "test.txt", "test", "Initial commit")
+	if err := origin.Storer.SetReference(plumbing.NewHashReference("refs/heads/develop", head)); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	if err := origin.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/develop")); err != nil {
+		t.Fatalf("Failed to set HEAD: %v", err)
+	}
+
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(cloneDir, false, &git.CloneOptions{URL: originDir})
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	err = clone.Storer.RemoveReference(plumbing.NewRemoteHEADReferenceName("origin"))
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		t.Fatalf("Failed to remove origin/HEAD: %v", err)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(cloneDir, "", WithBackend(backend))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.DefaultBranch != "main" {
+				t.Errorf("DefaultBranch = %q without the query, want the guess main", info.DefaultBranch)
+			}
+
+			info, err = GetVersionInfo(cloneDir, "", WithBackend(backend), WithRemoteHEADQuery(0))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.DefaultBranch != "develop" {
+				t.Errorf("DefaultBranch = %q, want develop", info.DefaultBranch)
+			}
+		})
+	}
+
+	// An unreachable remote falls back to the guess
+	if err := clone.DeleteRemote("origin"); err != nil {
+		t.Fatalf("Failed to delete remote: %v", err)
+	}
+	if _, err := clone.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{filepath.Join(t.TempDir(), "missing")}}); err != nil {
+		t.Fatalf("Failed to create remote: %v", err)
+	}
+	info, err := GetVersionInfo(cloneDir, "", WithRemoteHEADQuery(time.Second))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.DefaultBranch != "main" {
+		t.Errorf("DefaultBranch = %q for an unreachable remote, want main", info.DefaultBranch)
+	}
+}
@@ -0,0 +1,234 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ErrStaleTags is returned by WithRemoteTagCheck if the remote has version
+// tags the repository lacks or that moved
+var ErrStaleTags = errors.New("local tags are stale")
+
+// TagDiff compares the version tags of a repository, those matching
+// WithTagPrefix and not excluded by WithTagExcludes, with those of a remote
+type TagDiff struct {
+	Remote string `json:"remote"`
+	// RemoteOnly are the tags of the remote missing locally
+	RemoteOnly []string `json:"remoteOnly"`
+	// LocalOnly are the local tags missing on the remote, e.g. not pushed yet
+	LocalOnly []string `json:"localOnly"`
+	// Moved are the tags pointing to different objects locally and on the remote
+	Moved []string `json:"moved"`
+}
+
+// Stale reports whether the remote has tags missing locally or moved ones,
+// which may make the local version wrong. Local-only tags are not stale.
+func (d *TagDiff) Stale() bool {
+	return len(d.RemoteOnly) > 0 || len(d.Moved) > 0
+}
+
+// staleError returns an ErrStaleTags error listing the stale tags, or nil
+func (d *TagDiff) staleError() error {
+	if !d.Stale() {
+		return nil
+	}
+	var problems []string
+	if len(d.RemoteOnly) > 0 {
+		problems = append(problems, "missing "+strings.Join(d.RemoteOnly, ", "))
+	}
+	if len(d.Moved) > 0 {
+		problems = append(problems, "moved "+strings.Join(d.Moved, ", "))
+	}
+	return fmt.Errorf("%w: compared to %s, %s; fetch the tags first", ErrStaleTags, d.Remote, strings.Join(problems, "; "))
+}
+
+// WithRemoteTagCheck compares the version tags with those of the remote
+// (default: origin) before computing the version, after WithFetchTags, and
+// fails with ErrStaleTags if the local tags are stale. The remote is listed
+// like git ls-remote, with the authentication of WithFetchTags; the check
+// bypasses WithCache.
+func WithRemoteTagCheck(remote string) Option {
+	return func(o *options) {
+		if remote == "" {
+			remote = DefaultFetchRemote
+		}
+		o.tagCheckRemote = remote
+	}
+}
+
+// Tags returns the names of the version tags of the repository, those
+// matching WithTagPrefix and not excluded by WithTagExcludes, in the order of
+// tag.sort of git config like git tag: refname by default, or version:refname
+func Tags(repoPath string, opts ...Option) ([]string, error) {
+	o := newOptions(opts)
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	local, err := localTags(repo)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range local {
+		names = append(names, name)
+	}
+	tags, err := o.versionTags(names)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := ReadGitConfig(repo).Get("tag.sort"); ok {
+		sortTags(tags, key)
+	}
+	return tags, nil
+}
+
+// CompareRemoteTags lists the tags of the remote (default: origin) like git
+// ls-remote and compares the version tags with the local ones
+func CompareRemoteTags(repoPath, remote string, opts ...Option) (*TagDiff, error) {
+	return CompareRemoteTagsContext(context.Background(), repoPath, remote, opts...)
+}
+
+// CompareRemoteTagsContext is like CompareRemoteTags but aborts listing the
+// remote once the context is done
+func CompareRemoteTagsContext(ctx context.Context, repoPath, remote string, opts ...Option) (*TagDiff, error) {
+	if remote == "" {
+		remote = DefaultFetchRemote
+	}
+	o := newOptions(opts)
+	o.ctx = ctx
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return compareRemoteTags(repo, remote, o)
+}
+
+// compareRemoteTags compares the local tags with those of the remote
+func compareRemoteTags(repo *git.Repository, remoteName string, o *options) (*TagDiff, error) {
+	local, err := localTags(repo)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find remote %s: %w", remoteName, err)
+	}
+	refs, err := remote.ListContext(o.ctx, &git.ListOptions{Auth: fetchAuth(remote), PeelingOption: git.IgnorePeeled})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", remoteName, err)
+	}
+	remoteTags := make(map[string]string)
+	for _, ref := range refs {
+		if ref.Name().IsTag() && ref.Type() == plumbing.HashReference {
+			remoteTags[ref.Name().Short()] = ref.Hash().String()
+		}
+	}
+	return o.diffTags(remoteName, local, remoteTags)
+}
+
+// localTags returns the object hash of each tag of the repository by name
+func localTags(repo *git.Repository) (map[string]string, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	tags := make(map[string]string)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags[ref.Name().Short()] = ref.Hash().String()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// compareRemoteTags compares the local tags with those of the remote like
+// compareRemoteTags
+func (g gitCommand) compareRemoteTags(remote string, o *options) (*TagDiff, error) {
+	out, err := g.output("for-each-ref", "--format=%(objectname)%09%(refname:strip=2)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	local := parseTagList(out, "")
+	g, err = g.withFetchAuth(remote)
+	if err != nil {
+		return nil, err
+	}
+	out, err = g.output("ls-remote", "--tags", "--refs", remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", remote, err)
+	}
+	return o.diffTags(remote, local, parseTagList(out, "refs/tags/"))
+}
+
+// parseTagList parses lines of an object hash and a tag name separated by a
+// tab, with the given prefix removed from names
+func parseTagList(out, prefix string) map[string]string {
+	tags := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if hash, name, ok := strings.Cut(line, "\t"); ok {
+			tags[strings.TrimPrefix(name, prefix)] = hash
+		}
+	}
+	return tags
+}
+
+// diffTags compares the local and remote version tags, given as object
+// hashes by name
+func (o *options) diffTags(remote string, local, remoteTags map[string]string) (*TagDiff, error) {
+	names := make([]string, 0, len(local)+len(remoteTags))
+	for name := range local {
+		names = append(names, name)
+	}
+	for name := range remoteTags {
+		if _, ok := local[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	names, err := o.versionTags(names)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &TagDiff{Remote: remote, RemoteOnly: []string{}, LocalOnly: []string{}, Moved: []string{}}
+	for _, name := range names {
+		l, inLocal := local[name]
+		r, inRemote := remoteTags[name]
+		switch {
+		case !inLocal:
+			d.RemoteOnly = append(d.RemoteOnly, name)
+		case !inRemote:
+			d.LocalOnly = append(d.LocalOnly, name)
+		case l != r:
+			d.Moved = append(d.Moved, name)
+		}
+	}
+	o.log().Debug("compared tags with remote", "remote", remote, "remoteOnly", d.RemoteOnly, "localOnly", d.LocalOnly, "moved", d.Moved)
+	return d, nil
+}
+
+// versionTags returns the tag names matching the tag prefix and not excluded,
+// in sorted order
+func (o *options) versionTags(names []string) ([]string, error) {
+	excludes, err := newTagExcludes(o.tagExcludes)
+	if err != nil {
+		return nil, err
+	}
+	prefix := o.matchPrefix()
+	var tags []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && !excludes.match(name, prefix) {
+			tags = append(tags, name)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
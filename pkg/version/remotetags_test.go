@@ -0,0 +1,81 @@
+package version
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestCompareRemoteTags(t *testing.T) {
+	originDir, origin := initTestRepo(t)
+	first := commitTestFile(t, origin, originDir, "test.txt", "1", "First")
+	second := commitTestFile(t, origin, originDir, "test.txt", "2", "Second")
+	createTag := func(repo *git.Repository, name string, hash plumbing.Hash) {
+		t.Helper()
+		if _, err := repo.CreateTag(name, hash, nil); err != nil {
+			t.Fatalf("Failed to create tag %s: %v", name, err)
+		}
+	}
+	createTag(origin, "v1.0.0", first)
+	createTag(origin, "v1.1.0", second)
+
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(cloneDir, false, &git.CloneOptions{URL: originDir})
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	createTag(clone, "v1.3.0", second)
+	createTag(origin, "v1.2.0", second)
+	createTag(origin, "other", second)
+	if err := origin.DeleteTag("v1.1.0"); err != nil {
+		t.Fatalf("Failed to delete tag: %v", err)
+	}
+	createTag(origin, "v1.1.0", first)
+
+	tags, err := Tags(cloneDir, WithTagPrefix("v"))
+	if err != nil {
+		t.Fatalf("Tags failed: %v", err)
+	}
+	if want := []string{"v1.0.0", "v1.1.0", "v1.3.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Tags() = %v, want %v", tags, want)
+	}
+
+	diff, err := CompareRemoteTags(cloneDir, "", WithTagPrefix("v"))
+	if err != nil {
+		t.Fatalf("CompareRemoteTags failed: %v", err)
+	}
+	want := &TagDiff{Remote: "origin", RemoteOnly: []string{"v1.2.0"}, LocalOnly: []string{"v1.3.0"}, Moved: []string{"v1.1.0"}}
+	if !reflect.DeepEqual(diff, want) || !diff.Stale() {
+		t.Errorf("CompareRemoteTags() = %+v, want %+v", diff, want)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			_, err := GetVersionInfo(cloneDir, "", WithBackend(backend), WithTagPrefix("v"), WithRemoteTagCheck(""))
+			if !errors.Is(err, ErrStaleTags) {
+				t.Fatalf("GetVersionInfo() error = %v, want ErrStaleTags", err)
+			}
+			// Local-only tags are not stale
+			if _, err := GetVersionInfo(cloneDir, "", WithBackend(backend), WithTagPrefix("v"), WithTagExcludes("v1.1.0", "v1.2.0"), WithRemoteTagCheck("")); err != nil {
+				t.Errorf("GetVersionInfo failed with only local-only tags: %v", err)
+			}
+		})
+	}
+
+	// Fetched tags are up to date
+	info, err := GetVersionInfo(cloneDir, "", WithTagPrefix("v"), WithFetchTags(""), WithRemoteTagCheck(""))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed after fetching tags: %v", err)
+	}
+	if info.Version != "v1.3.0" {
+		t.Errorf("Version = %q, want v1.3.0", info.Version)
+	}
+}
@@ -0,0 +1,95 @@
+package version
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// cycloneDXComponent is the component of CycloneDX BOM metadata
+type cycloneDXComponent struct {
+	BOMRef             string              `json:"bom-ref,omitempty"`
+	Type               string              `json:"type"`
+	Group              string              `json:"group,omitempty"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version"`
+	ExternalReferences []cycloneDXExtRef   `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXExtRef struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CycloneDX returns the version info as a CycloneDX BOM fragment with only
+// metadata.component set, to be merged into the BOM of an SBOM generator. The
+// component is the application named after the repository, or name without an
+// origin remote; the remote is its vcs reference and the Git fields are
+// gitversion:* properties.
+func (i *Info) CycloneDX(name string) ([]byte, error) {
+	c := cycloneDXComponent{
+		Type:    "application",
+		Group:   i.RepoOwner,
+		Name:    name,
+		Version: i.Version,
+	}
+	if i.RepoName != "" {
+		c.Name = i.RepoName
+	}
+	c.BOMRef = c.Name + "@" + c.Version
+	if i.RemoteURL != "" {
+		ref := cycloneDXExtRef{Type: "vcs", URL: vcsURL(i.RemoteURL)}
+		if i.GitCommit != "" {
+			ref.Comment = "commit " + i.GitCommit
+		}
+		c.ExternalReferences = []cycloneDXExtRef{ref}
+	}
+	for _, p := range []cycloneDXProperty{
+		{"gitversion:commit", i.GitCommit},
+		{"gitversion:branch", i.GitBranch},
+		{"gitversion:describe", i.GitDescribe},
+		{"gitversion:latestTag", i.LatestTag},
+		{"gitversion:commitTime", i.CommitTime},
+		{"gitversion:dirty", strconv.FormatBool(i.IsDirty)},
+	} {
+		if p.Value != "" {
+			c.Properties = append(c.Properties, p)
+		}
+	}
+
+	var fragment struct {
+		Metadata struct {
+			Component cycloneDXComponent `json:"component"`
+		} `json:"metadata"`
+	}
+	fragment.Metadata.Component = c
+	return json.MarshalIndent(fragment, "", "  ")
+}
+
+// vcsURL returns the remote URL in URL form: scp-like addresses become ssh
+// URLs, e.g. git@github.com:org/repo.git is ssh://git@github.com/org/repo.git
+func vcsURL(remote string) string {
+	if strings.Contains(remote, "://") || isLocalPath(remote) || !strings.Contains(remote, ":") {
+		return remote
+	}
+	host, path, _ := strings.Cut(remote, ":")
+	return "ssh://" + host + "/" + strings.TrimPrefix(path, "/")
+}
+
+// isLocalPath reports whether the remote is a path on disk rather than a URL
+// or scp-like address: absolute, or starting with a drive letter like git
+// accepts on Windows, e.g. C:\repos\app or C:/repos/app
+func isLocalPath(remote string) bool {
+	if strings.HasPrefix(remote, "/") || strings.HasPrefix(remote, `\\`) {
+		return true
+	}
+	return len(remote) >= 3 && remote[1] == ':' && (remote[2] == '/' || remote[2] == '\\') &&
+		('a' <= remote[0] && remote[0] <= 'z' || 'A' <= remote[0] && remote[0] <= 'Z')
+}
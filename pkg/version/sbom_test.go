@@ -0,0 +1,107 @@
+package version
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInfoCycloneDX(t *testing.T) {
+	info := &Info{
+		Version:     "v1.2.3",
+		GitCommit:   "abc123def456",
+		GitBranch:   "main",
+		GitDescribe: "v1.2.3",
+		LatestTag:   "v1.2.3",
+		CommitTime:  "2025-01-01T00:00:00Z",
+		RemoteURL:   "git@github.com:org/app.git",
+		RepoOwner:   "org",
+		RepoName:    "app",
+	}
+
+	expected := `{
+  "metadata": {
+    "component": {
+      "bom-ref": "app@v1.2.3",
+      "type": "application",
+      "group": "org",
+      "name": "app",
+      "version": "v1.2.3",
+      "externalReferences": [
+        {
+          "type": "vcs",
+          "url": "ssh://git@github.com/org/app.git",
+          "comment": "commit abc123def456"
+        }
+      ],
+      "properties": [
+        {
+          "name": "gitversion:commit",
+          "value": "abc123def456"
+        },
+        {
+          "name": "gitversion:branch",
+          "value": "main"
+        },
+        {
+          "name": "gitversion:describe",
+          "value": "v1.2.3"
+        },
+        {
+          "name": "gitversion:latestTag",
+          "value": "v1.2.3"
+        },
+        {
+          "name": "gitversion:commitTime",
+          "value": "2025-01-01T00:00:00Z"
+        },
+        {
+          "name": "gitversion:dirty",
+          "value": "false"
+        }
+      ]
+    }
+  }
+}`
+	out, err := info.CycloneDX("fallback")
+	if err != nil {
+		t.Fatalf("CycloneDX failed: %v", err)
+	}
+	if string(out) != expected {
+		t.Errorf("CycloneDX() = %s, want %s", out, expected)
+	}
+
+	// Without a remote the component is named after the given name
+	local := &Info{Version: "main-gabc123d", GitCommit: "abc123def456"}
+	out, err = local.CycloneDX("checkout")
+	if err != nil {
+		t.Fatalf("CycloneDX failed: %v", err)
+	}
+	var fragment struct {
+		Metadata struct {
+			Component cycloneDXComponent `json:"component"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(out, &fragment); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	c := fragment.Metadata.Component
+	if c.Name != "checkout" || c.BOMRef != "checkout@main-gabc123d" || c.ExternalReferences != nil {
+		t.Errorf("CycloneDX() without remote = %+v", c)
+	}
+}
+
+func TestVCSURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/org/app.git": "https://github.com/org/app.git",
+		"git@github.com:org/app.git":     "ssh://git@github.com/org/app.git",
+		"github.com:/org/app":            "ssh://github.com/org/app",
+		"/srv/git/app.git":               "/srv/git/app.git",
+		`C:\repos\app`:                   `C:\repos\app`,
+		"C:/repos/app":                   "C:/repos/app",
+	}
+	for remote, want := range tests {
+		if got := vcsURL(remote); got != want {
+			t.Errorf("vcsURL(%q) = %q, want %q", remote, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const (
+	// deepenStart is the depth of the first fetch deepening a shallow clone;
+	// it doubles with every further fetch up to deepenMax
+	deepenStart = 64
+	deepenMax   = 1 << 20
+)
+
+// branchRefSpec fetches all branches of a remote
+func branchRefSpec(remote string) config.RefSpec {
+	return config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", remote))
+}
+
+// shallowCommits returns the boundary commits of a shallow clone, or nil
+func shallowCommits(repo *git.Repository) []plumbing.Hash {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil
+	}
+	return shallow
+}
+
+// deepen fetches the branches of the remote with the given depth, and the
+// tags pointing into the fetched history, and reports whether the history
+// grew. The boundary commits whose parents are now all present are removed
+// from .git/shallow, which go-git does not do itself.
+func deepen(ctx context.Context, repo *git.Repository, remoteName string, depth int) (bool, error) {
+	before := shallowCommits(repo)
+
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return false, fmt.Errorf("failed to find remote %s: %w", remoteName, err)
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{branchRefSpec(remoteName)},
+		Depth:    depth,
+		Tags:     git.TagFollowing,
+		Auth:     fetchAuth(remote),
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, fmt.Errorf("failed to deepen history from %s: %w", remoteName, err)
+	}
+
+	var after []plumbing.Hash
+	for _, hash := range shallowCommits(repo) {
+		if !hasParents(repo, hash) {
+			after = append(after, hash)
+		}
+	}
+	if err := repo.Storer.SetShallow(after); err != nil {
+		return false, fmt.Errorf("failed to update shallow commits: %w", err)
+	}
+	byHash := func(a, b plumbing.Hash) int { return bytes.Compare(a[:], b[:]) }
+	slices.SortFunc(before, byHash)
+	slices.SortFunc(after, byHash)
+	return !slices.Equal(before, after), nil
+}
+
+// hasParents reports whether all parents of the commit are in the repository
+func hasParents(repo *git.Repository, hash plumbing.Hash) bool {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return false
+	}
+	for _, parent := range commit.ParentHashes {
+		if repo.Storer.HasEncodedObject(parent) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isShallow reports whether the repository is a shallow clone
+func (g gitCommand) isShallow() (bool, error) {
+	out, err := g.output("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return false, err
+	}
+	return out == "true", nil
+}
+
+// deepen fetches depth more commits of history like deepen, reporting whether
+// the history grew
+func (g gitCommand) deepen(remote string, depth int) (bool, error) {
+	path, err := g.output("rev-parse", "--path-format=absolute", "--git-path", "shallow")
+	if err != nil {
+		return false, err
+	}
+	// The shallow file lists the boundary commits and is removed once the
+	// history is complete
+	before, _ := os.ReadFile(path)
+	fetch, err := g.withFetchAuth(remote)
+	if err != nil {
+		return false, err
+	}
+	if err := fetch.run("fetch", "--quiet", "--deepen="+strconv.Itoa(depth), remote); err != nil {
+		return false, fmt.Errorf("failed to deepen history from %s: %w", remote, err)
+	}
+	after, _ := os.ReadFile(path)
+	return !bytes.Equal(before, after), nil
+}
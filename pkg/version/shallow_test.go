@@ -0,0 +1,64 @@
+package version
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestGetVersionInfoShallow(t *testing.T) {
+	originDir, origin := initTestRepo(t)
+	for i := 1; i <= 5; i++ {
+		hash := commitTestFile(t, origin, originDir, "test.txt", fmt.Sprint(i), fmt.Sprintf("Commit %d", i))
+		if i == 2 {
+			if _, err := origin.CreateTag("v1.0.0", hash, nil); err != nil {
+				t.Fatalf("Failed to create tag: %v", err)
+			}
+		}
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			cloneDir := filepath.Join(t.TempDir(), "clone")
+			if _, err := git.PlainClone(cloneDir, false, &git.CloneOptions{URL: originDir, Depth: 2}); err != nil {
+				t.Fatalf("Failed to clone: %v", err)
+			}
+
+			info, err := GetVersionInfo(cloneDir, "", WithBackend(backend))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if !info.IsShallow {
+				t.Error("IsShallow should be true for a shallow clone")
+			}
+			if info.LatestTag != "" {
+				t.Fatalf("LatestTag = %q, the tag should be beyond the shallow history", info.LatestTag)
+			}
+
+			info, err = GetVersionInfo(cloneDir, "", WithBackend(backend), WithDeepen(true))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.LatestTag != "v1.0.0" || info.CommitsSinceTag != 3 {
+				t.Errorf("LatestTag = %q, CommitsSinceTag = %d, want v1.0.0 and 3", info.LatestTag, info.CommitsSinceTag)
+			}
+			if info.IsShallow {
+				t.Error("IsShallow should be false once the history is complete")
+			}
+		})
+	}
+
+	info, err := GetVersionInfo(originDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.IsShallow {
+		t.Error("IsShallow should be false for a full repository")
+	}
+}
@@ -0,0 +1,281 @@
+package version
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSigNamespace is the namespace git signs commits and tags in
+const sshSigNamespace = "git"
+
+// signers are the keys signatures are verified against: an armored OpenPGP
+// keyring or the keys of an SSH allowed_signers file
+type signers struct {
+	keyring openpgp.EntityList
+	ssh     []ssh.PublicKey
+}
+
+// loadSigners reads an armored OpenPGP public keyring or an SSH
+// allowed_signers file, as used by git's gpg.ssh.allowedSignersFile
+func loadSigners(path string) (*signers, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signers: %w", err)
+	}
+	if bytes.Contains(content, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keyring %s: %w", path, err)
+		}
+		return &signers{keyring: keyring}, nil
+	}
+
+	s := &signers{}
+	for n, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := allowedSigner(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed signer in %s line %d: %w", path, n+1, err)
+		}
+		if key != nil {
+			s.ssh = append(s.ssh, key)
+		}
+	}
+	if len(s.ssh) == 0 {
+		return nil, fmt.Errorf("no keys in %s", path)
+	}
+	return s, nil
+}
+
+// allowedSigner parses a line of an allowed_signers file, "principals
+// [options] keytype key", and returns its key, or nil if its namespaces
+// option excludes git
+func allowedSigner(line string) (ssh.PublicKey, error) {
+	// Skip the principals, which may be quoted
+	var rest string
+	if strings.HasPrefix(line, `"`) {
+		_, rest, _ = strings.Cut(line[1:], `"`)
+	} else {
+		_, rest, _ = strings.Cut(line, " ")
+	}
+
+	key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		if namespaces, ok := strings.CutPrefix(option, "namespaces="); ok {
+			if !strings.Contains(","+strings.Trim(namespaces, `"`)+",", ","+sshSigNamespace+",") {
+				return nil, nil
+			}
+		}
+	}
+	return key, nil
+}
+
+// verify reports whether the signature over the payload was made by one of
+// the signers; OpenPGP and SSH signatures are supported
+func (s *signers) verify(payload []byte, signature string) bool {
+	switch {
+	case strings.HasPrefix(signature, "-----BEGIN PGP SIGNATURE-----"):
+		_, err := openpgp.CheckArmoredDetachedSignature(s.keyring, bytes.NewReader(payload), strings.NewReader(signature), nil)
+		return err == nil
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		return s.verifySSH(payload, signature) == nil
+	}
+	return false
+}
+
+// sshSignature is an SSH signature after its magic preamble, as specified by
+// PROTOCOL.sshsig of OpenSSH
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// verifySSH verifies an armored SSH signature in the git namespace
+func (s *signers) verifySSH(payload []byte, armored string) error {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return errors.New("invalid SSH signature armor")
+	}
+	blob, ok := bytes.CutPrefix(block.Bytes, []byte("SSHSIG"))
+	if !ok {
+		return errors.New("invalid SSH signature preamble")
+	}
+	var sig sshSignature
+	if err := ssh.Unmarshal(blob, &sig); err != nil {
+		return err
+	}
+	if sig.Version != 1 || sig.Namespace != sshSigNamespace {
+		return fmt.Errorf("unsupported SSH signature version %d in namespace %q", sig.Version, sig.Namespace)
+	}
+
+	key, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return err
+	}
+	allowed := false
+	for _, k := range s.ssh {
+		allowed = allowed || bytes.Equal(k.Marshal(), key.Marshal())
+	}
+	if !allowed {
+		return errors.New("SSH signature key is not an allowed signer")
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported SSH signature hash %q", sig.HashAlgorithm)
+	}
+	h.Write(payload)
+
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sig.Namespace, sig.Reserved, sig.HashAlgorithm, h.Sum(nil)})...)
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		return err
+	}
+	return key.Verify(signed, &signature)
+}
+
+// verifyCommit reports whether the commit is signed by one of the signers
+func (s *signers) verifyCommit(commit *object.Commit) (bool, error) {
+	if commit.PGPSignature == "" {
+		return false, nil
+	}
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return false, err
+	}
+	return s.verify(objectContent(encoded), commit.PGPSignature), nil
+}
+
+// verifyTag reports whether the annotated tag is signed by one of the signers
+func (s *signers) verifyTag(tag *object.Tag) (bool, error) {
+	if tag.PGPSignature == "" {
+		return false, nil
+	}
+	encoded := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(encoded); err != nil {
+		return false, err
+	}
+	return s.verify(objectContent(encoded), tag.PGPSignature), nil
+}
+
+// objectContent returns the content of an in-memory object
+func objectContent(o *plumbing.MemoryObject) []byte {
+	r, _ := o.Reader()
+	defer r.Close()
+	var b bytes.Buffer
+	b.ReadFrom(r)
+	return b.Bytes()
+}
+
+// verifySignatures reports whether the commit and the annotated tag, if
+// any, are signed by one of the signers
+func verifySignatures(repo *git.Repository, s *signers, commit *object.Commit, tagName string) (bool, bool, error) {
+	commitSigned, err := s.verifyCommit(commit)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to verify commit signature: %w", err)
+	}
+	if tagName == "" {
+		return commitSigned, false, nil
+	}
+	ref, err := repo.Tag(tagName)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to find tag %s: %w", tagName, err)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return commitSigned, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("failed to read tag %s: %w", tagName, err)
+	}
+	tagSigned, err := s.verifyTag(tag)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to verify tag signature: %w", err)
+	}
+	return commitSigned, tagSigned, nil
+}
+
+// verifySignatures verifies the commit and the tag like verifySignatures,
+// with the objects read by git cat-file
+func (g gitCommand) verifySignatures(s *signers, commitHash string, tagName string) (bool, bool, error) {
+	commit := &object.Commit{}
+	if err := g.decodeObject(commitHash, plumbing.CommitObject, commit); err != nil {
+		return false, false, err
+	}
+	commitSigned, err := s.verifyCommit(commit)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to verify commit signature: %w", err)
+	}
+	if tagName == "" {
+		return commitSigned, false, nil
+	}
+	typ, err := g.output("cat-file", "-t", "refs/tags/"+tagName)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to find tag %s: %w", tagName, err)
+	}
+	if typ != "tag" {
+		return commitSigned, false, nil
+	}
+	tag := &object.Tag{}
+	if err := g.decodeObject("refs/tags/"+tagName, plumbing.TagObject, tag); err != nil {
+		return false, false, err
+	}
+	tagSigned, err := s.verifyTag(tag)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to verify tag signature: %w", err)
+	}
+	return commitSigned, tagSigned, nil
+}
+
+// decodeObject reads the object by git cat-file and decodes it into a
+// go-git commit or tag
+func (g gitCommand) decodeObject(rev string, typ plumbing.ObjectType, into interface {
+	Decode(plumbing.EncodedObject) error
+}) error {
+	content, err := g.raw("cat-file", typ.String(), rev)
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s: %w", typ, rev, err)
+	}
+	o := &plumbing.MemoryObject{}
+	o.SetType(typ)
+	if _, err := o.Write([]byte(content)); err != nil {
+		return err
+	}
+	if err := into.Decode(o); err != nil {
+		return fmt.Errorf("failed to decode %s %s: %w", typ, rev, err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package version
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSigner signs commits with an SSH key in the format of ssh-keygen -Y sign
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+func (s sshSigner) Sign(message io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	h := sha512.Sum512(content)
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSigNamespace, "", "sha512", h[:]})...)
+	sig, err := s.signer.Sign(rand.Reader, signed)
+	if err != nil {
+		return nil, err
+	}
+	blob := append([]byte("SSHSIG"), ssh.Marshal(sshSignature{
+		Version:       1,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     sshSigNamespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+	return pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob}), nil
+}
+
+// newSSHSigner returns a signer with a new ed25519 key
+func newSSHSigner(t *testing.T) sshSigner {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return sshSigner{signer: signer}
+}
+
+// writeKeyring writes the armored public key of the entity to a file
+func writeKeyring(t *testing.T, entity *openpgp.Entity) string {
+	t.Helper()
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("Failed to armor key: %v", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("Failed to serialize key: %v", err)
+	}
+	w.Close()
+	path := filepath.Join(t.TempDir(), "keyring.asc")
+	writeFile(t, path, b.String())
+	return path
+}
+
+// signedCommit commits a file signed with the options
+func signedCommit(t *testing.T, repo *git.Repository, dir, content string, opts *git.CommitOptions) plumbing.Hash {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, "test.txt"), content)
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add("test.txt"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	opts.Author = testSignature()
+	hash, err := w.Commit("Signed commit", opts)
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash
+}
+
+// signatureBackends returns the backends to test signature verification with
+func signatureBackends() []Backend {
+	if hasGit() {
+		return []Backend{GoGit(), GitCLI()}
+	}
+	return []Backend{GoGit()}
+}
+
+func TestVerifySignaturesOpenPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("Test User", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	other, err := openpgp.NewEntity("Other User", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	keyring, otherKeyring := writeKeyring(t, entity), writeKeyring(t, other)
+
+	tempDir, repo := initTestRepo(t)
+	head := signedCommit(t, repo, tempDir, "v1", &git.CommitOptions{SignKey: entity})
+	_, err = repo.CreateTag("v1.0.0", head, &git.CreateTagOptions{Tagger: testSignature(), Message: "Release v1.0.0", SignKey: entity})
+	if err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	for _, backend := range signatureBackends() {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithVerifySignatures(keyring))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if !info.CommitSigned || !info.TagSigned {
+				t.Errorf("CommitSigned, TagSigned = %v, %v, want true, true", info.CommitSigned, info.TagSigned)
+			}
+			if info.TagMessage != "Release v1.0.0" {
+				t.Errorf("TagMessage = %q, want the message without signature", info.TagMessage)
+			}
+
+			info, err = GetVersionInfo(tempDir, "", WithBackend(backend), WithVerifySignatures(otherKeyring))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.CommitSigned || info.TagSigned {
+				t.Errorf("CommitSigned, TagSigned = %v, %v with another key, want false, false", info.CommitSigned, info.TagSigned)
+			}
+		})
+	}
+}
+
+func TestVerifySignaturesSSH(t *testing.T) {
+	signer := newSSHSigner(t)
+	allowedSigners := filepath.Join(t.TempDir(), "allowed_signers")
+	writeFile(t, allowedSigners, "# trusted keys\ntest@example.com namespaces=\"git,file\" "+string(ssh.MarshalAuthorizedKey(signer.signer.PublicKey())))
+
+	tempDir, repo := initTestRepo(t)
+	head := signedCommit(t, repo, tempDir, "v1", &git.CommitOptions{Signer: signer})
+	if _, err := repo.CreateTag("v1.0.0", head, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	for _, backend := range signatureBackends() {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithVerifySignatures(allowedSigners))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			// The lightweight tag cannot be signed
+			if !info.CommitSigned || info.TagSigned {
+				t.Errorf("CommitSigned, TagSigned = %v, %v, want true, false", info.CommitSigned, info.TagSigned)
+			}
+		})
+	}
+
+	// An unsigned commit and a key of another signer are not verified
+	unsigned := signedCommit(t, repo, tempDir, "v2", &git.CommitOptions{})
+	other := signedCommit(t, repo, tempDir, "v3", &git.CommitOptions{Signer: newSSHSigner(t)})
+	for _, ref := range []plumbing.Hash{unsigned, other} {
+		info, err := GetVersionInfo(tempDir, "", WithRef(ref.String()), WithVerifySignatures(allowedSigners))
+		if err != nil {
+			t.Fatalf("GetVersionInfo failed: %v", err)
+		}
+		if info.CommitSigned {
+			t.Errorf("CommitSigned = true for %s, want false", ref)
+		}
+	}
+}
+
+func TestLoadSigners(t *testing.T) {
+	dir := t.TempDir()
+	key := string(ssh.MarshalAuthorizedKey(newSSHSigner(t).signer.PublicKey()))
+
+	if _, err := loadSigners(filepath.Join(dir, "missing")); err == nil {
+		t.Error("loadSigners should fail for a missing file")
+	}
+
+	invalid := filepath.Join(dir, "invalid")
+	writeFile(t, invalid, "test@example.com ssh-ed25519 not-base64\n")
+	if _, err := loadSigners(invalid); err == nil {
+		t.Error("loadSigners should fail for an invalid key")
+	}
+
+	// Keys restricted to other namespaces are ignored
+	fileOnly := filepath.Join(dir, "file-only")
+	writeFile(t, fileOnly, `"Test User" namespaces="file" `+key)
+	if _, err := loadSigners(fileOnly); err == nil {
+		t.Error("loadSigners should fail without keys for git")
+	}
+
+	quoted := filepath.Join(dir, "quoted")
+	writeFile(t, quoted, `"Test User,test@example.com" `+key)
+	s, err := loadSigners(quoted)
+	if err != nil {
+		t.Fatalf("loadSigners failed: %v", err)
+	}
+	if len(s.ssh) != 1 {
+		t.Errorf("loadSigners() = %d keys, want 1", len(s.ssh))
+	}
+
+	if err := os.WriteFile(quoted, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := loadSigners(quoted); err == nil {
+		t.Error("loadSigners should fail for an empty file")
+	}
+}
@@ -0,0 +1,47 @@
+package version
+
+import (
+	"fmt"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// snapshotTimeFormat is the timestamp of snapshot versions, like the
+// timestamps Maven gives deployed snapshots
+const snapshotTimeFormat = "20060102150405"
+
+// Snapshot returns the snapshot strategy for Maven and Gradle: untagged
+// commits are snapshots of the next patch version, stamped with the UTC
+// commit time and the abbreviated hash, e.g.
+// 1.2.4-SNAPSHOT.20240102150405.gabc123d after tag v1.2.3. Other branches
+// put the branch before SNAPSHOT, e.g. 1.2.4-feature-x-SNAPSHOT.20240102150405.gabc123d.
+// Tagged commits use the tag. Like Maven versions, snapshots have no leading v.
+// After a latest tag that is no SemVer, <branch-slug>-g<hash> is used like by
+// GitHubFlow; branches whose slug is empty come first as "branch".
+func Snapshot() Strategy {
+	return snapshot{}
+}
+
+type snapshot struct{}
+
+func (snapshot) Name() string { return "snapshot" }
+
+func (snapshot) Version(info *Info) (string, error) {
+	switch {
+	case !info.HasCommits:
+		return info.slugVersion(), nil
+	case info.LatestTag != "" && info.CommitsSinceTag == 0:
+		return info.describeVersion(), nil
+	}
+
+	current, _, err := info.latestSemVer()
+	if err != nil {
+		return info.slugVersion(), nil
+	}
+	next := current.Bump(semver.Patch)
+	next.Prerelease = fmt.Sprintf("SNAPSHOT.%s.g%s", info.commitTime.UTC().Format(snapshotTimeFormat), info.GitCommitShort)
+	if !info.IsDefaultBranch() {
+		next.Prerelease = info.slugLabel() + "-" + next.Prerelease
+	}
+	return info.formatSemVer(next, false), nil
+}
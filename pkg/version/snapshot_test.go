@@ -0,0 +1,76 @@
+package version
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotStrategy(t *testing.T) {
+	commitTime := time.Date(2024, time.January, 2, 16, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name     string
+		info     Info
+		expected string
+	}{
+		{
+			name:     "no tags",
+			info:     Info{GitBranch: "main", CommitsSinceTag: 3},
+			expected: "0.0.1-SNAPSHOT.20240102150405.gabc123d",
+		},
+		{
+			name:     "at tag",
+			info:     Info{GitBranch: "main", GitDescribe: "v1.2.3", LatestTag: "v1.2.3"},
+			expected: "v1.2.3",
+		},
+		{
+			name:     "after tag",
+			info:     Info{GitBranch: "main", LatestTag: "v1.2.3", CommitsSinceTag: 2},
+			expected: "1.2.4-SNAPSHOT.20240102150405.gabc123d",
+		},
+		{
+			name:     "after prerelease tag",
+			info:     Info{GitBranch: "main", LatestTag: "v1.3.0-rc.1", CommitsSinceTag: 2},
+			expected: "1.3.0-SNAPSHOT.20240102150405.gabc123d",
+		},
+		{
+			name:     "feature branch",
+			info:     Info{GitBranch: "feature/x", LatestTag: "v1.2.3", CommitsSinceTag: 2},
+			expected: "1.2.4-feature-x-SNAPSHOT.20240102150405.gabc123d",
+		},
+		{
+			name:     "tag prefix",
+			info:     Info{GitBranch: "main", LatestTag: "release/1.2.3", CommitsSinceTag: 1, tagPrefix: "release/"},
+			expected: "release/1.2.4-SNAPSHOT.20240102150405.gabc123d",
+		},
+		{
+			name:     "after non-SemVer tag",
+			info:     Info{GitBranch: "main", LatestTag: "build-42", CommitsSinceTag: 2},
+			expected: "main-gabc123d",
+		},
+		{
+			name:     "empty slug",
+			info:     Info{GitBranch: "äöü", LatestTag: "v1.2.3", CommitsSinceTag: 2},
+			expected: "1.2.4-branch-SNAPSHOT.20240102150405.gabc123d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			info.DefaultBranch = "main"
+			info.GitBranchSlug = createBranchSlug(info.GitBranch)
+			info.GitCommitShort = "abc123d"
+			info.HasCommits = true
+			info.commitTime = commitTime
+
+			result, err := Snapshot().Version(&info)
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Version() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stamp returns the version info as workspace status lines (KEY value) for
+// Bazel's --workspace_status_command. Keys with the STABLE_ prefix go to the
+// stable status and rebuild stamped targets when they change; BUILD_TIMESTAMP
+// is the build time in seconds since the Unix epoch.
+func (i *Info) Stamp() (string, error) {
+	buildTime, err := time.Parse(time.RFC3339, i.BuildTime)
+	if err != nil {
+		return "", fmt.Errorf("invalid build time %q: %w", i.BuildTime, err)
+	}
+	keys := []Field{
+		{"STABLE_VERSION", i.Version},
+		{"STABLE_GIT_COMMIT", i.GitCommit},
+		{"STABLE_GIT_COMMIT_SHORT", i.GitCommitShort},
+		{"STABLE_GIT_BRANCH", i.GitBranch},
+		{"STABLE_GIT_DESCRIBE", i.GitDescribe},
+		{"STABLE_GIT_LATEST_TAG", i.LatestTag},
+		{"STABLE_GIT_DIRTY", strconv.FormatBool(i.IsDirty)},
+		{"BUILD_TIMESTAMP", strconv.FormatInt(buildTime.Unix(), 10)},
+	}
+
+	var sb strings.Builder
+	for _, k := range keys {
+		// A status line ends at the newline, the value must not span lines
+		fmt.Fprintf(&sb, "%s %s\n", k.Name, strings.ReplaceAll(k.Value, "\n", " "))
+	}
+	return sb.String(), nil
+}
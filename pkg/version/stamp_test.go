@@ -0,0 +1,37 @@
+package version
+
+import "testing"
+
+func TestInfoStamp(t *testing.T) {
+	info := &Info{
+		Version:        "v1.0.0-5-gabc123d",
+		GitCommit:      "abc123def456",
+		GitCommitShort: "abc123d",
+		GitBranch:      "main",
+		GitDescribe:    "v1.0.0-5-gabc123d",
+		LatestTag:      "v1.0.0",
+		BuildTime:      "2025-01-01T00:00:00Z",
+	}
+
+	expected := `STABLE_VERSION v1.0.0-5-gabc123d
+STABLE_GIT_COMMIT abc123def456
+STABLE_GIT_COMMIT_SHORT abc123d
+STABLE_GIT_BRANCH main
+STABLE_GIT_DESCRIBE v1.0.0-5-gabc123d
+STABLE_GIT_LATEST_TAG v1.0.0
+STABLE_GIT_DIRTY false
+BUILD_TIMESTAMP 1735689600
+`
+	out, err := info.Stamp()
+	if err != nil {
+		t.Fatalf("Stamp failed: %v", err)
+	}
+	if out != expected {
+		t.Errorf("Stamp() = %q, want %q", out, expected)
+	}
+
+	info.BuildTime = ""
+	if _, err := info.Stamp(); err == nil {
+		t.Error("Stamp should fail without a build time")
+	}
+}
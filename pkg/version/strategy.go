@@ -0,0 +1,237 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// Strategy computes the version string from the collected repository state.
+// The dirty suffix is appended afterwards and must not be added by strategies.
+type Strategy interface {
+	// Name returns the name the strategy is selected by, e.g. "gitflow"
+	Name() string
+	// Version returns the version for the given info
+	Version(info *Info) (string, error)
+}
+
+// strategies contains the built-in strategies by name
+var strategies = map[string]Strategy{
+	"githubflow": GitHubFlow(),
+	"gitflow":    GitFlow(),
+	"trunk":      Trunk(),
+	"height":     Height(),
+}
+
+// StrategyByName returns the built-in strategy with the given name
+func StrategyByName(name string) (Strategy, error) {
+	s, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q: must be one of %s", name, strings.Join(StrategyNames(), ", "))
+	}
+	return s, nil
+}
+
+// StrategyNames returns the sorted names of the built-in strategies
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GitHubFlow returns the default strategy: the default branch uses git describe
+// (or <branch-slug>-g<hash> without tags), all other branches <branch-slug>-g<hash>
+func GitHubFlow() Strategy {
+	return githubFlow{}
+}
+
+type githubFlow struct{}
+
+func (githubFlow) Name() string { return "githubflow" }
+
+func (githubFlow) Version(info *Info) (string, error) {
+	if info.IsDefaultBranch() && info.GitDescribe != "" {
+		return info.describeVersion(), nil
+	}
+	return info.slugVersion(), nil
+}
+
+// Trunk returns the trunk-based strategy: the default branch uses git describe,
+// short-lived branches produce prereleases of the next patch version named
+// after the branch, e.g. v1.2.4-feature-x.3, or after "branch" if the branch
+// slug is empty
+func Trunk() Strategy {
+	return trunk{}
+}
+
+type trunk struct{}
+
+func (trunk) Name() string { return "trunk" }
+
+func (trunk) Version(info *Info) (string, error) {
+	if info.IsDefaultBranch() {
+		if info.GitDescribe != "" {
+			return info.describeVersion(), nil
+		}
+		return info.slugVersion(), nil
+	}
+	return info.prereleaseVersion(semver.Patch, info.slugLabel())
+}
+
+// emptySlugLabel is the prerelease label of branches whose slug is empty
+const emptySlugLabel = "branch"
+
+// slugLabel returns the branch slug as prerelease label, emptySlugLabel if
+// the slug is empty
+func (i *Info) slugLabel() string {
+	if i.GitBranchSlug == "" {
+		return emptySlugLabel
+	}
+	return i.GitBranchSlug
+}
+
+// GitFlow returns the GitFlow strategy:
+//   - default branch: git describe
+//   - develop: next minor version with alpha label, e.g. v1.3.0-alpha.5
+//   - release/X.Y[.Z]: version from the branch name with beta label, e.g. v1.3.0-beta.2
+//   - hotfix/X.Y.Z: version from the branch name with beta label
+//   - other branches: <branch-slug>-g<hash>
+//
+// The prerelease number is the number of commits since the latest tag.
+// Release and hotfix branches without a version in their name use the next
+// minor and patch version respectively. If the latest tag is no SemVer,
+// branches whose version depends on it fall back to <branch-slug>-g<hash>.
+func GitFlow() Strategy {
+	return gitFlow{}
+}
+
+type gitFlow struct{}
+
+func (gitFlow) Name() string { return "gitflow" }
+
+func (gitFlow) Version(info *Info) (string, error) {
+	switch {
+	case info.IsDefaultBranch():
+		if info.GitDescribe != "" {
+			return info.describeVersion(), nil
+		}
+		return info.slugVersion(), nil
+	case info.GitBranch == "develop":
+		return info.prereleaseVersion(semver.Minor, "alpha")
+	case strings.HasPrefix(info.GitBranch, "release/"):
+		return info.branchPrereleaseVersion(semver.Minor, "beta")
+	case strings.HasPrefix(info.GitBranch, "hotfix/"):
+		return info.branchPrereleaseVersion(semver.Patch, "beta")
+	default:
+		return info.slugVersion(), nil
+	}
+}
+
+// Height returns the commit height strategy: the number of commits since the
+// latest tag is appended as fourth component, e.g. v1.2.3.5, or to the
+// prerelease of a prerelease tag, e.g. v1.3.0-rc.1.5. Without tags the total
+// number of commits is used as patch version, e.g. v0.0.42. A latest tag that
+// is no SemVer is skipped like by GitHubFlow, i.e. <branch-slug>-g<hash> is
+// used.
+func Height() Strategy {
+	return height{}
+}
+
+type height struct{}
+
+func (height) Name() string { return "height" }
+
+func (height) Version(info *Info) (string, error) {
+	current, vPrefix, err := info.latestSemVer()
+	if err != nil {
+		return info.slugVersion(), nil
+	}
+	current.Build = ""
+	if info.LatestTag == "" {
+		current.Patch = uint64(info.CommitsSinceTag)
+		return info.formatSemVer(current, vPrefix), nil
+	}
+	if current.Prerelease != "" {
+		current.Prerelease = fmt.Sprintf("%s.%d", current.Prerelease, info.CommitsSinceTag)
+		return info.formatSemVer(current, vPrefix), nil
+	}
+	return fmt.Sprintf("%s.%d", info.formatSemVer(current, vPrefix), info.CommitsSinceTag), nil
+}
+
+// branchVersionPattern matches a version in a branch name, e.g. release/1.4,
+// release-2.0.x or hotfix/v1.4.2
+var branchVersionPattern = regexp.MustCompile(`[/-]v?(\d+)\.(\d+)(?:\.(\d+|x))?$`)
+
+// parseBranchVersion extracts a MAJOR.MINOR[.PATCH] version from the end of a
+// branch name; a patch of x is treated as 0
+func parseBranchVersion(branch string) (semver.Version, bool) {
+	match := branchVersionPattern.FindStringSubmatch(branch)
+	if match == nil {
+		return semver.Version{}, false
+	}
+	var v semver.Version
+	v.Major, _ = strconv.ParseUint(match[1], 10, 64)
+	v.Minor, _ = strconv.ParseUint(match[2], 10, 64)
+	if match[3] != "" && match[3] != "x" {
+		v.Patch, _ = strconv.ParseUint(match[3], 10, 64)
+	}
+	return v, true
+}
+
+// IsDefaultBranch reports whether the current branch is the default branch
+func (i *Info) IsDefaultBranch() bool {
+	return i.GitBranch == i.DefaultBranch
+}
+
+// describeVersion returns git describe without component namespace and, if
+// stripping is enabled, without tag prefix
+func (i *Info) describeVersion() string {
+	v := strings.TrimPrefix(i.GitDescribe, i.componentPrefix)
+	if i.stripTagPrefix {
+		v = strings.TrimPrefix(v, i.tagPrefix)
+	}
+	return v
+}
+
+// slugVersion returns <branch-slug>-g<short-hash>
+func (i *Info) slugVersion() string {
+	return fmt.Sprintf("%s-g%s", i.GitBranchSlug, i.GitCommitShort)
+}
+
+// prereleaseVersion returns the next version for the bump with a prerelease
+// of the given label and the number of commits since the latest tag. A latest
+// tag that is no SemVer is skipped like by GitHubFlow, i.e. the branch slug
+// version is returned.
+func (i *Info) prereleaseVersion(bump semver.Bump, label string) (string, error) {
+	current, vPrefix, err := i.latestSemVer()
+	if err != nil {
+		return i.slugVersion(), nil
+	}
+	next := current.Bump(bump)
+	next.Prerelease = fmt.Sprintf("%s.%d", label, i.CommitsSinceTag)
+	return i.formatSemVer(next, vPrefix), nil
+}
+
+// branchPrereleaseVersion is like prereleaseVersion but takes the base
+// version from the branch name if it contains one
+func (i *Info) branchPrereleaseVersion(bump semver.Bump, label string) (string, error) {
+	base, ok := parseBranchVersion(i.GitBranch)
+	if !ok {
+		return i.prereleaseVersion(bump, label)
+	}
+
+	_, vPrefix, err := i.latestSemVer()
+	if err != nil {
+		// The base version does not depend on the latest tag
+		vPrefix = i.tagPrefix == ""
+	}
+	base.Prerelease = fmt.Sprintf("%s.%d", label, i.CommitsSinceTag)
+	return i.formatSemVer(base, vPrefix), nil
+}
@@ -0,0 +1,234 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+func TestStrategies(t *testing.T) {
+	base := Info{
+		GitCommitShort:  "abc123d",
+		GitDescribe:     "v1.2.3-5-gabc123d",
+		LatestTag:       "v1.2.3",
+		DefaultBranch:   "main",
+		CommitsSinceTag: 5,
+	}
+
+	tests := []struct {
+		strategy string
+		branch   string
+		expected string
+	}{
+		{"githubflow", "main", "v1.2.3-5-gabc123d"},
+		{"githubflow", "develop", "develop-gabc123d"},
+		{"githubflow", "feature/x", "feature-x-gabc123d"},
+		{"trunk", "main", "v1.2.3-5-gabc123d"},
+		{"trunk", "feature/x", "v1.2.4-feature-x.5"},
+		{"gitflow", "main", "v1.2.3-5-gabc123d"},
+		{"gitflow", "develop", "v1.3.0-alpha.5"},
+		{"gitflow", "release/1.4", "v1.4.0-beta.5"},
+		{"gitflow", "release/v2.0.0", "v2.0.0-beta.5"},
+		{"gitflow", "release/next", "v1.3.0-beta.5"},
+		{"gitflow", "hotfix/1.2.4", "v1.2.4-beta.5"},
+		{"gitflow", "hotfix/urgent", "v1.2.4-beta.5"},
+		{"gitflow", "feature/x", "feature-x-gabc123d"},
+		{"height", "main", "v1.2.3.5"},
+		{"height", "feature/x", "v1.2.3.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+tt.branch, func(t *testing.T) {
+			strategy, err := StrategyByName(tt.strategy)
+			if err != nil {
+				t.Fatalf("StrategyByName(%q) failed: %v", tt.strategy, err)
+			}
+			info := base
+			info.GitBranch = tt.branch
+			info.GitBranchSlug = createBranchSlug(tt.branch)
+
+			result, err := strategy.Version(&info)
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Version() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStrategyWithoutTags(t *testing.T) {
+	info := &Info{
+		GitBranch:       "develop",
+		GitBranchSlug:   "develop",
+		GitCommitShort:  "abc123d",
+		DefaultBranch:   "main",
+		CommitsSinceTag: 12,
+	}
+
+	result, err := GitFlow().Version(info)
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if result != "v0.1.0-alpha.12" {
+		t.Errorf("Version() = %q, want %q", result, "v0.1.0-alpha.12")
+	}
+}
+
+func TestStrategyNonSemVerTag(t *testing.T) {
+	base := Info{
+		GitCommitShort:  "abc123d",
+		GitDescribe:     "nightly-5-gabc123d",
+		LatestTag:       "nightly",
+		DefaultBranch:   "main",
+		CommitsSinceTag: 5,
+	}
+
+	tests := []struct {
+		strategy Strategy
+		branch   string
+		expected string
+	}{
+		{Trunk(), "main", "nightly-5-gabc123d"},
+		{Trunk(), "feature/x", "feature-x-gabc123d"},
+		{GitFlow(), "develop", "develop-gabc123d"},
+		{GitFlow(), "release/next", "release-next-gabc123d"},
+		{GitFlow(), "release/1.4", "v1.4.0-beta.5"},
+		{Height(), "main", "main-gabc123d"},
+		{Height(), "feature/x", "feature-x-gabc123d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.Name()+"/"+tt.branch, func(t *testing.T) {
+			info := base
+			info.GitBranch = tt.branch
+			info.GitBranchSlug = createBranchSlug(tt.branch)
+
+			result, err := tt.strategy.Version(&info)
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Version() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrunkStrategyEmptySlug(t *testing.T) {
+	info := &Info{
+		GitBranch:       "///",
+		GitCommitShort:  "abc123d",
+		LatestTag:       "v1.2.3",
+		DefaultBranch:   "main",
+		CommitsSinceTag: 5,
+	}
+
+	result, err := Trunk().Version(info)
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if result != "v1.2.4-branch.5" {
+		t.Errorf("Version() = %q, want %q", result, "v1.2.4-branch.5")
+	}
+	if _, err := semver.Parse(result[1:]); err != nil {
+		t.Errorf("Version() = %q is no SemVer: %v", result, err)
+	}
+}
+
+func TestHeightStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     Info
+		expected string
+	}{
+		{
+			name:     "no tags",
+			info:     Info{CommitsSinceTag: 42},
+			expected: "v0.0.42",
+		},
+		{
+			name:     "at tag",
+			info:     Info{LatestTag: "v1.2.3"},
+			expected: "v1.2.3.0",
+		},
+		{
+			name:     "prerelease tag",
+			info:     Info{LatestTag: "v1.3.0-rc.1", CommitsSinceTag: 5},
+			expected: "v1.3.0-rc.1.5",
+		},
+		{
+			name:     "tag prefix stripped",
+			info:     Info{LatestTag: "release/1.2.3", CommitsSinceTag: 2, tagPrefix: "release/", stripTagPrefix: true},
+			expected: "1.2.3.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Height().Version(&tt.info)
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Version() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStrategyByNameUnknown(t *testing.T) {
+	if _, err := StrategyByName("waterfall"); err == nil {
+		t.Error("StrategyByName(\"waterfall\") expected error")
+	}
+}
+
+func TestParseBranchVersion(t *testing.T) {
+	tests := []struct {
+		branch   string
+		expected semver.Version
+		ok       bool
+	}{
+		{"release/1.4", semver.Version{Major: 1, Minor: 4}, true},
+		{"release/1.4.2", semver.Version{Major: 1, Minor: 4, Patch: 2}, true},
+		{"hotfix/v2.0.1", semver.Version{Major: 2, Patch: 1}, true},
+		{"release-3.1", semver.Version{Major: 3, Minor: 1}, true},
+		{"release-2.0.x", semver.Version{Major: 2}, true},
+		{"release/next", semver.Version{}, false},
+		{"feature/x", semver.Version{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			result, ok := parseBranchVersion(tt.branch)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("parseBranchVersion(%q) = %+v, %v, want %+v, %v", tt.branch, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoWithStrategy(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	tagged := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+
+	// The repository is on master, so "main" makes it a short-lived branch
+	info, err := GetVersionInfo(tempDir, "main", WithStrategy(Trunk()))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.CommitsSinceTag != 2 {
+		t.Errorf("CommitsSinceTag = %d, want 2", info.CommitsSinceTag)
+	}
+	expected := "v1.0.1-" + info.GitBranchSlug + ".2"
+	if info.Version != expected {
+		t.Errorf("Version = %q, want %q", info.Version, expected)
+	}
+}
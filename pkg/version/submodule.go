@@ -0,0 +1,139 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// superprojectPath returns the working tree of the superproject if the
+// repository is checked out as one of its submodules, like
+// 'git rev-parse --show-superproject-working-tree', or an empty string
+func superprojectPath(repo *git.Repository) string {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return ""
+	}
+	root := wt.Filesystem.Root()
+
+	parent := filepath.Dir(root)
+	if parent == root {
+		return ""
+	}
+	super, err := OpenRepository(parent)
+	if err != nil {
+		return ""
+	}
+	superWt, err := super.Worktree()
+	if err != nil {
+		return ""
+	}
+	superRoot := superWt.Filesystem.Root()
+
+	// The superproject tracks the submodule as gitlink in its index
+	rel, err := filepath.Rel(superRoot, root)
+	if err != nil {
+		return ""
+	}
+	idx, err := super.Storer.Index()
+	if err != nil {
+		return ""
+	}
+	entry, err := idx.Entry(filepath.ToSlash(rel))
+	if err != nil || entry.Mode != filemode.Submodule {
+		return ""
+	}
+	return superRoot
+}
+
+// Submodule is the version info of a submodule of WithRecurseSubmodules
+type Submodule struct {
+	// Name is the name of the submodule in .gitmodules
+	Name string `json:"name" yaml:"name"`
+	// Path is the path of the submodule in the superproject
+	Path string `json:"path" yaml:"path"`
+	// Info is the version info at the commit recorded by the superproject
+	Info *Info `json:"info" yaml:"info"`
+}
+
+// submoduleInfos computes the version info of the checked out submodules of
+// the repository at the commits recorded in the given commit, in path order.
+// A submodule whose HEAD is at that commit is versioned like its working
+// tree, including the dirty check; any other is versioned at the commit.
+// Detached commits, as checked out by git submodule update, are versioned as
+// on the default branch of the submodule.
+func submoduleInfos(repoPath, commit string, o *options) ([]Submodule, error) {
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	c, err := repo.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", commit, err)
+	}
+	file, err := tree.File(".gitmodules")
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse .gitmodules: %w", err)
+	}
+
+	var submodules []Submodule
+	for _, m := range modules.Submodules {
+		entry, err := tree.FindEntry(m.Path)
+		if err != nil || entry.Mode != filemode.Submodule {
+			o.log().Info("submodule not in commit", "submodule", m.Name, "path", m.Path)
+			continue
+		}
+		// Without its own .git the directory belongs to the superproject
+		if _, err := wt.Filesystem.Lstat(path.Join(m.Path, ".git")); err != nil {
+			o.log().Info("submodule not checked out", "submodule", m.Name, "path", m.Path)
+			continue
+		}
+
+		so := *o
+		so.componentPath, so.componentNamespace, so.ref, so.defaultBranch = "", "", "", ""
+		so.detachedBranch, so.headTag, so.ci, so.repo = "", "", nil, nil
+		so.superproject, so.pinned = false, true
+		dir := filepath.Join(wt.Filesystem.Root(), filepath.FromSlash(m.Path))
+		sub, err := OpenRepository(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open submodule %s: %w", m.Name, err)
+		}
+		if head, err := sub.Head(); err != nil || head.Hash() != entry.Hash {
+			so.ref = entry.Hash.String()
+		}
+		info, err := getVersionInfo(dir, &so)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get version info of submodule %s: %w", m.Name, err)
+		}
+		submodules = append(submodules, Submodule{Name: m.Name, Path: m.Path, Info: info})
+	}
+	sort.Slice(submodules, func(i, j int) bool { return submodules[i].Path < submodules[j].Path })
+	return submodules, nil
+}
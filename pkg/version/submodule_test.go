@@ -0,0 +1,138 @@
+package version
+
+import (
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetVersionInfoInSubmodule(t *testing.T) {
+	if !hasGit() {
+		t.Skip("git executable not found")
+	}
+
+	subDir, subRepo := initTestRepo(t)
+	subHead := commitTestFile(t, subRepo, subDir, "lib.txt", "lib", "Library commit")
+	if _, err := subRepo.CreateTag("v0.1.0", subHead, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	superDir, superRepo := initTestRepo(t)
+	first := commitTestFile(t, superRepo, superDir, "app.txt", "app", "App commit")
+	if _, err := superRepo.CreateTag("v2.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	// go-git cannot add submodules, so use the git executable
+	gitArgs := [][]string{
+		{"-c", "protocol.file.allow=always", "submodule", "--quiet", "add", subDir, "libs/sub"},
+		{"-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "Add submodule"},
+	}
+	for _, args := range gitArgs {
+		cmd := exec.Command("git", append([]string{"-C", superDir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	superHead, err := superRepo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+
+	for _, backend := range []Backend{GoGit(), GitCLI()} {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(filepath.Join(superDir, "libs", "sub"), "", WithBackend(backend), WithSuperproject(true))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.Version != "v0.1.0" {
+				t.Errorf("Version = %q, want submodule version v0.1.0", info.Version)
+			}
+			if info.Superproject == nil {
+				t.Fatal("Superproject should be set for a submodule")
+			}
+			if info.Superproject.GitCommit != superHead.Hash().String() {
+				t.Errorf("Superproject.GitCommit = %q, want %q", info.Superproject.GitCommit, superHead.Hash())
+			}
+			if expected := "v2.0.0-1-g" + superHead.Hash().String()[:7]; info.Superproject.Version != expected {
+				t.Errorf("Superproject.Version = %q, want %q", info.Superproject.Version, expected)
+			}
+
+			info, err = GetVersionInfo(superDir, "", WithBackend(backend), WithSuperproject(true))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.Superproject != nil {
+				t.Error("Superproject should be nil outside of a submodule")
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoRecurseSubmodules(t *testing.T) {
+	if !hasGit() {
+		t.Skip("git executable not found")
+	}
+
+	libDir, libRepo := initTestRepo(t)
+	libHead := commitTestFile(t, libRepo, libDir, "lib.txt", "lib", "Library commit")
+	if _, err := libRepo.CreateTag("v0.1.0", libHead, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	fwDir, fwRepo := initTestRepo(t)
+	fwHead := commitTestFile(t, fwRepo, fwDir, "fw.txt", "fw", "Firmware commit")
+	if _, err := fwRepo.CreateTag("v3.0.0", fwHead, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	superDir, superRepo := initTestRepo(t)
+	commitTestFile(t, superRepo, superDir, "app.txt", "app", "App commit")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-C", dir, "-c", "protocol.file.allow=always", "-c", "user.name=Test User", "-c", "user.email=test@example.com"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git(superDir, "submodule", "--quiet", "add", libDir, "libs/lib")
+	git(superDir, "submodule", "--quiet", "add", fwDir, "fw")
+	git(superDir, "commit", "--quiet", "-m", "Add submodules")
+
+	// A commit in the checkout that is not recorded by the superproject
+	git(filepath.Join(superDir, "fw"), "commit", "--quiet", "--allow-empty", "-m", "Unrecorded")
+
+	info, err := GetVersionInfo(superDir, "", WithRecurseSubmodules(true))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if len(info.Submodules) != 2 {
+		t.Fatalf("Submodules = %+v, want 2", info.Submodules)
+	}
+	for i, want := range []struct{ name, path, version, commit string }{
+		{"fw", "fw", "v3.0.0", fwHead.String()},
+		{"libs/lib", "libs/lib", "v0.1.0", libHead.String()},
+	} {
+		sub := info.Submodules[i]
+		if sub.Name != want.name || sub.Path != want.path || sub.Info.Version != want.version || sub.Info.GitCommit != want.commit {
+			t.Errorf("Submodules[%d] = %s %s %s %s, want %+v", i, sub.Name, sub.Path, sub.Info.Version, sub.Info.GitCommit, want)
+		}
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"submodules":[{"name":"fw","path":"fw","info":{"version":"v3.0.0"`) {
+		t.Errorf("json.Marshal() = %s, want submodules", data)
+	}
+
+	info, err = GetVersionInfo(superDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Submodules != nil {
+		t.Error("Submodules should be nil without WithRecurseSubmodules")
+	}
+}
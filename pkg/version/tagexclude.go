@@ -0,0 +1,55 @@
+package version
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// tagExcludes matches the tags excluded by WithTagExcludes
+type tagExcludes struct {
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// newTagExcludes compiles the exclude patterns: a pattern enclosed in slashes
+// is a regular expression, e.g. /-rc\.?[0-9]+$/, any other pattern a glob
+func newTagExcludes(patterns []string) (*tagExcludes, error) {
+	e := &tagExcludes{}
+	for _, pattern := range patterns {
+		if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag exclude pattern %q: %w", pattern, err)
+			}
+			e.regexps = append(e.regexps, re)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tag exclude pattern %q: %w", pattern, err)
+		}
+		e.globs = append(e.globs, pattern)
+	}
+	return e, nil
+}
+
+// match reports whether the tag is excluded. Globs match the full tag name or
+// the name without the prefix, e.g. "*-rc*" matches svc/v1.0.0-rc1 with the
+// prefix svc/; regular expressions match anywhere in the full name.
+func (e *tagExcludes) match(name, prefix string) bool {
+	for _, glob := range e.globs {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(glob, strings.TrimPrefix(name, prefix)); ok {
+			return true
+		}
+	}
+	for _, re := range e.regexps {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestGetVersionInfoWithTagExcludes(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	for _, c := range []struct{ content, tag string }{
+		{"1", "v1.0.0"},
+		{"2", "v1.1.0-rc1"},
+		{"3", "nightly-20240102"},
+		{"4", ""},
+	} {
+		hash := commitTestFile(t, repo, tempDir, "test.txt", c.content, "Commit "+c.content)
+		if c.tag == "" {
+			continue
+		}
+		if _, err := repo.CreateTag(c.tag, hash, nil); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		excludes []string
+		tag      string
+		distance int
+	}{
+		{"none", nil, "nightly-20240102", 1},
+		{"globs", []string{"nightly-*", "*-rc*"}, "v1.0.0", 3},
+		{"regexps", []string{"/^nightly-/", `/-rc\d+$/`}, "v1.0.0", 3},
+		{"partial", []string{"nightly-*"}, "v1.1.0-rc1", 2},
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.Name()+"/"+tt.name, func(t *testing.T) {
+				info, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithTagExcludes(tt.excludes...))
+				if err != nil {
+					t.Fatalf("GetVersionInfo failed: %v", err)
+				}
+				if info.LatestTag != tt.tag || info.CommitsSinceTag != tt.distance {
+					t.Errorf("LatestTag = %q, CommitsSinceTag = %d, want %q and %d", info.LatestTag, info.CommitsSinceTag, tt.tag, tt.distance)
+				}
+			})
+		}
+		for _, pattern := range []string{"[", "/(/"} {
+			if _, err := GetVersionInfo(tempDir, "", WithBackend(backend), WithTagExcludes(pattern)); err == nil {
+				t.Errorf("GetVersionInfo with %s and exclude %q expected error", backend.Name(), pattern)
+			}
+		}
+	}
+}
+
+func TestTagExcludesMatch(t *testing.T) {
+	excludes, err := newTagExcludes([]string{"*-rc*", "/^svc/v0\\./"})
+	if err != nil {
+		t.Fatalf("newTagExcludes failed: %v", err)
+	}
+	tests := []struct {
+		name     string
+		prefix   string
+		expected bool
+	}{
+		{"v1.0.0-rc1", "", true},
+		{"svc/v1.0.0-rc1", "svc/", true},
+		{"svc/v1.0.0", "svc/", false},
+		{"svc/v0.9.0", "svc/", true},
+		{"v1.0.0", "", false},
+	}
+	for _, tt := range tests {
+		if got := excludes.match(tt.name, tt.prefix); got != tt.expected {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.name, tt.prefix, got, tt.expected)
+		}
+	}
+}
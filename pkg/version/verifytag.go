@@ -0,0 +1,180 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/fxsml/gitversion/pkg/conventional"
+	"github.com/fxsml/gitversion/pkg/semver"
+)
+
+// versionLikePattern matches names meant to be versions, e.g. 1.5 or v1.5.0
+var versionLikePattern = regexp.MustCompile(`^v?[0-9]`)
+
+// TagCheck is the result of VerifyHeadTags for a version tag at HEAD
+type TagCheck struct {
+	Tag string `json:"tag"`
+	// Previous is the latest version tag before Tag, empty for the first one
+	Previous string `json:"previous,omitempty"`
+	// Violations are the rules Tag violates, empty if it is valid
+	Violations []string `json:"violations"`
+}
+
+// Valid reports whether the tag violates no rule
+func (c TagCheck) Valid() bool {
+	return len(c.Violations) == 0
+}
+
+// VerifyHeadTags checks the version tags at HEAD, or the commit of WithRef,
+// to catch tags created by hand: each must have the tag prefix, be a semantic
+// version and follow the previous version tag by a single patch, minor or
+// major bump. With conventionalCommits it must be the bump the Conventional
+// Commits since the previous tag call for, or the version of a Release-As
+// trailer. Tags lacking the prefix are checked if they look like versions,
+// e.g. 1.5.0 or v1.5; excluded tags are ignored. Without version tags at HEAD no checks
+// are returned.
+func VerifyHeadTags(repoPath string, conventionalCommits bool, opts ...Option) ([]TagCheck, error) {
+	o := newOptions(opts)
+	repo, err := o.openRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	head, err := resolveHead(repo, o.ref)
+	if err != nil {
+		return nil, err
+	}
+	atHead, err := tagsAt(repo, head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	excludes, err := newTagExcludes(o.tagExcludes)
+	if err != nil {
+		return nil, err
+	}
+	namespace, prefix := o.componentTagPrefix(), o.matchPrefix()
+	var tags []string
+	for _, name := range atHead {
+		if !strings.HasPrefix(name, namespace) || excludes.match(name, prefix) {
+			continue
+		}
+		if versionLikePattern.MatchString(strings.TrimPrefix(name, namespace)) || (prefix != namespace && strings.HasPrefix(name, prefix)) {
+			tags = append(tags, name)
+		}
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	// The previous version is the one with the tags at HEAD excluded
+	po := *o
+	po.tagExcludes = append([]string(nil), o.tagExcludes...)
+	for _, name := range atHead {
+		po.tagExcludes = append(po.tagExcludes, "/^"+regexp.QuoteMeta(name)+"$/")
+	}
+	po.dirtyCheck = false
+	previous, err := getVersionInfo(repoPath, &po)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the previous version: %w", err)
+	}
+	previousVersion, previousErr := previous.LatestSemVer()
+
+	var checks []TagCheck
+	for _, name := range tags {
+		check := TagCheck{Tag: name, Previous: previous.LatestTag, Violations: []string{}}
+		rest := strings.TrimPrefix(name, prefix)
+		if !strings.HasPrefix(name, prefix) {
+			check.Violations = append(check.Violations, fmt.Sprintf("missing tag prefix %q", o.tagPrefix))
+			rest = strings.TrimPrefix(name, namespace)
+		}
+		v, err := semver.Parse(rest)
+		if err != nil {
+			check.Violations = append(check.Violations, err.Error())
+		} else if previous.LatestTag != "" && previousErr == nil {
+			violation, err := checkTagBump(repo, head.Hash(), previous.LatestTag, previousVersion, v, conventionalCommits)
+			if err != nil {
+				return nil, err
+			}
+			if violation != "" {
+				check.Violations = append(check.Violations, violation)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// tagsAt returns the sorted names of the tags pointing to the commit,
+// directly or through an annotated tag
+func tagsAt(repo *git.Repository, commit plumbing.Hash) ([]string, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	var names []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tag, err := repo.TagObject(hash); err == nil {
+			c, err := tag.Commit()
+			if err != nil {
+				// Tags of trees and blobs never point to a commit
+				return nil
+			}
+			hash = c.Hash
+		}
+		if hash == commit {
+			names = append(names, ref.Name().Short())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// checkTagBump checks that v follows the previous version by a single bump,
+// or with conventionalCommits by the one the commits since the previous tag
+// call for, and returns the violation, if any
+func checkTagBump(repo *git.Repository, head plumbing.Hash, previousTag string, previous, v semver.Version, conventionalCommits bool) (string, error) {
+	if v.Compare(previous) <= 0 {
+		return fmt.Sprintf("not higher than the previous version %s", previous), nil
+	}
+	core := semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+
+	bumps := []semver.Bump{semver.Patch, semver.Minor, semver.Major}
+	if conventionalCommits {
+		result, err := conventional.Analyze(repo, head, previousTag)
+		if err != nil {
+			return "", err
+		}
+		if result.ReleaseAs != "" {
+			releaseAs, err := semver.Parse(result.ReleaseAs)
+			if err != nil {
+				return "", fmt.Errorf("invalid Release-As version: %w", err)
+			}
+			if core.Compare(releaseAs) != 0 {
+				return fmt.Sprintf("expected version %s of Release-As", releaseAs), nil
+			}
+			return "", nil
+		}
+		// Commits without features or fixes still release a patch
+		bumps = []semver.Bump{max(result.Bump, semver.Patch)}
+	}
+
+	var expected []string
+	for _, bump := range bumps {
+		next := previous.Bump(bump)
+		if next.Compare(core) == 0 {
+			return "", nil
+		}
+		expected = append(expected, fmt.Sprintf("%s (%s)", next, bump))
+	}
+	return fmt.Sprintf("skips versions after %s: expected %s", previous, strings.Join(expected, ", ")), nil
+}
@@ -0,0 +1,70 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestVerifyHeadTags(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	first := commitTestFile(t, repo, tempDir, "test.txt", "1", "Initial commit")
+	if _, err := repo.CreateTag("v1.4.2", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "2", "feat: add feature")
+
+	checks, err := VerifyHeadTags(tempDir, false, WithTagPrefix("v"))
+	if err != nil {
+		t.Fatalf("VerifyHeadTags failed: %v", err)
+	}
+	if len(checks) != 0 {
+		t.Errorf("VerifyHeadTags() = %+v without tags at HEAD, want none", checks)
+	}
+
+	tests := []struct {
+		tag        string
+		auto       bool
+		violations int
+	}{
+		{"v1.4.3", false, 0},
+		{"v1.4.3", true, 1},
+		{"v1.5.0", false, 0},
+		{"v1.5.0", true, 0},
+		{"v2.0.0-rc.1", false, 0},
+		{"v2.0.0-rc.1", true, 1},
+		{"v1.6.0", false, 1},
+		{"v1.4.1", false, 1},
+		{"v1.5", false, 1},
+		{"1.5.0", false, 1},
+		{"1.6", false, 2},
+		{"b-v1.5.0", false, -1},
+		{"other", false, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if _, err := repo.CreateTag(tt.tag, head, &git.CreateTagOptions{Tagger: testSignature(), Message: tt.tag}); err != nil {
+				t.Fatalf("Failed to create tag: %v", err)
+			}
+			defer repo.DeleteTag(tt.tag)
+
+			checks, err := VerifyHeadTags(tempDir, tt.auto, WithTagPrefix("v"))
+			if err != nil {
+				t.Fatalf("VerifyHeadTags failed: %v", err)
+			}
+			if tt.violations < 0 {
+				if len(checks) != 0 {
+					t.Errorf("VerifyHeadTags() = %+v, want no version tags", checks)
+				}
+				return
+			}
+			if len(checks) != 1 || checks[0].Tag != tt.tag || checks[0].Previous != "v1.4.2" {
+				t.Fatalf("VerifyHeadTags() = %+v, want a check of %s after v1.4.2", checks, tt.tag)
+			}
+			if len(checks[0].Violations) != tt.violations {
+				t.Errorf("Violations = %q, want %d", checks[0].Violations, tt.violations)
+			}
+		})
+	}
+}
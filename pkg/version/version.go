@@ -1,138 +1,473 @@
 package version
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fxsml/gitversion/pkg/semver"
 )
 
-// Info contains version information
+// Info contains version information. It is serialized to JSON and YAML with
+// its fields in declaration order; LatestTag is omitted if empty.
 type Info struct {
-	Version        string
-	GitCommit      string
-	GitCommitShort string
-	GitBranch      string
-	GitBranchSlug  string
-	GitDescribe    string
-	LatestTag      string
-	BuildTime      string
-	IsDirty        bool
-	DefaultBranch  string
+	Version         string `json:"version" yaml:"version"`
+	GitCommit       string `json:"gitCommit" yaml:"gitCommit"`
+	GitCommitShort  string `json:"gitCommitShort" yaml:"gitCommitShort"`
+	GitBranch       string `json:"gitBranch" yaml:"gitBranch"`
+	GitBranchSlug   string `json:"gitBranchSlug" yaml:"gitBranchSlug"`
+	GitDescribe     string `json:"gitDescribe" yaml:"gitDescribe"`
+	LatestTag       string `json:"latestTag,omitempty" yaml:"latestTag,omitempty"`
+	BuildTime       string `json:"buildTime" yaml:"buildTime"`
+	IsDirty         bool   `json:"isDirty" yaml:"isDirty"`
+	DefaultBranch   string `json:"defaultBranch" yaml:"defaultBranch"`
+	CommitsSinceTag int    `json:"commitsSinceTag" yaml:"commitsSinceTag"`
+	// HasCommits is false in a new repository whose HEAD points to a branch
+	// without commits; GitCommit is then the zero hash
+	HasCommits bool `json:"hasCommits" yaml:"hasCommits"`
+	// IsShallow reports a shallow clone, in which the latest tag and the
+	// commit distance may be wrong; see WithDeepen
+	IsShallow bool `json:"isShallow" yaml:"isShallow"`
+	// DescribeTruncated reports that no tag was found within the commits
+	// searched with WithMaxDescribeDepth; the version is <branch-slug>-g<hash>
+	DescribeTruncated bool `json:"describeTruncated,omitempty" yaml:"describeTruncated,omitempty"`
+	// Major, Minor, Patch, Prerelease and BuildMetadata are the SemVer
+	// components of the latest tag without component namespace and tag
+	// prefix; they are zero if there is no tag or it is not SemVer
+	Major         uint64 `json:"major" yaml:"major"`
+	Minor         uint64 `json:"minor" yaml:"minor"`
+	Patch         uint64 `json:"patch" yaml:"patch"`
+	Prerelease    string `json:"prerelease,omitempty" yaml:"prerelease,omitempty"`
+	BuildMetadata string `json:"buildMetadata,omitempty" yaml:"buildMetadata,omitempty"`
+	// CommitTime, CommitterName, AuthorEmail and CommitMessageSubject are
+	// read from the HEAD commit; they are empty if there are no commits
+	CommitTime           string `json:"commitTime,omitempty" yaml:"commitTime,omitempty"`
+	CommitterName        string `json:"committerName,omitempty" yaml:"committerName,omitempty"`
+	AuthorEmail          string `json:"authorEmail,omitempty" yaml:"authorEmail,omitempty"`
+	CommitMessageSubject string `json:"commitMessageSubject,omitempty" yaml:"commitMessageSubject,omitempty"`
+	// RemoteURL is the URL of the origin remote without credentials;
+	// RepoOwner and RepoName are parsed from it, e.g. org and repo of
+	// git@github.com:org/repo.git. They are empty without origin.
+	RemoteURL string `json:"remoteURL,omitempty" yaml:"remoteURL,omitempty"`
+	RepoOwner string `json:"repoOwner,omitempty" yaml:"repoOwner,omitempty"`
+	RepoName  string `json:"repoName,omitempty" yaml:"repoName,omitempty"`
+	// TagMessage, Tagger ("Name <email>") and TagTime are read from the
+	// latest tag if it points to HEAD and is annotated, else empty
+	TagMessage string `json:"tagMessage,omitempty" yaml:"tagMessage,omitempty"`
+	Tagger     string `json:"tagger,omitempty" yaml:"tagger,omitempty"`
+	TagTime    string `json:"tagTime,omitempty" yaml:"tagTime,omitempty"`
+	// CommitSigned and TagSigned report that HEAD and the latest tag are
+	// signed by a trusted key; they are only set with WithVerifySignatures
+	// and omitted from JSON and YAML if false
+	CommitSigned bool `json:"commitSigned,omitempty" yaml:"commitSigned,omitempty"`
+	TagSigned    bool `json:"tagSigned,omitempty" yaml:"tagSigned,omitempty"`
+	// PullRequestNumber is the pull or merge request of a CI build of WithCI,
+	// zero outside of pull request builds
+	PullRequestNumber int `json:"pullRequestNumber,omitempty" yaml:"pullRequestNumber,omitempty"`
+	// CI is the CI build of WithCI, if any
+	CI *CI `json:"ci,omitempty" yaml:"ci,omitempty"`
+	// Superproject is the version info of the superproject if the repository
+	// is a submodule and WithSuperproject is enabled
+	Superproject *Info `json:"superproject,omitempty" yaml:"superproject,omitempty"`
+	// Submodules are the version infos of the submodules if
+	// WithRecurseSubmodules is enabled
+	Submodules []Submodule `json:"submodules,omitempty" yaml:"submodules,omitempty"`
+
+	// componentPrefix is the component namespace LatestTag was matched with
+	componentPrefix string
+	// tagPrefix is the prefix LatestTag was matched with after the namespace
+	tagPrefix string
+	// stripTagPrefix reports whether the tag prefix is removed from versions
+	stripTagPrefix bool
+	// commitTime is the committer date of HEAD
+	commitTime time.Time
+	// dirtyHash is the hash of the dirty working tree if the dirty format
+	// contains {hash}
+	dirtyHash string
+	// tags are the names of all tags if WithPrereleaseLabels or
+	// WithMonotonic needs them
+	tags []string
 }
 
 // GetVersionInfo retrieves version information from the Git repository at the given path
 // defaultBranch specifies the main branch (e.g., "main" or "master"). If empty, attempts auto-detection.
-func GetVersionInfo(repoPath string, defaultBranch string) (*Info, error) {
-	// Find the git root by walking up until .git is found
-	absPath, err := filepath.Abs(repoPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+func GetVersionInfo(repoPath string, defaultBranch string, opts ...Option) (*Info, error) {
+	return GetVersionInfoContext(context.Background(), repoPath, defaultBranch, opts...)
+}
+
+// GetVersionInfoContext is like GetVersionInfo but aborts walking the history
+// and checking the working tree once the context is done
+func GetVersionInfoContext(ctx context.Context, repoPath string, defaultBranch string, opts ...Option) (*Info, error) {
+	o := newOptions(opts)
+	o.defaultBranch = defaultBranch
+	o.ctx = ctx
+	return getVersionInfo(repoPath, o)
+}
+
+// GetVersionInfoFromRepo is like GetVersionInfo for a repository the caller
+// opened, e.g. cloned into memory with go-git's memory storage or backed by
+// another billy filesystem. The dirty check applies to the worktree of the
+// repository, if it has one. Only the go-git backend is supported, without
+// WithCache; the default branch is detected.
+func GetVersionInfoFromRepo(repo *git.Repository, opts ...Option) (*Info, error) {
+	return GetVersionInfoFromRepoContext(context.Background(), repo, opts...)
+}
+
+// GetVersionInfoFromRepoContext is like GetVersionInfoFromRepo but aborts
+// walking the history and checking the working tree once the context is done
+func GetVersionInfoFromRepoContext(ctx context.Context, repo *git.Repository, opts ...Option) (*Info, error) {
+	return FromRepositoryContext(ctx, repo, "", opts...)
+}
+
+// FromRepository is like GetVersionInfo for a repository the caller already
+// opened, e.g. a server or other git tooling holding a handle, without opening
+// and discovering it again. The go-git backend reads the repository as is;
+// worktreePath is its working tree on disk, which the git-cli backend runs
+// in and WithCache keys entries by. Without a path it is GetVersionInfoFromRepo.
+func FromRepository(repo *git.Repository, worktreePath string, opts ...Option) (*Info, error) {
+	return FromRepositoryContext(context.Background(), repo, worktreePath, opts...)
+}
+
+// FromRepositoryContext is like FromRepository but aborts walking the history
+// and checking the working tree once the context is done
+func FromRepositoryContext(ctx context.Context, repo *git.Repository, worktreePath string, opts ...Option) (*Info, error) {
+	if repo == nil {
+		return nil, errors.New("no repository given")
 	}
-	origPath := absPath
-	gitRoot := ""
-	for {
-		gitDir := absPath + "/.git"
-		if fi, err := os.Stat(gitDir); err == nil && (fi.IsDir() || fi.Mode().IsRegular()) {
-			gitRoot = absPath
-			break
+	o := newOptions(opts)
+	o.ctx = ctx
+	o.repo = repo
+	if worktreePath == "" {
+		if _, ok := o.backend.(goGit); !ok {
+			return nil, fmt.Errorf("the %s backend needs a repository path, use FromRepository", o.backend.Name())
 		}
-		parent := parentDir(absPath)
-		if parent == absPath {
-			// Reached filesystem root
-			return nil, fmt.Errorf("failed to open repository: no .git found from %s upwards", origPath)
+		if o.cacheDir != "" {
+			return nil, errors.New("the cache needs a repository path, use FromRepository")
 		}
-		absPath = parent
 	}
+	return getVersionInfo(worktreePath, o)
+}
 
-	repo, err := git.PlainOpen(gitRoot)
+// getVersionInfo implements GetVersionInfo for resolved options
+func getVersionInfo(repoPath string, o *options) (*Info, error) {
+	if o.defaultBranch == "" && o.ci != nil {
+		o.defaultBranch = o.ci.DefaultBranch
+	}
+	state, err := o.inspect(repoPath)
+	if errors.Is(err, ErrNoRepository) && o.buildInfo != nil {
+		return o.buildInfoVersion(err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to open repository: %w", err)
+		return nil, err
 	}
+	if err := o.applyCIRefs(state); err != nil {
+		return nil, err
+	}
+	o.logState(state)
 
 	info := &Info{
-		BuildTime: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		BuildTime:         time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		GitCommit:         state.commit,
+		GitCommitShort:    state.shortCommit,
+		GitBranch:         state.branch,
+		GitBranchSlug:     createBranchSlug(state.branch),
+		GitDescribe:       state.describe.format(state.shortCommit),
+		LatestTag:         state.describe.Tag,
+		CommitsSinceTag:   state.describe.Distance,
+		IsDirty:           state.dirty,
+		DefaultBranch:     state.defaultBranch,
+		HasCommits:        !state.unborn,
+		IsShallow:         state.shallow,
+		DescribeTruncated: state.describe.Truncated,
+		CommitSigned:      state.commitSigned,
+		TagSigned:         state.tagSigned,
+		CI:                o.ci,
+		componentPrefix:   o.componentTagPrefix(),
+		tagPrefix:         o.tagPrefix,
+		stripTagPrefix:    o.stripTagPrefix,
+		commitTime:        state.commitTime,
+		dirtyHash:         state.dirtyHash,
+		tags:              state.tags,
+	}
+	info.RemoteURL, info.RepoOwner, info.RepoName = parseRemoteURL(state.remoteURL)
+	if o.ci != nil && o.ci.Tag == "" && o.ref == "" {
+		// Not a number for providers naming pull requests otherwise
+		info.PullRequestNumber, _ = strconv.Atoi(o.ci.PullRequest)
+	}
+	if a := state.annotation; a.tagger != "" {
+		info.TagMessage, info.Tagger = a.message, a.tagger
+		info.TagTime = a.time.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if !state.unborn {
+		info.CommitTime = state.commitTime.UTC().Format("2006-01-02T15:04:05Z")
+		info.CommitterName = state.committerName
+		info.AuthorEmail = state.authorEmail
+		info.CommitMessageSubject = state.subject
+	}
+
+	// Split the latest tag into its SemVer components
+	if v, err := info.LatestSemVer(); err == nil {
+		info.Major, info.Minor, info.Patch = v.Major, v.Minor, v.Patch
+		info.Prerelease, info.BuildMetadata = v.Prerelease, v.Build
+	}
+
+	// Determine version based on branch and tags, unless the search for the
+	// latest tag gave up
+	if info.DescribeTruncated {
+		info.Version = info.slugVersion()
+	} else if info.Version, err = o.version(info); err != nil {
+		return nil, err
+	}
+	if o.monotonic {
+		if err := o.checkMonotonicInfo(info); err != nil {
+			return nil, err
+		}
 	}
 
-	// Auto-detect default branch if not specified
-	if defaultBranch == "" {
-		defaultBranch = detectDefaultBranch(repo)
+	// Add the version info of the superproject, with the same options except
+	// those specific to this repository
+	if state.superproject != "" {
+		so := *o
+		so.componentPath, so.componentNamespace, so.ref, so.defaultBranch = "", "", "", ""
+		so.detachedBranch, so.headTag, so.ci, so.repo = "", "", nil, nil
+		info.Superproject, err = getVersionInfo(state.superproject, &so)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get superproject version info: %w", err)
+		}
 	}
 
-	// Store the default branch in info
-	info.DefaultBranch = defaultBranch
+	if o.recurseSubmodules && !state.unborn {
+		info.Submodules, err = submoduleInfos(repoPath, state.commit, o)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// Get HEAD reference
-	head, err := repo.Head()
+	// Replace the -g<hash> suffix with SemVer build metadata if requested
+	if o.metadataFormat != "" {
+		info.Version, err = info.applyMetadata(info.Version, o.metadataFormat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply build metadata: %w", err)
+		}
+	}
+
+	// Append the dirty suffix if there are uncommitted changes
+	if info.IsDirty {
+		info.Version, err = info.applyDirtyFormat(info.Version, o.dirtyFormat, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply dirty format: %w", err)
+		}
+	}
+
+	// Let the modifiers rewrite the final version
+	if err := info.applyModifiers(o.ctx, o.modifiers); err != nil {
+		return nil, fmt.Errorf("failed to modify version: %w", err)
+	}
+
+	o.log().Info("computed version", "version", info.Version)
+	return info, nil
+}
+
+// version computes the version with the pull request template in pull request
+// builds, else the first matching branch template, release branch rule,
+// prerelease label or else the strategy
+func (o *options) version(info *Info) (string, error) {
+	if info.PullRequestNumber != 0 {
+		v, err := info.renderBranchTemplate(o.prTemplate)
+		if err != nil {
+			return "", fmt.Errorf("failed to render pull request template: %w", err)
+		}
+		o.log().Info("versioned as pull request", "pullRequest", info.PullRequestNumber, "template", o.prTemplate)
+		return v, nil
+	}
+
+	tmpl, ok, err := matchBranchTemplate(info.GitBranch, o.branchTemplates)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		v, err := info.renderBranchTemplate(tmpl.Template)
+		if err != nil {
+			return "", fmt.Errorf("failed to render template for branch pattern %s: %w", tmpl.Pattern, err)
+		}
+		o.log().Info("versioned by branch template", "pattern", tmpl.Pattern, "template", tmpl.Template)
+		return v, nil
+	}
+
+	rule, ok, err := matchReleaseBranch(info.GitBranch, o.releaseBranches)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		return "", err
+	}
+	if ok {
+		v, err := info.branchPrereleaseVersion(semver.Minor, rule.Label)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute version for release branch pattern %s: %w", rule.Pattern, err)
+		}
+		o.log().Info("versioned as release branch", "pattern", rule.Pattern, "label", rule.Label)
+		return v, nil
 	}
 
-	// Get commit hash
-	info.GitCommit = head.Hash().String()
-	info.GitCommitShort = head.Hash().String()[:7]
+	labelRule, ok, err := matchPrereleaseLabel(info.GitBranch, o.prereleaseLabels)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		label, err := info.renderBranchTemplate(labelRule.Label)
+		if err != nil {
+			return "", fmt.Errorf("failed to render label for prerelease label pattern %s: %w", labelRule.Pattern, err)
+		}
+		v, err := info.labelVersion(label, labelRule.Numbering)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute version for prerelease label pattern %s: %w", labelRule.Pattern, err)
+		}
+		o.log().Info("versioned by prerelease label", "pattern", labelRule.Pattern, "label", label, "numbering", labelRule.Numbering)
+		return v, nil
+	}
 
-	// Get branch name
-	if head.Name().IsBranch() {
-		info.GitBranch = head.Name().Short()
-	} else {
-		// Detached HEAD state
-		info.GitBranch = "HEAD"
+	v, err := o.strategy.Version(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute version with strategy %s: %w", o.strategy.Name(), err)
 	}
+	o.log().Info("versioned by strategy", "strategy", o.strategy.Name(), "defaultBranch", info.IsDefaultBranch())
+	return v, nil
+}
 
-	// Create branch slug
-	info.GitBranchSlug = createBranchSlug(info.GitBranch)
+// ErrNoRepository is returned if no repository contains the given path
+var ErrNoRepository = errors.New("no .git found")
 
-	// Get git describe (tags)
-	info.GitDescribe, info.LatestTag = getGitDescribe(repo, head.Hash())
+// ErrNoCommits is returned if a result requires a commit but the repository
+// has none yet
+var ErrNoCommits = errors.New("the repository has no commits")
 
-	// Check for uncommitted changes
-	info.IsDirty = hasUncommittedChanges(repo)
+// OpenRepository opens the Git repository containing the given path,
+// walking up the directory tree until a .git directory or file is found.
+// Like git, it honors GIT_DIR, GIT_WORK_TREE and GIT_CEILING_DIRECTORIES.
+func OpenRepository(repoPath string) (*git.Repository, error) {
+	return newOptions(nil).openRepository(repoPath)
+}
+
+// openRepository is OpenRepository, stopping the search at the ceiling
+// directories of WithCeilingDirectories and the filesystem boundaries of
+// WithOneFilesystem as well. The repository of FromRepository is returned
+// as is.
+func (o *options) openRepository(repoPath string) (*git.Repository, error) {
+	if o.repo != nil {
+		return o.repo, nil
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
 
-	// Determine version based on branch and tags
-	if info.GitBranch == defaultBranch {
-		// On default branch: use git describe if tags exist, otherwise branch-slug-ghash
-		if info.GitDescribe != "" {
-			info.Version = info.GitDescribe
-		} else {
-			info.Version = fmt.Sprintf("%s-g%s", info.GitBranchSlug, info.GitCommitShort)
+	// GIT_DIR skips discovery; the working tree defaults to the given path
+	if gitDir := os.Getenv("GIT_DIR"); gitDir != "" {
+		o.log().Info("opening repository from GIT_DIR", "gitDir", gitDir, "workTree", absPath)
+		repo, err := plainOpen(gitDir)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		// On other branches: always use branch-slug-ghash format
-		info.Version = fmt.Sprintf("%s-g%s", info.GitBranchSlug, info.GitCommitShort)
+		return withWorkTree(repo, absPath)
 	}
 
-	// Append timestamp suffix if there are uncommitted changes
-	if info.IsDirty {
-		timestamp := time.Now().UTC().Format("20060102150405")
-		info.Version = fmt.Sprintf("%s-%s", info.Version, timestamp)
+	origPath := absPath
+	ceilings := o.ceilingDirectories()
+	filesystem := filesystemID(absPath)
+	gitRoot := ""
+	for {
+		gitDir := filepath.Join(absPath, ".git")
+		if fi, err := os.Stat(gitDir); err == nil && (fi.IsDir() || fi.Mode().IsRegular()) {
+			gitRoot = absPath
+			break
+		}
+		o.log().Log(o.ctx, LevelTrace, "no .git in directory, searching upwards", "dir", absPath)
+		parent := filepath.Dir(absPath)
+		if parent == absPath {
+			// Reached the filesystem root, e.g. / or C:\ on Windows
+			return nil, fmt.Errorf("failed to open repository: %w from %s upwards", ErrNoRepository, origPath)
+		}
+		if ceilings[parent] {
+			return nil, fmt.Errorf("failed to open repository: %w from %s up to ceiling %s", ErrNoRepository, origPath, parent)
+		}
+		if o.oneFilesystem && filesystemID(parent) != filesystem {
+			return nil, fmt.Errorf("failed to open repository: %w from %s up to the filesystem boundary at %s", ErrNoRepository, origPath, absPath)
+		}
+		absPath = parent
 	}
 
-	return info, nil
+	o.log().Info("found repository", "root", gitRoot, "from", origPath)
+	repo, err := plainOpen(gitRoot)
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv("GIT_WORK_TREE") == "" {
+		return repo, nil
+	}
+	return withWorkTree(repo, gitRoot)
 }
 
-// parentDir returns the parent directory of the given path
-func parentDir(path string) string {
-	if path == "/" {
-		return "/"
+// plainOpen opens the repository with a .git directory or file at path, or
+// the git directory at path itself
+func plainOpen(path string) (*git.Repository, error) {
+	// EnableDotGitCommonDir follows the commondir file of linked worktrees
+	// (git worktree add) to the shared refs and objects of the main repository
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return repo, nil
+}
+
+// withWorkTree reopens the repository with the working tree from GIT_WORK_TREE,
+// or the given default if it is not set
+func withWorkTree(repo *git.Repository, defaultPath string) (*git.Repository, error) {
+	workTree := os.Getenv("GIT_WORK_TREE")
+	if workTree == "" {
+		workTree = defaultPath
 	}
-	path = strings.TrimRight(path, "/")
-	idx := strings.LastIndex(path, "/")
-	if idx <= 0 {
-		return "/"
+	workTree, err := filepath.Abs(workTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
-	return path[:idx]
+
+	repo, err = git.Open(repo.Storer, osfs.New(workTree))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return repo, nil
+}
+
+// ceilingDirectories returns the absolute paths in GIT_CEILING_DIRECTORIES
+// and of WithCeilingDirectories, which discovery does not walk up into
+func (o *options) ceilingDirectories() map[string]bool {
+	ceilings := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("GIT_CEILING_DIRECTORIES")) {
+		if filepath.IsAbs(dir) {
+			ceilings[filepath.Clean(dir)] = true
+		}
+	}
+	for _, dir := range o.ceilingDirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			ceilings[abs] = true
+		}
+	}
+	return ceilings
 }
 
 // detectDefaultBranch attempts to detect the default branch from the repository
-// It checks the symbolic ref of origin/HEAD, falling back to common defaults
-func detectDefaultBranch(repo *git.Repository) string {
+// It checks the symbolic ref of origin/HEAD, then with WithRemoteHEADQuery the
+// HEAD of origin, falling back to common defaults
+func detectDefaultBranch(repo *git.Repository, o *options) string {
 	// Try to get the default branch from origin/HEAD
 	ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), true)
 	if err == nil && ref != nil {
@@ -145,6 +480,14 @@ func detectDefaultBranch(repo *git.Repository) string {
 		return refName
 	}
 
+	if o.remoteHEADTimeout > 0 {
+		branch, err := remoteHEAD(o.ctx, repo, DefaultFetchRemote, o.remoteHEADTimeout)
+		if err == nil {
+			return branch
+		}
+		o.log().Info("default branch not queried from remote", "error", err)
+	}
+
 	// Fallback: check if main or master branch exists
 	branches := []string{"main", "master"}
 	refs, err := repo.References()
@@ -168,35 +511,6 @@ func detectDefaultBranch(repo *git.Repository) string {
 	return "main"
 }
 
-// hasUncommittedChanges checks if the repository has uncommitted changes
-// Only checks for staged and unstaged modifications, not untracked files
-func hasUncommittedChanges(repo *git.Repository) bool {
-	worktree, err := repo.Worktree()
-	if err != nil {
-		return false
-	}
-
-	status, err := worktree.Status()
-	if err != nil {
-		return false
-	}
-
-	// Check only for modified, added, deleted, renamed, or copied files
-	// Ignore untracked files (Untracked status)
-	for _, fileStatus := range status {
-		// Check staging area
-		if fileStatus.Staging != git.Untracked && fileStatus.Staging != git.Unmodified {
-			return true
-		}
-		// Check worktree (but not untracked files)
-		if fileStatus.Worktree != git.Untracked && fileStatus.Worktree != git.Unmodified {
-			return true
-		}
-	}
-
-	return false
-}
-
 // createBranchSlug creates a slug from branch name
 // Replaces / and _ with -, keeps only alphanumeric and -
 func createBranchSlug(branch string) string {
@@ -211,63 +525,119 @@ func createBranchSlug(branch string) string {
 	return slug
 }
 
-// getGitDescribe attempts to get the output similar to 'git describe --tags HEAD'
-// Returns (describe, tagName) where describe is the full git describe output and tagName is just the tag
-func getGitDescribe(repo *git.Repository, hash plumbing.Hash) (string, string) {
-	// Get all tags and build a map of commit hash -> tag name
-	tagRefs, err := repo.Tags()
-	if err != nil {
-		return "", ""
-	}
+// String returns a formatted string representation of the version info
+func (i *Info) String() string {
+	return i.Version
+}
 
-	tagMap := make(map[plumbing.Hash]string)
-	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
-		tagMap[ref.Hash()] = ref.Name().Short()
-		return nil
-	})
+// Format renders the version info using the given Go text/template,
+// e.g. "{{.GitBranchSlug}}-{{.GitCommitShort}}"
+func (i *Info) Format(format string) (string, error) {
+	tmpl, err := template.New("format").Parse(format)
 	if err != nil {
-		return "", ""
+		return "", fmt.Errorf("failed to parse format template: %w", err)
 	}
-
-	// Check if current commit is exactly at a tag
-	if tagName, exists := tagMap[hash]; exists {
-		return tagName, tagName
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, i); err != nil {
+		return "", fmt.Errorf("failed to render format template: %w", err)
 	}
+	return sb.String(), nil
+}
 
-	// Walk commit history to find the most recent tag
-	commitIter, err := repo.Log(&git.LogOptions{
-		From: hash,
-	})
-	if err != nil {
-		return "", ""
-	}
-	defer commitIter.Close()
+// Field is a named version info value in a flat, string-only form
+type Field struct {
+	Name  string
+	Value string
+}
 
-	distance := 0
-	var foundTag string
+// Fields returns the version info as an ordered list of snake_case named values,
+// suitable for key/value outputs such as environment variables
+func (i *Info) Fields() []Field {
+	var ci CI
+	if i.CI != nil {
+		ci = *i.CI
+	}
+	return []Field{
+		{"version", i.Version},
+		{"commit", i.GitCommit},
+		{"commit_short", i.GitCommitShort},
+		{"branch", i.GitBranch},
+		{"branch_slug", i.GitBranchSlug},
+		{"describe", i.GitDescribe},
+		{"latest_tag", i.LatestTag},
+		{"build_time", i.BuildTime},
+		{"is_dirty", strconv.FormatBool(i.IsDirty)},
+		{"default_branch", i.DefaultBranch},
+		{"commits_since_tag", strconv.Itoa(i.CommitsSinceTag)},
+		{"has_commits", strconv.FormatBool(i.HasCommits)},
+		{"is_shallow", strconv.FormatBool(i.IsShallow)},
+		{"major", strconv.FormatUint(i.Major, 10)},
+		{"minor", strconv.FormatUint(i.Minor, 10)},
+		{"patch", strconv.FormatUint(i.Patch, 10)},
+		{"prerelease", i.Prerelease},
+		{"build_metadata", i.BuildMetadata},
+		{"commit_time", i.CommitTime},
+		{"committer_name", i.CommitterName},
+		{"author_email", i.AuthorEmail},
+		{"commit_message_subject", i.CommitMessageSubject},
+		{"remote_url", i.RemoteURL},
+		{"repo_owner", i.RepoOwner},
+		{"repo_name", i.RepoName},
+		{"commit_signed", strconv.FormatBool(i.CommitSigned)},
+		{"tag_signed", strconv.FormatBool(i.TagSigned)},
+		{"ci_provider", ci.Provider},
+		{"ci_build_number", ci.BuildNumber},
+		{"ci_pull_request", ci.PullRequest},
+	}
+}
 
-	err = commitIter.ForEach(func(commit *object.Commit) error {
-		if tagName, exists := tagMap[commit.Hash]; exists {
-			foundTag = tagName
-			return fmt.Errorf("found") // Stop iteration
+// Env returns the version info as dotenv lines (KEY=value), one per field,
+// with each key upper-cased and prefixed, e.g. GITVERSION_VERSION=v1.0.0.
+// Values are quoted with ShellQuote, so that the lines can be evaluated by a
+// shell even if a commit subject contains spaces, quotes or $(...).
+func (i *Info) Env(prefix string) string {
+	var sb strings.Builder
+	for _, f := range i.Fields() {
+		value := f.Value
+		if value != "" {
+			value = ShellQuote(value)
 		}
-		distance++
-		return nil
-	})
-
-	if foundTag != "" {
-		// Format as tag-distance-ghash (e.g., v1.0.0-5-g1234567)
-		shortHash := hash.String()[:7]
-		describe := fmt.Sprintf("%s-%d-g%s", foundTag, distance, shortHash)
-		return describe, foundTag
+		fmt.Fprintf(&sb, "%s%s=%s\n", prefix, strings.ToUpper(f.Name), value)
 	}
+	return sb.String()
+}
 
-	return "", ""
+// shellSafe matches values that need no quoting in POSIX shells
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:+@%,=-]+$`)
+
+// ShellQuote quotes the value for POSIX shells unless it only contains safe
+// characters, so that the output can be evaluated and stays readable as dotenv
+func ShellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
-// String returns a formatted string representation of the version info
-func (i *Info) String() string {
-	return i.Version
+// LDFlags returns a go build -ldflags string that sets the Version, Commit,
+// Branch and BuildTime variables of the given package path, e.g. "main" or
+// "github.com/org/app/internal/buildinfo"
+func (i *Info) LDFlags(pkg string) string {
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{"Version", i.Version},
+		{"Commit", i.GitCommit},
+		{"Branch", i.GitBranch},
+		{"BuildTime", i.BuildTime},
+	}
+
+	flags := make([]string, 0, len(vars))
+	for _, v := range vars {
+		flags = append(flags, fmt.Sprintf("-X %s.%s=%s", pkg, v.name, v.value))
+	}
+	return strings.Join(flags, " ")
 }
 
 // DetailedString returns a detailed multi-line string with all version information
@@ -280,7 +650,7 @@ func (i *Info) DetailedString() string {
 	if tagStr == "" {
 		tagStr = "(none)"
 	}
-	return fmt.Sprintf(`Version:        %s
+	s := fmt.Sprintf(`Version:        %s
 Commit:         %s
 Branch:         %s
 Default Branch: %s
@@ -295,4 +665,49 @@ Dirty:          %s`,
 		i.BuildTime,
 		dirtyStr,
 	)
+	// The SemVer components are only meaningful for SemVer tags
+	if _, err := i.LatestSemVer(); i.LatestTag != "" && err == nil {
+		s += fmt.Sprintf("\nSemVer:         major %d, minor %d, patch %d", i.Major, i.Minor, i.Patch)
+		if i.Prerelease != "" {
+			s += ", prerelease " + i.Prerelease
+		}
+		if i.BuildMetadata != "" {
+			s += ", build " + i.BuildMetadata
+		}
+	}
+	if i.CommitTime != "" {
+		s += fmt.Sprintf("\nCommit Time:    %s\nCommitter:      %s\nAuthor Email:   %s\nSubject:        %s",
+			i.CommitTime, i.CommitterName, i.AuthorEmail, i.CommitMessageSubject)
+	}
+	if i.Tagger != "" {
+		s += fmt.Sprintf("\nTagger:         %s\nTag Time:       %s", i.Tagger, i.TagTime)
+		if i.TagMessage != "" {
+			s += "\nTag Message:    " + strings.ReplaceAll(i.TagMessage, "\n", "\n                ")
+		}
+	}
+	if i.RemoteURL != "" {
+		s += "\nRemote:         " + i.RemoteURL
+	}
+	if i.IsShallow {
+		s += "\nShallow:        true"
+	}
+	if i.DescribeTruncated {
+		s += "\nTruncated:      no tag within the maximum describe depth"
+	}
+	if i.CI != nil {
+		s += "\nCI:             " + i.CI.Provider
+		if i.CI.BuildNumber != "" {
+			s += " build " + i.CI.BuildNumber
+		}
+		if i.CI.PullRequest != "" {
+			s += ", pull request " + i.CI.PullRequest
+		}
+	}
+	if i.Superproject != nil {
+		s += "\nSuperproject:   " + i.Superproject.Version
+	}
+	for _, sub := range i.Submodules {
+		s += fmt.Sprintf("\nSubmodule:      %s %s", sub.Path, sub.Info.Version)
+	}
+	return s
 }
@@ -1,14 +1,21 @@
 package version
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 func TestGetVersionInfoFromSubdirectory(t *testing.T) {
@@ -326,6 +333,240 @@ func TestInfoDetailedString(t *testing.T) {
 	}
 }
 
+func TestInfoDetailedStringSemVer(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"v1.2.3-rc.1", "SemVer:         major 1, minor 2, patch 3, prerelease rc.1"},
+		{"nightly", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			info := &Info{Version: "v1.2.3-rc.1", LatestTag: tt.tag}
+			if v, err := info.LatestSemVer(); err == nil {
+				info.Major, info.Minor, info.Patch, info.Prerelease = v.Major, v.Minor, v.Patch, v.Prerelease
+			}
+			result := info.DetailedString()
+			if tt.expected == "" {
+				if strings.Contains(result, "SemVer:") {
+					t.Errorf("DetailedString() = %q, want no SemVer line", result)
+				}
+			} else if !strings.Contains(result, tt.expected) {
+				t.Errorf("DetailedString() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInfoJSON(t *testing.T) {
+	info := &Info{
+		Version:        "v1.0.0",
+		GitCommit:      "abc123def456",
+		GitCommitShort: "abc123d",
+		GitBranch:      "main",
+		GitBranchSlug:  "main",
+		DefaultBranch:  "main",
+		LatestTag:      "v1.0.0",
+		BuildTime:      "2025-01-01T00:00:00Z",
+		IsDirty:        true,
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"version":        "v1.0.0",
+		"gitCommit":      "abc123def456",
+		"gitCommitShort": "abc123d",
+		"gitBranch":      "main",
+		"defaultBranch":  "main",
+		"latestTag":      "v1.0.0",
+		"isDirty":        true,
+	}
+	for key, want := range expected {
+		if got := fields[key]; got != want {
+			t.Errorf("JSON field %q = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestInfoFormat(t *testing.T) {
+	info := &Info{
+		Version:        "v1.0.0",
+		GitCommitShort: "abc123d",
+		GitBranchSlug:  "feature-test",
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "slug and commit",
+			format:   "{{.GitBranchSlug}}-{{.GitCommitShort}}",
+			expected: "feature-test-abc123d",
+		},
+		{
+			name:     "version with literal text",
+			format:   "release {{.Version}}",
+			expected: "release v1.0.0",
+		},
+		{
+			name:    "invalid template",
+			format:  "{{.Version",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			format:  "{{.Unknown}}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := info.Format(tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Format(%q) expected error, got %q", tt.format, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Format(%q) failed: %v", tt.format, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Format(%q) = %q, want %q", tt.format, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInfoLDFlags(t *testing.T) {
+	info := &Info{
+		Version:   "v1.0.0-5-gabc123d",
+		GitCommit: "abc123def456",
+		GitBranch: "feature/test",
+		BuildTime: "2025-01-01T00:00:00Z",
+	}
+
+	expected := "-X main.Version=v1.0.0-5-gabc123d" +
+		" -X main.Commit=abc123def456" +
+		" -X main.Branch=feature/test" +
+		" -X main.BuildTime=2025-01-01T00:00:00Z"
+	if result := info.LDFlags("main"); result != expected {
+		t.Errorf("LDFlags(%q) = %q, want %q", "main", result, expected)
+	}
+
+	result := info.LDFlags("github.com/org/app/internal/buildinfo")
+	if !strings.HasPrefix(result, "-X github.com/org/app/internal/buildinfo.Version=v1.0.0-5-gabc123d ") {
+		t.Errorf("LDFlags() = %q, want full package path prefix", result)
+	}
+}
+
+func TestInfoEnv(t *testing.T) {
+	info := &Info{
+		Version:        "v1.0.0",
+		GitCommit:      "abc123def456",
+		GitCommitShort: "abc123d",
+		GitBranch:      "main",
+		GitBranchSlug:  "main",
+		GitDescribe:    "v1.0.0",
+		LatestTag:      "v1.0.0",
+		BuildTime:      "2025-01-01T00:00:00Z",
+		DefaultBranch:  "main",
+		Major:          1,
+	}
+
+	result := info.Env("GITVERSION_")
+	expectedLines := []string{
+		"GITVERSION_VERSION=v1.0.0",
+		"GITVERSION_COMMIT=abc123def456",
+		"GITVERSION_COMMIT_SHORT=abc123d",
+		"GITVERSION_BRANCH=main",
+		"GITVERSION_BRANCH_SLUG=main",
+		"GITVERSION_DESCRIBE=v1.0.0",
+		"GITVERSION_LATEST_TAG=v1.0.0",
+		"GITVERSION_BUILD_TIME=2025-01-01T00:00:00Z",
+		"GITVERSION_IS_DIRTY=false",
+		"GITVERSION_DEFAULT_BRANCH=main",
+		"GITVERSION_COMMITS_SINCE_TAG=0",
+		"GITVERSION_HAS_COMMITS=false",
+		"GITVERSION_IS_SHALLOW=false",
+		"GITVERSION_MAJOR=1",
+		"GITVERSION_MINOR=0",
+		"GITVERSION_PATCH=0",
+		"GITVERSION_PRERELEASE=",
+		"GITVERSION_BUILD_METADATA=",
+		"GITVERSION_COMMIT_TIME=",
+		"GITVERSION_COMMITTER_NAME=",
+		"GITVERSION_AUTHOR_EMAIL=",
+		"GITVERSION_COMMIT_MESSAGE_SUBJECT=",
+		"GITVERSION_REMOTE_URL=",
+		"GITVERSION_REPO_OWNER=",
+		"GITVERSION_REPO_NAME=",
+		"GITVERSION_COMMIT_SIGNED=false",
+		"GITVERSION_TAG_SIGNED=false",
+		"GITVERSION_CI_PROVIDER=",
+		"GITVERSION_CI_BUILD_NUMBER=",
+		"GITVERSION_CI_PULL_REQUEST=",
+	}
+	expected := strings.Join(expectedLines, "\n") + "\n"
+	if result != expected {
+		t.Errorf("Env() = %q, want %q", result, expected)
+	}
+
+	if !strings.HasPrefix(info.Env("APP_"), "APP_VERSION=v1.0.0\n") {
+		t.Errorf("Env(%q) should use custom prefix, got %q", "APP_", info.Env("APP_"))
+	}
+}
+
+func TestInfoEnvQuoting(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "pwned")
+	info := &Info{
+		Version:              "v1.0.0",
+		CommitterName:        "Jane Doe",
+		CommitMessageSubject: "feat: it's \"quoted\" $(touch " + marker + ") `touch " + marker + "`",
+	}
+
+	env := info.Env("GITVERSION_")
+	for _, line := range []string{
+		"GITVERSION_VERSION=v1.0.0\n",
+		"GITVERSION_COMMITTER_NAME='Jane Doe'\n",
+		"GITVERSION_PRERELEASE=\n",
+	} {
+		if !strings.Contains(env, line) {
+			t.Errorf("Env() = %q, want line %q", env, line)
+		}
+	}
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	script := env + `printf '%s\n%s' "$GITVERSION_COMMITTER_NAME" "$GITVERSION_COMMIT_MESSAGE_SUBJECT"`
+	out, err := exec.Command("sh", "-c", script).CombinedOutput()
+	if err != nil {
+		t.Fatalf("evaluating Env() failed: %v: %s", err, out)
+	}
+	if want := info.CommitterName + "\n" + info.CommitMessageSubject; string(out) != want {
+		t.Errorf("evaluated Env() = %q, want %q", out, want)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("evaluating Env() ran a command of the commit subject")
+	}
+}
+
 func TestGetVersionInfoWithUncommittedChanges(t *testing.T) {
 	// Create a temporary directory for test repository
 	tempDir, err := os.MkdirTemp("", "gitversion-test-dirty-*")
@@ -407,3 +648,542 @@ func TestGetVersionInfoWithUncommittedChanges(t *testing.T) {
 		t.Errorf("Timestamp suffix should be 14 digits, got %d: %s", len(lastPart), lastPart)
 	}
 }
+
+func TestGetVersionInfoWithAnnotatedTag(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	tagged := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("v1.0.0", tagged, &git.CreateTagOptions{
+		Tagger:  testSignature(),
+		Message: "Release v1.0.0",
+	}); err != nil {
+		t.Fatalf("Failed to create annotated tag: %v", err)
+	}
+
+	info, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Version != "v1.0.0" {
+		t.Errorf("Version = %q, want %q", info.Version, "v1.0.0")
+	}
+
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	info, err = GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	expected := "v1.0.0-1-g" + head.String()[:7]
+	if info.Version != expected {
+		t.Errorf("Version = %q, want %q", info.Version, expected)
+	}
+	if info.LatestTag != "v1.0.0" {
+		t.Errorf("LatestTag = %q, want %q", info.LatestTag, "v1.0.0")
+	}
+}
+
+func TestGetVersionInfoPrefersAnnotatedTag(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("a-lightweight", head, nil); err != nil {
+		t.Fatalf("Failed to create lightweight tag: %v", err)
+	}
+	if _, err := repo.CreateTag("v1.0.0", head, &git.CreateTagOptions{
+		Tagger:  testSignature(),
+		Message: "Release v1.0.0",
+	}); err != nil {
+		t.Fatalf("Failed to create annotated tag: %v", err)
+	}
+	if _, err := repo.CreateTag("z-lightweight", head, nil); err != nil {
+		t.Fatalf("Failed to create lightweight tag: %v", err)
+	}
+
+	info, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Version != "v1.0.0" {
+		t.Errorf("Version = %q, want annotated tag %q", info.Version, "v1.0.0")
+	}
+}
+
+// initTestRepo creates a temporary Git repository that is removed when the test ends
+func initTestRepo(t *testing.T) (string, *git.Repository) {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "gitversion-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	return tempDir, repo
+}
+
+// commitTestFile writes a file relative to the repository root and commits it
+func commitTestFile(t *testing.T, repo *git.Repository, dir, name, content, message string) plumbing.Hash {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add(filepath.ToSlash(name)); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+
+	hash, err := w.Commit(message, &git.CommitOptions{Author: testSignature()})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash
+}
+
+// testSignature returns the author/tagger signature used in tests
+func testSignature() *object.Signature {
+	return &object.Signature{
+		Name:  "Test User",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}
+}
+
+func TestGetVersionInfoWithTagPrefix(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	first := commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	if _, err := repo.CreateTag("release/1.0.0", first, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	second := commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+	if _, err := repo.CreateTag("nightly-42", second, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "test.txt", "v3", "Third commit")
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		expected  string
+		latestTag string
+	}{
+		{
+			name:      "no prefix uses nearest tag",
+			expected:  "nightly-42-1-g" + head.String()[:7],
+			latestTag: "nightly-42",
+		},
+		{
+			name:      "prefix filters tags",
+			opts:      []Option{WithTagPrefix("release/")},
+			expected:  "release/1.0.0-2-g" + head.String()[:7],
+			latestTag: "release/1.0.0",
+		},
+		{
+			name:      "prefix stripped",
+			opts:      []Option{WithTagPrefix("release/"), WithStripTagPrefix(true)},
+			expected:  "1.0.0-2-g" + head.String()[:7],
+			latestTag: "release/1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "", tt.opts...)
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.Version != tt.expected {
+				t.Errorf("Version = %q, want %q", info.Version, tt.expected)
+			}
+			if info.LatestTag != tt.latestTag {
+				t.Errorf("LatestTag = %q, want %q", info.LatestTag, tt.latestTag)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoInLinkedWorktree(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+
+	branchRef := plumbing.NewHashReference(plumbing.ReferenceName("refs/heads/feature/wt"), head)
+	if err := repo.Storer.SetReference(branchRef); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	// Recreate the layout of 'git worktree add': a .git file in the worktree
+	// pointing to .git/worktrees/<name>, which refers back via commondir
+	wtDir, err := os.MkdirTemp("", "gitversion-test-worktree-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(wtDir)
+
+	wtGitDir := filepath.Join(tempDir, ".git", "worktrees", "wt")
+	if err := os.MkdirAll(wtGitDir, 0755); err != nil {
+		t.Fatalf("Failed to create worktree git dir: %v", err)
+	}
+	index, err := os.ReadFile(filepath.Join(tempDir, ".git", "index"))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(wtGitDir, "HEAD"):      "ref: refs/heads/feature/wt\n",
+		filepath.Join(wtGitDir, "commondir"): "../..\n",
+		filepath.Join(wtGitDir, "gitdir"):    filepath.Join(wtDir, ".git") + "\n",
+		filepath.Join(wtGitDir, "index"):     string(index),
+		filepath.Join(wtDir, ".git"):         "gitdir: " + wtGitDir + "\n",
+		filepath.Join(wtDir, "test.txt"):     "test",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	info, err := GetVersionInfo(wtDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed in linked worktree: %v", err)
+	}
+	if info.GitBranch != "feature/wt" {
+		t.Errorf("GitBranch = %q, want %q", info.GitBranch, "feature/wt")
+	}
+	if info.GitCommit != head.String() {
+		t.Errorf("GitCommit = %q, want %q", info.GitCommit, head.String())
+	}
+	if info.IsDirty {
+		t.Error("IsDirty should be false for clean linked worktree")
+	}
+}
+
+func TestOpenRepositoryWithGitEnv(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	head := commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+
+	otherDir := t.TempDir()
+	t.Setenv("GIT_DIR", filepath.Join(tempDir, ".git"))
+	t.Setenv("GIT_WORK_TREE", tempDir)
+
+	info, err := GetVersionInfo(otherDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo with GIT_DIR failed: %v", err)
+	}
+	if info.GitCommit != head.String() {
+		t.Errorf("GitCommit = %q, want %q", info.GitCommit, head.String())
+	}
+	if info.IsDirty {
+		t.Error("IsDirty should be false with GIT_WORK_TREE pointing to the clean working tree")
+	}
+
+	// Without GIT_WORK_TREE the given path is the working tree, which lacks test.txt
+	t.Setenv("GIT_WORK_TREE", "")
+	info, err = GetVersionInfo(otherDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo with GIT_DIR failed: %v", err)
+	}
+	if !info.IsDirty {
+		t.Error("IsDirty should be true for a working tree without the committed file")
+	}
+}
+
+func TestOpenRepositoryWithCeilingDirectories(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+
+	subDir := filepath.Join(tempDir, "a", "b")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Join(tempDir, "a"))
+
+	if _, err := OpenRepository(subDir); err == nil {
+		t.Error("OpenRepository should not walk up into a ceiling directory")
+	}
+	if _, err := OpenRepository(filepath.Join(tempDir, "a")); err != nil {
+		t.Errorf("OpenRepository from the ceiling directory itself failed: %v", err)
+	}
+}
+
+func TestGetVersionInfoWithCeilingDirectories(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+
+	subDir := filepath.Join(tempDir, "a", "b")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			_, err := GetVersionInfo(subDir, "", WithBackend(backend), WithCeilingDirectories(filepath.Join(tempDir, "a")))
+			if !errors.Is(err, ErrNoRepository) {
+				t.Errorf("GetVersionInfo below a ceiling directory = %v, want ErrNoRepository", err)
+			}
+			// The repository is on a single filesystem
+			if _, err := GetVersionInfo(subDir, "", WithBackend(backend), WithOneFilesystem(true)); err != nil {
+				t.Errorf("GetVersionInfo with WithOneFilesystem failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoContext(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	commitTestFile(t, repo, tempDir, "test.txt", "v1", "Initial commit")
+	commitTestFile(t, repo, tempDir, "test.txt", "v2", "Second commit")
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfoContext(context.Background(), tempDir, "", WithBackend(backend))
+			if err != nil {
+				t.Fatalf("GetVersionInfoContext failed: %v", err)
+			}
+			if info.CommitsSinceTag != 2 {
+				t.Errorf("CommitsSinceTag = %d, want 2", info.CommitsSinceTag)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := GetVersionInfoContext(ctx, tempDir, "", WithBackend(backend)); !errors.Is(err, context.Canceled) {
+				t.Errorf("GetVersionInfoContext with canceled context = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestGetVersionInfoWithoutCommits(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			info, err := GetVersionInfo(tempDir, "master", WithBackend(backend))
+			if err != nil {
+				t.Fatalf("GetVersionInfo failed: %v", err)
+			}
+			if info.HasCommits {
+				t.Error("HasCommits = true, want false")
+			}
+			if info.GitBranch != "master" {
+				t.Errorf("GitBranch = %q, want %q", info.GitBranch, "master")
+			}
+			if info.GitCommit != plumbing.ZeroHash.String() {
+				t.Errorf("GitCommit = %q, want zero hash", info.GitCommit)
+			}
+			if info.Version != "master-g0000000" {
+				t.Errorf("Version = %q, want %q", info.Version, "master-g0000000")
+			}
+			if info.IsDirty {
+				t.Error("IsDirty = true, want false")
+			}
+		})
+	}
+
+	// Staged files make the tree dirty
+	writeFile(t, filepath.Join(tempDir, "test.txt"), "v1")
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := w.Add("test.txt"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	for _, backend := range backends {
+		info, err := GetVersionInfo(tempDir, "master", WithBackend(backend))
+		if err != nil {
+			t.Fatalf("GetVersionInfo with %s failed: %v", backend.Name(), err)
+		}
+		if !info.IsDirty {
+			t.Errorf("IsDirty with %s = false, want true", backend.Name())
+		}
+	}
+
+	// Other refs than HEAD must exist
+	if _, err := GetVersionInfo(tempDir, "master", WithRef("master")); err == nil {
+		t.Error("GetVersionInfo should fail for a ref without commits")
+	}
+}
+
+func TestGetVersionInfoSemVerComponents(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	hash := commitTestFile(t, repo, tempDir, "test.txt", "test", "Initial commit")
+	if _, err := repo.CreateTag("release/v1.2.3-rc.1+build.5", hash, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	info, err := GetVersionInfo(tempDir, "", WithTagPrefix("release/"))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Major != 1 || info.Minor != 2 || info.Patch != 3 || info.Prerelease != "rc.1" || info.BuildMetadata != "build.5" {
+		t.Errorf("components = %d.%d.%d-%s+%s, want 1.2.3-rc.1+build.5", info.Major, info.Minor, info.Patch, info.Prerelease, info.BuildMetadata)
+	}
+	if out, err := info.Format("{{.Major}}.{{.Minor}}"); err != nil || out != "1.2" {
+		t.Errorf("Format() = %q, %v, want 1.2", out, err)
+	}
+
+	info, err = GetVersionInfo(tempDir, "", WithTagPrefix("other/"))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.Major != 0 || info.Minor != 0 || info.Patch != 0 || info.Prerelease != "" {
+		t.Errorf("components without tag = %d.%d.%d-%s, want zero", info.Major, info.Minor, info.Patch, info.Prerelease)
+	}
+}
+
+func TestGetVersionInfoCommitMetadata(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	hash := commitTestFile(t, repo, tempDir, "test.txt", "test", "Add feature\nacross lines\n\nWith a body")
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatalf("Failed to get commit: %v", err)
+	}
+	want := commit.Committer
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		info, err := GetVersionInfo(tempDir, "", WithBackend(backend))
+		if err != nil {
+			t.Fatalf("%s: GetVersionInfo failed: %v", backend.Name(), err)
+		}
+		if info.CommitTime != want.When.UTC().Format("2006-01-02T15:04:05Z") {
+			t.Errorf("%s: CommitTime = %q, want %s", backend.Name(), info.CommitTime, want.When.UTC())
+		}
+		if info.CommitterName != want.Name || info.AuthorEmail != want.Email {
+			t.Errorf("%s: committer %q, author %q, want %q, %q", backend.Name(), info.CommitterName, info.AuthorEmail, want.Name, want.Email)
+		}
+		if info.CommitMessageSubject != "Add feature across lines" {
+			t.Errorf("%s: CommitMessageSubject = %q, want %q", backend.Name(), info.CommitMessageSubject, "Add feature across lines")
+		}
+	}
+
+	emptyDir, _ := initTestRepo(t)
+	info, err := GetVersionInfo(emptyDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.CommitTime != "" || info.CommitterName != "" || info.CommitMessageSubject != "" {
+		t.Errorf("commit metadata without commits = %q, %q, %q, want empty", info.CommitTime, info.CommitterName, info.CommitMessageSubject)
+	}
+}
+
+func TestGetVersionInfoFromRepo(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	tagged := commitTestFile(t, repo, tempDir, "a.txt", "a", "First")
+	if _, err := repo.CreateTag("v1.0.0", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	head := commitTestFile(t, repo, tempDir, "b.txt", "b", "Second")
+	expected := "v1.0.0-1-g" + head.String()[:7]
+
+	fs := memfs.New()
+	mem, err := git.Clone(memory.NewStorage(), fs, &git.CloneOptions{URL: tempDir})
+	if err != nil {
+		t.Fatalf("Failed to clone into memory: %v", err)
+	}
+	info, err := GetVersionInfoFromRepo(mem)
+	if err != nil {
+		t.Fatalf("GetVersionInfoFromRepo failed: %v", err)
+	}
+	if info.Version != expected || info.IsDirty {
+		t.Errorf("Version, IsDirty = %q, %v, want %q, false", info.Version, info.IsDirty, expected)
+	}
+
+	// The dirty check reads the in-memory worktree
+	f, err := fs.Create("a.txt")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := f.Write([]byte("modified")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	f.Close()
+	info, err = GetVersionInfoFromRepo(mem, WithDirtyFormat("-dirty"))
+	if err != nil {
+		t.Fatalf("GetVersionInfoFromRepo failed: %v", err)
+	}
+	if info.Version != expected+"-dirty" {
+		t.Errorf("Version = %q, want %q", info.Version, expected+"-dirty")
+	}
+
+	bare, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: tempDir})
+	if err != nil {
+		t.Fatalf("Failed to clone into memory: %v", err)
+	}
+	info, err = GetVersionInfoFromRepo(bare, WithRef("v1.0.0"))
+	if err != nil {
+		t.Fatalf("GetVersionInfoFromRepo failed: %v", err)
+	}
+	if info.LatestTag != "v1.0.0" || info.CommitsSinceTag != 0 {
+		t.Errorf("LatestTag, CommitsSinceTag = %q, %d, want v1.0.0, 0", info.LatestTag, info.CommitsSinceTag)
+	}
+
+	if _, err := GetVersionInfoFromRepo(mem, WithBackend(GitCLI())); err == nil {
+		t.Error("GetVersionInfoFromRepo() with git-cli backend expected error")
+	}
+}
+
+func TestFromRepository(t *testing.T) {
+	tempDir, repo := initTestRepo(t)
+	tagged := commitTestFile(t, repo, tempDir, "a.txt", "a", "First")
+	if _, err := repo.CreateTag("v1.0.0", tagged, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	commitTestFile(t, repo, tempDir, "b.txt", "b", "Second")
+	expected, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+
+	backends := []Backend{GoGit()}
+	if hasGit() {
+		backends = append(backends, GitCLI())
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			for _, opts := range [][]Option{{WithBackend(backend)}, {WithBackend(backend), WithCache(t.TempDir())}} {
+				info, err := FromRepository(repo, tempDir, opts...)
+				if err != nil {
+					t.Fatalf("FromRepository failed: %v", err)
+				}
+				if info.Version != expected.Version || info.GitCommit != expected.GitCommit {
+					t.Errorf("Version = %q at %s, want %q at %s", info.Version, info.GitCommit, expected.Version, expected.GitCommit)
+				}
+			}
+		})
+	}
+
+	if _, err := FromRepository(repo, "", WithCache(t.TempDir())); err == nil {
+		t.Error("FromRepository() with cache and without path expected error")
+	}
+	if _, err := FromRepository(nil, tempDir); err == nil {
+		t.Error("FromRepository(nil) expected error")
+	}
+}
@@ -0,0 +1,113 @@
+package version
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+
+	"github.com/fxsml/gitversion/pkg/revwalk"
+)
+
+// createMergeHistory creates A - B - M on master where M merges a side
+// branch commit C (parent A) tagged v2.0.0-feature:
+//
+//	A - B - M
+//	 \     /
+//	  C ---
+func createMergeHistory(t *testing.T) (string, *git.Repository, []plumbing.Hash) {
+	t.Helper()
+
+	tempDir, repo := initTestRepo(t)
+	a := commitTestFile(t, repo, tempDir, "test.txt", "a", "A")
+	b := commitTestFile(t, repo, tempDir, "test.txt", "b", "B")
+
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	c, err := w.Commit("C", &git.CommitOptions{Author: testSignature(), Parents: []plumbing.Hash{a}, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if _, err := repo.CreateTag("v2.0.0-feature", c, nil); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	m, err := w.Commit("M", &git.CommitOptions{Author: testSignature(), Parents: []plumbing.Hash{b, c}, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatalf("Failed to commit merge: %v", err)
+	}
+	return tempDir, repo, []plumbing.Hash{a, b, c, m}
+}
+
+func TestCountCommits(t *testing.T) {
+	_, repo, hashes := createMergeHistory(t)
+	a, c, m := hashes[0], hashes[2], hashes[3]
+	nodes := commitgraph.NewObjectCommitNodeIndex(repo.Storer)
+	all := func(*object.Commit) bool { return true }
+
+	tests := []struct {
+		name        string
+		since       plumbing.Hash
+		firstParent bool
+		expected    int
+	}{
+		{"whole history", plumbing.ZeroHash, false, 4},
+		{"since merged branch excludes its ancestors", c, false, 2},
+		{"since fork point", a, false, 3},
+		{"first parent since fork point", a, true, 2},
+		{"first parent whole history", plumbing.ZeroHash, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := revwalk.Count(context.Background(), nodes, m, tt.since, tt.firstParent, all)
+			if err != nil {
+				t.Fatalf("Count failed: %v", err)
+			}
+			if count != tt.expected {
+				t.Errorf("Count = %d, want %d", count, tt.expected)
+			}
+		})
+	}
+
+	// Commits reachable from a descendant with the same timestamp are excluded
+	count, err := revwalk.Count(context.Background(), nodes, a, m, false, all)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Count since a descendant = %d, want 0", count)
+	}
+}
+
+func TestGetVersionInfoWithFirstParent(t *testing.T) {
+	tempDir, _, hashes := createMergeHistory(t)
+	m := hashes[3]
+
+	info, err := GetVersionInfo(tempDir, "")
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.LatestTag != "v2.0.0-feature" {
+		t.Errorf("LatestTag = %q, want feature tag from merged branch", info.LatestTag)
+	}
+
+	info, err = GetVersionInfo(tempDir, "", WithFirstParent(true))
+	if err != nil {
+		t.Fatalf("GetVersionInfo failed: %v", err)
+	}
+	if info.LatestTag != "" {
+		t.Errorf("LatestTag = %q, want no tag on the first-parent chain", info.LatestTag)
+	}
+	expected := info.GitBranchSlug + "-g" + m.String()[:7]
+	if info.Version != expected {
+		t.Errorf("Version = %q, want %q", info.Version, expected)
+	}
+	if info.CommitsSinceTag != 3 {
+		t.Errorf("CommitsSinceTag = %d, want 3", info.CommitsSinceTag)
+	}
+}